@@ -0,0 +1,261 @@
+package phpsessgo
+
+import (
+	"container/list"
+	"context"
+	"hash/fnv"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheMaxEntries is default number of sessions kept by CachingSessionHandler
+	DefaultCacheMaxEntries = 1000
+	// DefaultCacheTTL is default time CachingSessionHandler trust the cached session
+	DefaultCacheTTL = time.Second
+
+	// cacheGenerationStripes is number of invalidation counters shared by the sessions hashed to them
+	cacheGenerationStripes = 256
+)
+
+// CachePublisher notify other processes the session was written
+type CachePublisher interface {
+	Publish(sessionID string) error
+}
+
+// CachingSessionHandlerConfig configure CachingSessionHandler
+type CachingSessionHandlerConfig struct {
+	// MaxEntries is maximum number of cached sessions, default to DefaultCacheMaxEntries
+	MaxEntries int
+	// TTL bound how long change by other process can be missed, default to DefaultCacheTTL
+	TTL time.Duration
+	// Publisher notify other processes after write, e.g. RedisCacheInvalidator.
+	// Publish failure doesn't fail the write, other caches expire within TTL
+	Publisher CachePublisher
+}
+
+// CachingSessionHandler keep recently used sessions of the wrapped handler in LRU cache
+type CachingSessionHandler struct {
+	SessionHandler
+	config CachingSessionHandlerConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// generations of the session stripes change on every invalidation of the session,
+	// so data read before it is not cached
+	generations [cacheGenerationStripes]uint64
+}
+
+type cacheEntry struct {
+	sessionID string
+	data      string
+	expiresAt time.Time
+}
+
+// NewCachingSessionHandler create new instance of CachingSessionHandler
+func NewCachingSessionHandler(handler SessionHandler, config CachingSessionHandlerConfig) *CachingSessionHandler {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultCacheMaxEntries
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultCacheTTL
+	}
+
+	return &CachingSessionHandler{
+		SessionHandler: handler,
+		config:         config,
+		now:            time.Now,
+		entries:        make(map[string]*list.Element),
+		lru:            list.New(),
+	}
+}
+
+func (h *CachingSessionHandler) Read(sessionID string) (string, error) {
+	if data, ok := h.get(sessionID); ok {
+		return data, nil
+	}
+
+	generation := h.generation(sessionID)
+	data, err := h.SessionHandler.Read(sessionID)
+	if err != nil {
+		return "", err
+	}
+	h.set(sessionID, data, generation)
+	return data, nil
+}
+
+func (h *CachingSessionHandler) Write(sessionID string, sessionData string) error {
+	h.Invalidate(sessionID)
+	if err := h.SessionHandler.Write(sessionID, sessionData); err != nil {
+		return err
+	}
+	h.written(sessionID, sessionData)
+	return nil
+}
+
+// ReadContext read the session from the cache or with context of the wrapped handler
+func (h *CachingSessionHandler) ReadContext(ctx context.Context, sessionID string) (string, error) {
+	if data, ok := h.get(sessionID); ok {
+		return data, nil
+	}
+
+	generation := h.generation(sessionID)
+	data, err := NewContextSessionHandler(h.SessionHandler).ReadContext(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	h.set(sessionID, data, generation)
+	return data, nil
+}
+
+// WriteContext write the session with context of the wrapped handler and cache it
+func (h *CachingSessionHandler) WriteContext(ctx context.Context, sessionID string, sessionData string) error {
+	h.Invalidate(sessionID)
+	if err := NewContextSessionHandler(h.SessionHandler).WriteContext(ctx, sessionID, sessionData); err != nil {
+		return err
+	}
+	h.written(sessionID, sessionData)
+	return nil
+}
+
+// Unwrap return the wrapped handler
+func (h *CachingSessionHandler) Unwrap() SessionHandler {
+	return h.SessionHandler
+}
+
+// bindRequest reject HTTPSessionHandler, there is nothing to cache
+func (h *CachingSessionHandler) bindRequest(w http.ResponseWriter, r *http.Request) (SessionHandler, bool, error) {
+	if _, bound, err := bindRequest(h.SessionHandler, w, r); bound || err != nil {
+		if err == nil {
+			err = ErrCachedHTTPSessionHandler
+		}
+		return h, false, err
+	}
+	return h, false, nil
+}
+
+// UpdateTimestamp of the wrapped handler when it is supported
+func (h *CachingSessionHandler) UpdateTimestamp(sessionID string, sessionData string) error {
+	return h.UpdateTimestampContext(context.Background(), sessionID, sessionData)
+}
+
+// UpdateTimestampContext is UpdateTimestamp with context of the wrapped handler
+func (h *CachingSessionHandler) UpdateTimestampContext(ctx context.Context, sessionID string, sessionData string) error {
+	return updateTimestamp(ctx, h.SessionHandler, sessionID, sessionData)
+}
+
+// Destroy the session of the wrapped handler and remove it from the cache
+func (h *CachingSessionHandler) Destroy(sessionID string) error {
+	return h.DestroyContext(context.Background(), sessionID)
+}
+
+// DestroyContext is Destroy with context of the wrapped handler
+func (h *CachingSessionHandler) DestroyContext(ctx context.Context, sessionID string) error {
+	h.Invalidate(sessionID)
+	if err := destroySession(ctx, h.SessionHandler, sessionID); err != nil {
+		return err
+	}
+	if h.config.Publisher != nil {
+		h.config.Publisher.Publish(sessionID)
+	}
+	return nil
+}
+
+// Invalidate remove the session from the cache
+func (h *CachingSessionHandler) Invalidate(sessionID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.generations[generationStripe(sessionID)]++
+	if element, ok := h.entries[sessionID]; ok {
+		h.lru.Remove(element)
+		delete(h.entries, sessionID)
+	}
+}
+
+// Purge remove all sessions from the cache
+func (h *CachingSessionHandler) Purge() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.generations {
+		h.generations[i]++
+	}
+	h.entries = make(map[string]*list.Element)
+	h.lru.Init()
+}
+
+// Len return number of cached sessions
+func (h *CachingSessionHandler) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.lru.Len()
+}
+
+func (h *CachingSessionHandler) written(sessionID, sessionData string) {
+	if h.config.Publisher != nil {
+		h.config.Publisher.Publish(sessionID)
+	}
+	h.set(sessionID, sessionData, h.generation(sessionID))
+}
+
+func (h *CachingSessionHandler) generation(sessionID string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.generations[generationStripe(sessionID)]
+}
+
+// generationStripe return index of the generation counter of the session, FNV-1a of the ID
+func generationStripe(sessionID string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(sessionID))
+	return int(hash.Sum32() % cacheGenerationStripes)
+}
+
+func (h *CachingSessionHandler) get(sessionID string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	element, ok := h.entries[sessionID]
+	if !ok {
+		return "", false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !h.now().Before(entry.expiresAt) {
+		h.lru.Remove(element)
+		delete(h.entries, sessionID)
+		return "", false
+	}
+
+	h.lru.MoveToFront(element)
+	return entry.data, true
+}
+
+// set cache the data unless the session, or other one sharing its stripe, was invalidated since the generation
+func (h *CachingSessionHandler) set(sessionID, data string, generation uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if generation != h.generations[generationStripe(sessionID)] {
+		return
+	}
+
+	entry := &cacheEntry{sessionID: sessionID, data: data, expiresAt: h.now().Add(h.config.TTL)}
+	if element, ok := h.entries[sessionID]; ok {
+		element.Value = entry
+		h.lru.MoveToFront(element)
+		return
+	}
+
+	h.entries[sessionID] = h.lru.PushFront(entry)
+	for h.lru.Len() > h.config.MaxEntries {
+		oldest := h.lru.Back()
+		h.lru.Remove(oldest)
+		delete(h.entries, oldest.Value.(*cacheEntry).sessionID)
+	}
+}
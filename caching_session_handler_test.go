@@ -0,0 +1,133 @@
+package phpsessgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	published []string
+}
+
+func (p *recordingPublisher) Publish(sessionID string) error {
+	p.published = append(p.published, sessionID)
+	return nil
+}
+
+func TestCachingSessionHandler(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemorySessionHandler(MemorySessionHandlerConfig{})
+	defer store.Close()
+
+	publisher := &recordingPublisher{}
+	handler := NewCachingSessionHandler(store, CachingSessionHandlerConfig{
+		MaxEntries: 2,
+		TTL:        time.Second,
+		Publisher:  publisher,
+	})
+	handler.now = func() time.Time { return now }
+
+	require.NoError(t, store.Write("a", "data-a"))
+
+	t.Run("cache read", func(t *testing.T) {
+		data, err := handler.Read("a")
+		require.NoError(t, err)
+		require.Equal(t, "data-a", data)
+
+		// written by other process
+		require.NoError(t, store.Write("a", "php-a"))
+		data, _ = handler.Read("a")
+		require.Equal(t, "data-a", data)
+
+		now = now.Add(time.Second)
+		data, _ = handler.Read("a")
+		require.Equal(t, "php-a", data)
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		require.NoError(t, store.Write("a", "php-a-2"))
+		handler.Invalidate("a")
+
+		data, _ := handler.Read("a")
+		require.Equal(t, "php-a-2", data)
+	})
+
+	t.Run("write through", func(t *testing.T) {
+		require.NoError(t, handler.Write("b", "data-b"))
+		stored, _ := store.Read("b")
+		require.Equal(t, "data-b", stored)
+		require.Equal(t, []string{"b"}, publisher.published)
+
+		require.NoError(t, store.Write("b", "php-b"))
+		data, _ := handler.Read("b")
+		require.Equal(t, "data-b", data)
+	})
+
+	t.Run("least recently used eviction", func(t *testing.T) {
+		handler.Read("a")
+		require.NoError(t, handler.Write("c", "data-c"))
+		require.Equal(t, 2, handler.Len())
+		require.Contains(t, handler.entries, "a")
+		require.NotContains(t, handler.entries, "b")
+	})
+
+	t.Run("stale read is not cached", func(t *testing.T) {
+		generation := handler.generation("d")
+		handler.Invalidate("d")
+		handler.set("d", "stale", generation)
+		require.NotContains(t, handler.entries, "d")
+	})
+
+	t.Run("write to other session during read", func(t *testing.T) {
+		require.NotEqual(t, generationStripe("d"), generationStripe("e"))
+		require.NoError(t, store.Write("d", "data-d"))
+
+		generation := handler.generation("d")
+		data, err := store.Read("d")
+		require.NoError(t, err)
+		require.NoError(t, handler.Write("e", "data-e"))
+		handler.set("d", data, generation)
+		require.Contains(t, handler.entries, "d")
+	})
+
+	t.Run("destroy", func(t *testing.T) {
+		require.NoError(t, handler.Destroy("c"))
+		require.NotContains(t, handler.entries, "c")
+		require.NotContains(t, store.List(), "c")
+
+		handler.Purge()
+		require.Equal(t, 0, handler.Len())
+	})
+}
+
+func TestRedisCacheInvalidator_SessionID(t *testing.T) {
+	client := redis.NewClient(&redis.Options{DB: 2})
+	defer client.Close()
+
+	invalidator := NewRedisCacheInvalidator(client, RedisCacheInvalidatorConfig{
+		Keyspace: true,
+		Channel:  "phpsessgo:invalidate",
+	})
+
+	testcases := []struct {
+		message   redis.Message
+		sessionID string
+		ok        bool
+	}{
+		{redis.Message{Channel: "__keyspace@2__:PHPREDIS_SESSION:some-session-id", Payload: "set"}, "some-session-id", true},
+		{redis.Message{Channel: "__keyspace@0__:PHPREDIS_SESSION:some-session-id", Payload: "set"}, "", false},
+		{redis.Message{Channel: "__keyspace@2__:other:some-session-id", Payload: "del"}, "", false},
+		{redis.Message{Channel: "phpsessgo:invalidate", Payload: "other-session-id"}, "other-session-id", true},
+	}
+
+	for _, tt := range testcases {
+		sessionID, ok := invalidator.sessionID(&tt.message)
+		require.Equal(t, tt.ok, ok, tt.message.Channel)
+		require.Equal(t, tt.sessionID, sessionID)
+	}
+
+	require.Error(t, NewRedisCacheInvalidator(client, RedisCacheInvalidatorConfig{}).Listen(NewCachingSessionHandler(nil, CachingSessionHandlerConfig{})))
+}
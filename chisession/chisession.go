@@ -0,0 +1,24 @@
+// Package chisession provide phpsessgo middleware for chi router.
+// chi use standard net/http middleware, so it is thin layer over phpsessgo.NewMiddleware
+package chisession
+
+import (
+	"net/http"
+
+	"github.com/eligundry/phpsessgo"
+)
+
+// Middleware start the session for every request and save it before the response is written
+func Middleware(manager phpsessgo.SessionManager) func(http.Handler) http.Handler {
+	return phpsessgo.NewMiddleware(manager, phpsessgo.MiddlewareConfig{})
+}
+
+// MiddlewareWithConfig is Middleware with custom configuration
+func MiddlewareWithConfig(manager phpsessgo.SessionManager, config phpsessgo.MiddlewareConfig) func(http.Handler) http.Handler {
+	return phpsessgo.NewMiddleware(manager, config)
+}
+
+// Get return the session started by the middleware or nil when there is none
+func Get(r *http.Request) *phpsessgo.Session {
+	return phpsessgo.SessionFromContext(r.Context())
+}
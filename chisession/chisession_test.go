@@ -0,0 +1,43 @@
+package chisession_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/chisession"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	handler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	manager, err := phpsessgo.NewSessionManager(
+		phpsessgo.DefaultSessionName,
+		&phpsessgo.UUIDCreator{},
+		handler,
+		&phpsessgo.PHPSessionEncoder{},
+		phpsessgo.SessionManagerConfig{},
+	)
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Use(chisession.Middleware(manager))
+	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		session := chisession.Get(r)
+		session.Value["hello"] = "world"
+		w.Write([]byte(session.SessionID))
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	sessionID := rr.Body.String()
+	require.Equal(t, manager.SetCookieString(sessionID), rr.Header().Get("Set-Cookie"))
+
+	data, _ := handler.Read(sessionID)
+	require.Equal(t, `hello|s:5:"world";`, data)
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+	"unicode/utf8"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+// record is single line of export, binary data (e.g. compressed) is kept in DataBase64
+type record struct {
+	ID         string `json:"id"`
+	Data       string `json:"data,omitempty"`
+	DataBase64 string `json:"data_base64,omitempty"`
+	LastWrite  int64  `json:"last_write,omitempty"`
+}
+
+func (r record) data() (string, error) {
+	if r.DataBase64 == "" {
+		return r.Data, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(r.DataBase64)
+	return string(data), err
+}
+
+func (c *cli) list(args []string) error {
+	opts := &options{}
+	flags := newFlagSet("list", c.stderr, opts)
+	addFilterFlags(flags, opts)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := openStore(opts)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return walk(store, opts, func(info phpsessgo.SessionInfo) error {
+		lastWrite := "-"
+		if !info.LastWrite.IsZero() {
+			lastWrite = info.LastWrite.UTC().Format(time.RFC3339)
+		}
+		_, err := fmt.Fprintf(c.stdout, "%s\t%s\n", info.ID, lastWrite)
+		return err
+	})
+}
+
+func (c *cli) get(args []string) error {
+	return c.eachSession("get", args, nil, func(store phpsessgo.SessionHandler, opts *options, id string) error {
+		data, err := store.Read(id)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.stdout, data)
+		return err
+	})
+}
+
+func (c *cli) decode(args []string) error {
+	var (
+		tagged bool
+		format string
+	)
+	extraFlags := func(flags *flag.FlagSet) {
+		flags.BoolVar(&tagged, "tagged", false, "print lossless JSON keeping PHP types, see phptype.MarshalJSON")
+		flags.StringVar(&format, "format", "json", "output format: json, var_dump, print_r or var_export of $_SESSION")
+	}
+	return c.eachSession("decode", args, extraFlags, func(store phpsessgo.SessionHandler, opts *options, id string) error {
+		encoder, err := orderedEncoder(opts.serializeHandler)
+		if err != nil {
+			return err
+		}
+
+		data, err := store.Read(id)
+		if err != nil {
+			return err
+		}
+		array, err := encoder.DecodeOrdered(data)
+		if err != nil {
+			return fmt.Errorf("session %s: %v", id, err)
+		}
+
+		switch format {
+		case "json":
+			out, err := (&phpsessgo.JSONSessionEncoder{Indent: "  ", Tagged: tagged}).EncodeOrdered(array)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(c.stdout, out)
+			return err
+		case "var_dump":
+			_, err = fmt.Fprint(c.stdout, phptype.VarDump(array))
+		case "print_r":
+			_, err = fmt.Fprint(c.stdout, phptype.PrintR(array))
+		case "var_export":
+			_, err = fmt.Fprintln(c.stdout, phptype.VarExport(array))
+		default:
+			err = fmt.Errorf("unsupported format %q", format)
+		}
+		return err
+	})
+}
+
+func (c *cli) set(args []string) error {
+	opts := &options{}
+	flags := newFlagSet("set", c.stderr, opts)
+	raw := flags.Bool("raw", false, "write the data without checking it decode with the serialize handler")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return fmt.Errorf("usage: phpsessgo set [flags] <id> [data]")
+	}
+
+	var data string
+	if flags.NArg() == 2 {
+		data = flags.Arg(1)
+	} else {
+		b, err := ioutil.ReadAll(c.stdin)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+
+	if !*raw {
+		encoder, err := phpsessgo.NewSessionEncoder(opts.serializeHandler)
+		if err != nil {
+			return err
+		}
+		if _, err := encoder.Decode(data); err != nil {
+			return fmt.Errorf("invalid session data: %v", err)
+		}
+	}
+
+	store, err := openStore(opts)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return store.Write(flags.Arg(0), data)
+}
+
+func (c *cli) delete(args []string) error {
+	return c.eachSession("delete", args, nil, func(store phpsessgo.SessionHandler, opts *options, id string) error {
+		destroyer, ok := store.(phpsessgo.SessionDestroyHandler)
+		if !ok {
+			return fmt.Errorf("save handler %q can't delete sessions", opts.saveHandler)
+		}
+		return destroyer.Destroy(id)
+	})
+}
+
+func (c *cli) export(args []string) error {
+	opts := &options{}
+	flags := newFlagSet("export", c.stderr, opts)
+	addFilterFlags(flags, opts)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := openStore(opts)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	out := bufio.NewWriter(c.stdout)
+	encoder := json.NewEncoder(out)
+	count := 0
+	err = walk(store, opts, func(info phpsessgo.SessionInfo) error {
+		data, err := store.Read(info.ID)
+		if err != nil {
+			return err
+		}
+		// expired or destroyed since it was listed
+		if data == "" {
+			return nil
+		}
+
+		r := record{ID: info.ID}
+		if utf8.ValidString(data) {
+			r.Data = data
+		} else {
+			r.DataBase64 = base64.StdEncoding.EncodeToString([]byte(data))
+		}
+		if !info.LastWrite.IsZero() {
+			r.LastWrite = info.LastWrite.Unix()
+		}
+		count++
+		return encoder.Encode(r)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "exported %d sessions\n", count)
+	return out.Flush()
+}
+
+func (c *cli) importSessions(args []string) error {
+	opts := &options{}
+	flags := newFlagSet("import", c.stderr, opts)
+	addFilterFlags(flags, opts)
+	overwrite := flags.Bool("overwrite", false, "replace sessions already in the store")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := openStore(opts)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	imported, skipped := 0, 0
+	now := time.Now()
+	decoder := json.NewDecoder(c.stdin)
+	for {
+		var r record
+		if err := decoder.Decode(&r); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		info := phpsessgo.SessionInfo{ID: r.ID}
+		if r.LastWrite > 0 {
+			info.LastWrite = time.Unix(r.LastWrite, 0)
+		}
+		if !opts.match(info, now) {
+			continue
+		}
+
+		if !*overwrite {
+			existing, err := store.Read(r.ID)
+			if err != nil {
+				return err
+			}
+			if existing != "" {
+				skipped++
+				continue
+			}
+		}
+
+		data, err := r.data()
+		if err != nil {
+			return fmt.Errorf("session %s: %v", r.ID, err)
+		}
+		if err := store.Write(r.ID, data); err != nil {
+			return fmt.Errorf("session %s: %v", r.ID, err)
+		}
+		imported++
+	}
+
+	fmt.Fprintf(c.stderr, "imported %d sessions, skipped %d existing\n", imported, skipped)
+	return nil
+}
+
+// eachSession parse flags of command taking session IDs as arguments, with the command specific
+// flags registered by extraFlags, and call fn for each ID
+func (c *cli) eachSession(name string, args []string, extraFlags func(flags *flag.FlagSet), fn func(store phpsessgo.SessionHandler, opts *options, id string) error) error {
+	opts := &options{}
+	flags := newFlagSet(name, c.stderr, opts)
+	if extraFlags != nil {
+		extraFlags(flags)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: phpsessgo %s [flags] <id>...", name)
+	}
+
+	store, err := openStore(opts)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	for _, id := range flags.Args() {
+		if err := fn(store, opts, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
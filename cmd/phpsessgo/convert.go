@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+// converter rewrite sessions from one serialize handler to another
+type converter struct {
+	cli      *cli
+	store    phpsessgo.SessionHandler
+	rewriter phpsessgo.SessionRewriter
+	from     phpsessgo.OrderedSessionEncoder
+	to       phpsessgo.OrderedSessionEncoder
+	dryRun   bool
+	tick     <-chan time.Time
+
+	converted, skipped, failed int
+}
+
+func (c *cli) convert(args []string) error {
+	opts := &options{}
+	flags := newFlagSet("convert", c.stderr, opts)
+	addFilterFlags(flags, opts)
+	from := flags.String("from", "php", "current serialize handler of the sessions")
+	to := flags.String("to", "", "new serialize handler: php, php_binary, php_serialize or json, the lossless tagged JSON")
+	dryRun := flags.Bool("dry-run", false, "only report what would be converted")
+	rate := flags.Int("rate", 0, "maximum sessions converted per second, zero is unlimited")
+	batch := flags.Int64("batch", 100, "redis SCAN count")
+	cursorFile := flags.String("cursor-file", "", "file keeping redis SCAN cursor, to resume interrupted conversion")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	conv := &converter{cli: c, dryRun: *dryRun}
+	var err error
+	if conv.from, err = convertEncoder(*from); err != nil {
+		return err
+	}
+	if conv.to, err = convertEncoder(*to); err != nil {
+		return err
+	}
+	if *rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(*rate))
+		defer ticker.Stop()
+		conv.tick = ticker.C
+	}
+
+	if conv.store, err = openStore(opts); err != nil {
+		return err
+	}
+	defer conv.store.Close()
+	var ok bool
+	if conv.rewriter, ok = conv.store.(phpsessgo.SessionRewriter); !ok {
+		return fmt.Errorf("save handler %s can't rewrite sessions in place", opts.saveHandler)
+	}
+
+	now := time.Now()
+	convert := func(info phpsessgo.SessionInfo) error {
+		if opts.match(info, now) {
+			conv.convert(info.ID)
+		}
+		return nil
+	}
+
+	if sharded, ok := conv.store.(*phpsessgo.ShardedRedisSessionHandler); ok {
+		err = conv.scanRedis(sharded, *batch, *cursorFile, convert)
+	} else {
+		err = walk(conv.store, &options{saveHandler: opts.saveHandler}, convert)
+	}
+
+	fmt.Fprintf(c.stderr, "converted %d sessions, skipped %d, failed %d\n", conv.converted, conv.skipped, conv.failed)
+	if err == nil && conv.failed > 0 {
+		err = fmt.Errorf("%d sessions failed", conv.failed)
+	}
+	return err
+}
+
+// convertEncoder return the encoder of serialize handler, json is the tagged format
+// so the conversion doesn't lose PHP types nor the order of arrays
+func convertEncoder(name string) (phpsessgo.OrderedSessionEncoder, error) {
+	if name == "json" {
+		return &phpsessgo.JSONSessionEncoder{Tagged: true}, nil
+	}
+	return orderedEncoder(name)
+}
+
+// orderedEncoder return the encoder of serialize handler keeping the order of session variables
+func orderedEncoder(name string) (phpsessgo.OrderedSessionEncoder, error) {
+	encoder, err := phpsessgo.NewSessionEncoder(name)
+	if err != nil {
+		return nil, err
+	}
+	ordered, ok := encoder.(phpsessgo.OrderedSessionEncoder)
+	if !ok {
+		return nil, fmt.Errorf("serialize handler %s can't keep the order of session variables", name)
+	}
+	return ordered, nil
+}
+
+// convert single session, sessions already in the new format are skipped so the conversion can be rerun.
+// The session is replaced only if it was not changed meanwhile and it keep its expiration
+func (conv *converter) convert(id string) {
+	if conv.tick != nil {
+		<-conv.tick
+	}
+
+	data, err := conv.store.Read(id)
+	if err != nil {
+		conv.fail(id, err)
+		return
+	}
+	if data == "" {
+		conv.skipped++
+		return
+	}
+
+	session, err := decodeStrict(conv.from, data)
+	if err != nil {
+		if _, toErr := decodeStrict(conv.to, data); toErr == nil {
+			conv.skipped++
+			return
+		}
+		conv.fail(id, err)
+		return
+	}
+
+	converted, err := conv.to.EncodeOrdered(session)
+	if err != nil {
+		conv.fail(id, err)
+		return
+	}
+	if converted == data {
+		conv.skipped++
+		return
+	}
+	if decoded, err := conv.to.DecodeOrdered(converted); err != nil || !reflect.DeepEqual(decoded, session) {
+		conv.fail(id, fmt.Errorf("conversion is lossy"))
+		return
+	}
+
+	if conv.dryRun {
+		fmt.Fprintf(conv.cli.stdout, "%s\t%d -> %d bytes\n", id, len(data), len(converted))
+	} else if replaced, err := conv.rewriter.RewriteSession(id, data, converted); err != nil {
+		conv.fail(id, err)
+		return
+	} else if !replaced {
+		fmt.Fprintf(conv.cli.stderr, "session %s: changed during conversion, skipped\n", id)
+		conv.skipped++
+		return
+	}
+	conv.converted++
+}
+
+// decodeStrict reject data the lenient decoders read as empty session, e.g. php_serialize data with php
+func decodeStrict(encoder phpsessgo.OrderedSessionEncoder, data string) (*phptype.OrderedArray, error) {
+	session, err := encoder.DecodeOrdered(data)
+	if err == nil && session.Len() == 0 && data != "" {
+		err = fmt.Errorf("no variable decoded")
+	}
+	return session, err
+}
+
+func (conv *converter) fail(id string, err error) {
+	conv.failed++
+	fmt.Fprintf(conv.cli.stderr, "session %s: %v\n", id, err)
+}
+
+// scanRedis walk the nodes with SCAN, the cursor "<node>:<cursor>" is saved after every batch
+func (conv *converter) scanRedis(store *phpsessgo.ShardedRedisSessionHandler, batch int64, cursorFile string, fn func(info phpsessgo.SessionInfo) error) error {
+	node, cursor, err := loadCursor(cursorFile)
+	if err != nil {
+		return err
+	}
+
+	for ; node < len(store.Nodes); node, cursor = node+1, 0 {
+		for {
+			infos, next, err := store.Nodes[node].Handler.ScanSessions(cursor, batch)
+			if err != nil {
+				return err
+			}
+			for _, info := range infos {
+				if err := fn(info); err != nil {
+					return err
+				}
+			}
+			if next == 0 {
+				break
+			}
+			cursor = next
+			if err := saveCursor(cursorFile, node, cursor); err != nil {
+				return err
+			}
+		}
+		if err := saveCursor(cursorFile, node+1, 0); err != nil {
+			return err
+		}
+	}
+
+	if cursorFile != "" {
+		return os.Remove(cursorFile)
+	}
+	return nil
+}
+
+func loadCursor(cursorFile string) (node int, cursor uint64, err error) {
+	if cursorFile == "" {
+		return 0, 0, nil
+	}
+
+	data, err := ioutil.ReadFile(cursorFile)
+	if os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(string(data)), ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid cursor %q in %s", data, cursorFile)
+	}
+	if node, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, fmt.Errorf("invalid cursor %q in %s", data, cursorFile)
+	}
+	if cursor, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid cursor %q in %s", data, cursorFile)
+	}
+	return node, cursor, nil
+}
+
+func saveCursor(cursorFile string, node int, cursor uint64) error {
+	if cursorFile == "" {
+		return nil
+	}
+	return ioutil.WriteFile(cursorFile, []byte(fmt.Sprintf("%d:%d\n", node, cursor)), 0644)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLI_ConvertFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phpsessgo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sess_abc1"), []byte(`login_ok|b:1;`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sess_abc2"), []byte(`a:1:{s:4:"cart";i:3;}`), 0600))
+
+	out, err := runCLI(t, "", "convert", "-save-path", dir, "-to", "php_serialize", "-dry-run")
+	require.NoError(t, err)
+	require.Equal(t, "abc1\t13 -> 25 bytes\n", out)
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "sess_abc1"))
+	require.NoError(t, err)
+	require.Equal(t, `login_ok|b:1;`, string(raw))
+
+	_, err = runCLI(t, "", "convert", "-save-path", dir, "-to", "php_serialize", "-rate", "1000")
+	require.NoError(t, err)
+	raw, err = ioutil.ReadFile(filepath.Join(dir, "sess_abc1"))
+	require.NoError(t, err)
+	require.Equal(t, `a:1:{s:8:"login_ok";b:1;}`, string(raw))
+
+	// the second run has nothing left to do
+	out, err = runCLI(t, "", "convert", "-save-path", dir, "-to", "php_serialize", "-dry-run")
+	require.NoError(t, err)
+	require.Equal(t, "", out)
+
+	_, err = runCLI(t, "", "convert", "-save-path", dir, "-to", "yaml")
+	require.Error(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sess_abc3"), []byte(`not a session`), 0600))
+	_, err = runCLI(t, "", "convert", "-save-path", dir, "-to", "php_serialize")
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "1 sessions failed"))
+}
+
+func TestCLI_ConvertKeepOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phpsessgo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	data := `z|i:1;cart|a:3:{i:9;s:1:"c";i:1;s:1:"a";s:1:"k";s:1:"b";}user|O:4:"User":2:{s:4:"name";s:3:"bob";s:5:"email";s:0:"";}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sess_abc1"), []byte(data), 0600))
+
+	_, err = runCLI(t, "", "convert", "-save-path", dir, "-to", "json")
+	require.NoError(t, err)
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "sess_abc1"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(raw), `{"z":1,"cart":{"array":[[9,"c"],[1,"a"],["k","b"]]}`), string(raw))
+
+	_, err = runCLI(t, "", "convert", "-save-path", dir, "-from", "json", "-to", "php")
+	require.NoError(t, err)
+	raw, err = ioutil.ReadFile(filepath.Join(dir, "sess_abc1"))
+	require.NoError(t, err)
+	require.Equal(t, data, string(raw))
+}
+
+func TestCLI_ConvertRedis(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "phpsessgo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cursorFile := filepath.Join(dir, "cursor")
+
+	s.Set("PHPREDIS_SESSION:abc1", `login_ok|b:1;`)
+	s.SetTTL("PHPREDIS_SESSION:abc1", 5*time.Minute)
+	s.Set("PHPREDIS_SESSION:abc2", `count|i:3;`)
+	s.Set("other:abc3", `count|i:3;`)
+
+	// resume after the only node was fully scanned
+	require.NoError(t, ioutil.WriteFile(cursorFile, []byte("1:0\n"), 0644))
+	_, err = runCLI(t, "", "convert", "-save-handler", "redis", "-save-path", "tcp://"+s.Addr(),
+		"-from", "php", "-to", "json", "-cursor-file", cursorFile)
+	require.NoError(t, err)
+	raw, _ := s.Get("PHPREDIS_SESSION:abc1")
+	require.Equal(t, `login_ok|b:1;`, raw)
+	_, err = os.Stat(cursorFile)
+	require.True(t, os.IsNotExist(err))
+
+	_, err = runCLI(t, "", "convert", "-save-handler", "redis", "-save-path", "tcp://"+s.Addr(),
+		"-from", "php", "-to", "json", "-batch", "1", "-cursor-file", cursorFile)
+	require.NoError(t, err)
+	raw, _ = s.Get("PHPREDIS_SESSION:abc1")
+	require.Equal(t, `{"login_ok":true}`, raw)
+	require.Equal(t, 5*time.Minute, s.TTL("PHPREDIS_SESSION:abc1"))
+	raw, _ = s.Get("PHPREDIS_SESSION:abc2")
+	require.Equal(t, `{"count":3}`, raw)
+	raw, _ = s.Get("other:abc3")
+	require.Equal(t, `count|i:3;`, raw)
+	_, err = os.Stat(cursorFile)
+	require.True(t, os.IsNotExist(err))
+
+	require.NoError(t, ioutil.WriteFile(cursorFile, []byte("garbage"), 0644))
+	_, err = runCLI(t, "", "convert", "-save-handler", "redis", "-save-path", "tcp://"+s.Addr(),
+		"-to", "json", "-cursor-file", cursorFile)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "invalid cursor"))
+}
@@ -0,0 +1,7 @@
+package main
+
+import (
+	// database/sql drivers of the sql save handler
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
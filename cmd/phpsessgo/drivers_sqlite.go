@@ -0,0 +1,9 @@
+//go:build cgo
+// +build cgo
+
+package main
+
+import (
+	// sqlite3 driver need cgo, it is left out of static builds
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -0,0 +1,76 @@
+// Command phpsessgo inspect and move PHP sessions stored by any handler supported by the library.
+//
+//	phpsessgo list   -save-handler redis -save-path "tcp://127.0.0.1:6379?prefix=PHPREDIS_SESSION:"
+//	phpsessgo decode -save-handler files -save-path /var/lib/php/sessions <id>
+//	phpsessgo export -save-handler files -save-path /var/lib/php/sessions -older-than 1h > sessions.jsonl
+//	phpsessgo import -save-handler redis -save-path tcp://127.0.0.1:6379 < sessions.jsonl
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(c *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"list":    {"list sessions matching the filters", (*cli).list},
+	"get":     {"print raw data of the sessions", (*cli).get},
+	"decode":  {"print decoded data of the sessions as JSON", (*cli).decode},
+	"set":     {"write raw data of the session from the argument or stdin", (*cli).set},
+	"delete":  {"destroy the sessions", (*cli).delete},
+	"export":  {"write sessions matching the filters as JSON lines", (*cli).export},
+	"import":  {"read sessions written by export", (*cli).importSessions},
+	"convert": {"rewrite sessions to another serialize handler in place", (*cli).convert},
+}
+
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+func (c *cli) run(args []string) int {
+	if len(args) == 0 {
+		c.usage()
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(c.stderr, "phpsessgo: unknown command %q\n", args[0])
+		c.usage()
+		return 2
+	}
+
+	if err := cmd.run(c, args[1:]); err != nil {
+		fmt.Fprintf(c.stderr, "phpsessgo %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func (c *cli) usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(c.stderr, "usage: phpsessgo <command> [flags] [args]")
+	fmt.Fprintln(c.stderr, "\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(c.stderr, "  %-8s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(c.stderr, "\nrun \"phpsessgo <command> -h\" for the flags of the command")
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runCLI(t *testing.T, stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	c := &cli{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr}
+	if code := c.run(args); code != 0 {
+		return stdout.String(), &exitError{code: code, stderr: stderr.String()}
+	}
+	return stdout.String(), nil
+}
+
+type exitError struct {
+	code   int
+	stderr string
+}
+
+func (e *exitError) Error() string {
+	return e.stderr
+}
+
+func TestCLI_FilesRoundTrip(t *testing.T) {
+	src, err := ioutil.TempDir("", "phpsessgo")
+	require.NoError(t, err)
+	defer os.RemoveAll(src)
+	dst, err := ioutil.TempDir("", "phpsessgo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dst)
+
+	_, err = runCLI(t, "", "set", "-save-path", src, "abc1", `user|a:1:{s:4:"name";s:3:"bob";}`)
+	require.NoError(t, err)
+	_, err = runCLI(t, `count|i:3;`, "set", "-save-path", src, "abc2")
+	require.NoError(t, err)
+	_, err = runCLI(t, "", "set", "-save-path", src, "abc3", `foo|s:10:"bar";`)
+	require.Error(t, err)
+
+	// binary data survive the export as base64
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "sess_zzz9"), []byte{0xff, 0x00, 0x01}, 0600))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(src, "sess_zzz9"), old, old))
+
+	out, err := runCLI(t, "", "list", "-save-path", src, "-prefix", "abc")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "abc1\t"))
+
+	out, err = runCLI(t, "", "list", "-save-path", src, "-older-than", "1h")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out, "zzz9\t"))
+
+	out, err = runCLI(t, "", "decode", "-save-path", src, "abc1")
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+	require.Equal(t, map[string]interface{}{"user": map[string]interface{}{"name": "bob"}}, decoded)
+
+	out, err = runCLI(t, "", "decode", "-save-path", src, "-tagged", "abc1")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"user": {"array": [["name", "bob"]]}}`, out)
+
+	out, err = runCLI(t, "", "decode", "-save-path", src, "-format", "print_r", "abc2")
+	require.NoError(t, err)
+	require.Equal(t, "Array\n(\n    [count] => 3\n)\n", out)
+	_, err = runCLI(t, "", "decode", "-save-path", src, "-format", "yaml", "abc2")
+	require.Error(t, err)
+
+	exported, err := runCLI(t, "", "export", "-save-path", src)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(exported), "\n"), 3)
+
+	_, err = runCLI(t, exported, "import", "-save-path", dst, "-newer-than", "1h")
+	require.NoError(t, err)
+	out, err = runCLI(t, "", "get", "-save-path", dst, "abc2")
+	require.NoError(t, err)
+	require.Equal(t, "count|i:3;\n", out)
+	_, err = os.Stat(filepath.Join(dst, "sess_zzz9"))
+	require.True(t, os.IsNotExist(err))
+
+	_, err = runCLI(t, exported, "import", "-save-path", dst, "-prefix", "zzz")
+	require.NoError(t, err)
+	raw, err := ioutil.ReadFile(filepath.Join(dst, "sess_zzz9"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xff, 0x00, 0x01}, raw)
+
+	_, err = runCLI(t, "", "delete", "-save-path", dst, "abc1", "abc2")
+	require.NoError(t, err)
+	out, err = runCLI(t, "", "list", "-save-path", dst)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out, "zzz9\t"))
+}
+
+func TestCLI_Usage(t *testing.T) {
+	_, err := runCLI(t, "")
+	require.Error(t, err)
+	_, err = runCLI(t, "", "frobnicate")
+	require.Error(t, err)
+	_, err = runCLI(t, "", "list", "-save-handler", "memcached", "-save-path", "x")
+	require.Error(t, err)
+	_, err = runCLI(t, "", "list", "-save-handler", "sql", "-save-path", "x")
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+//go:build cgo
+// +build cgo
+
+package main
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLI_SQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phpsessgo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	dsn := filepath.Join(dir, "sessions.db")
+
+	db, err := sql.Open("sqlite3", dsn)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE sessions (
+		sess_id VARCHAR(128) NOT NULL PRIMARY KEY,
+		sess_data BLOB NOT NULL,
+		sess_lifetime INTEGER NOT NULL,
+		sess_time INTEGER NOT NULL
+	)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store := []string{"-save-handler", "sql", "-sql-driver", "sqlite3", "-save-path", dsn}
+	args := func(command string, extra ...string) []string {
+		return append(append([]string{command}, store...), extra...)
+	}
+
+	_, err = runCLI(t, "", args("set", "abc1", `login_ok|b:1;`)...)
+	require.NoError(t, err)
+	_, err = runCLI(t, "", args("set", "abc2", `count|i:3;`)...)
+	require.NoError(t, err)
+
+	out, err := runCLI(t, "", args("list")...)
+	require.NoError(t, err)
+	require.Contains(t, out, "abc1")
+	require.Contains(t, out, "abc2")
+
+	_, err = runCLI(t, "", args("convert", "-to", "php_serialize")...)
+	require.NoError(t, err)
+	out, err = runCLI(t, "", args("get", "abc1")...)
+	require.NoError(t, err)
+	require.Equal(t, `a:1:{s:8:"login_ok";b:1;}`+"\n", out)
+
+	_, err = runCLI(t, "", args("delete", "abc2")...)
+	require.NoError(t, err)
+	out, err = runCLI(t, "", args("list")...)
+	require.NoError(t, err)
+	require.NotContains(t, out, "abc2")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/eligundry/phpsessgo"
+)
+
+// options shared by all commands
+type options struct {
+	saveHandler      string
+	savePath         string
+	serializeHandler string
+	expiration       time.Duration
+
+	sqlDriver  string
+	sqlDialect string
+	sqlTable   string
+
+	prefix    string
+	olderThan time.Duration
+	newerThan time.Duration
+}
+
+func newFlagSet(name string, output io.Writer, opts *options) *flag.FlagSet {
+	flags := flag.NewFlagSet("phpsessgo "+name, flag.ContinueOnError)
+	flags.SetOutput(output)
+
+	flags.StringVar(&opts.saveHandler, "save-handler", "files", "session.save_handler: files, redis or sql")
+	flags.StringVar(&opts.savePath, "save-path", "", "session.save_path, the data source name with sql")
+	flags.StringVar(&opts.serializeHandler, "serialize-handler", "php", "session.serialize_handler: php, php_binary, php_serialize or json")
+	flags.DurationVar(&opts.expiration, "expiration", 1440*time.Second, "session.gc_maxlifetime of written sessions")
+	flags.StringVar(&opts.sqlDriver, "sql-driver", "", "database/sql driver: mysql, postgres or sqlite3 when built with cgo")
+	flags.StringVar(&opts.sqlDialect, "sql-dialect", "", "sql dialect: mysql, postgres or sqlite, default to the one of the driver")
+	flags.StringVar(&opts.sqlTable, "sql-table", "", "sql session table, default to sessions")
+	return flags
+}
+
+// addFilterFlags register the flags of the commands walking the store
+func addFilterFlags(flags *flag.FlagSet, opts *options) {
+	flags.StringVar(&opts.prefix, "prefix", "", "only sessions with ID starting with the prefix")
+	flags.DurationVar(&opts.olderThan, "older-than", 0, "only sessions not written for the duration")
+	flags.DurationVar(&opts.newerThan, "newer-than", 0, "only sessions written within the duration")
+}
+
+// openStore create the session handler selected by the options
+func openStore(opts *options) (phpsessgo.SessionHandler, error) {
+	switch opts.saveHandler {
+	case "files":
+		return phpsessgo.NewFileSessionHandler(opts.savePath)
+	case "redis":
+		return phpsessgo.NewShardedRedisSessionHandler(opts.savePath, opts.expiration)
+	case "sql":
+		if opts.sqlDriver == "" {
+			return nil, fmt.Errorf("-sql-driver is required with sql save handler")
+		}
+		dialect := opts.sqlDialect
+		if dialect == "" {
+			dialect = driverDialects[opts.sqlDriver]
+		}
+		db, err := sql.Open(opts.sqlDriver, opts.savePath)
+		if err != nil {
+			return nil, err
+		}
+		handler, err := phpsessgo.NewSQLSessionHandler(db, phpsessgo.SQLSessionHandlerConfig{
+			Dialect:    phpsessgo.SQLDialect(dialect),
+			Table:      opts.sqlTable,
+			Expiration: opts.expiration,
+		})
+		if err != nil {
+			db.Close()
+		}
+		return handler, err
+	default:
+		return nil, fmt.Errorf("unsupported save handler %q", opts.saveHandler)
+	}
+}
+
+// driverDialects is the default dialect of the linked drivers
+var driverDialects = map[string]string{
+	"mysql":    string(phpsessgo.SQLDialectMySQL),
+	"postgres": string(phpsessgo.SQLDialectPostgres),
+	"sqlite3":  string(phpsessgo.SQLDialectSQLite),
+}
+
+// match apply the filters to the session, age filters never match unknown write time
+func (opts *options) match(info phpsessgo.SessionInfo, now time.Time) bool {
+	if !strings.HasPrefix(info.ID, opts.prefix) {
+		return false
+	}
+	if opts.olderThan > 0 && (info.LastWrite.IsZero() || now.Sub(info.LastWrite) < opts.olderThan) {
+		return false
+	}
+	if opts.newerThan > 0 && (info.LastWrite.IsZero() || now.Sub(info.LastWrite) > opts.newerThan) {
+		return false
+	}
+	return true
+}
+
+// walk call fn for every session of the store matching the filters
+func walk(store phpsessgo.SessionHandler, opts *options, fn func(info phpsessgo.SessionInfo) error) error {
+	walker, ok := store.(phpsessgo.SessionWalker)
+	if !ok {
+		return fmt.Errorf("save handler %q can't list sessions", opts.saveHandler)
+	}
+
+	now := time.Now()
+	return walker.WalkSessions(func(info phpsessgo.SessionInfo) error {
+		if !opts.match(info, now) {
+			return nil
+		}
+		return fn(info)
+	})
+}
@@ -0,0 +1,235 @@
+package phpsessgo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// Compression is name of the session data compression, same as redis.session.compression of phpredis
+type Compression string
+
+const (
+	// CompressionLZF is raw liblzf output, phpredis store it without header
+	CompressionLZF Compression = "lzf"
+	// CompressionLZ4 is LZ4 block prefixed with crc8 of the length and 32-bit little-endian length, like phpredis
+	CompressionLZ4 Compression = "lz4"
+	// CompressionGzip is gzip stream, not understood by phpredis
+	CompressionGzip Compression = "gzip"
+)
+
+// ErrUnsupportedCompression is returned for zstd data written by phpredis
+var ErrUnsupportedCompression = errors.New("phpsessgo: unsupported session compression")
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// CompressingSessionHandlerConfig configure CompressingSessionHandler
+type CompressingSessionHandlerConfig struct {
+	// Compression of written data
+	Compression Compression
+	// Threshold is minimum size of the data to compress, smaller data is stored as is
+	Threshold int
+	// MaxSize of decompressed data, larger data is treated as not compressed.
+	// Default to DefaultDecompressedMaxSize
+	MaxSize int
+}
+
+// DefaultDecompressedMaxSize is the default limit of decompressed session size
+const DefaultDecompressedMaxSize = 16 << 20
+
+// CompressingSessionHandler compress session data of the wrapped handler.
+// Like phpredis, data which can't be decompressed is returned as is,
+// so uncompressed sessions and sessions below the threshold stay readable
+type CompressingSessionHandler struct {
+	SessionHandler
+	config CompressingSessionHandlerConfig
+}
+
+// NewCompressingSessionHandler create new instance of CompressingSessionHandler
+func NewCompressingSessionHandler(handler SessionHandler, config CompressingSessionHandlerConfig) (*CompressingSessionHandler, error) {
+	switch config.Compression {
+	case CompressionLZF, CompressionLZ4, CompressionGzip:
+	default:
+		return nil, fmt.Errorf("phpsessgo: unknown session compression %q", config.Compression)
+	}
+	if config.Threshold < 0 {
+		return nil, fmt.Errorf("phpsessgo: compression threshold must not be negative")
+	}
+	if config.MaxSize < 0 {
+		return nil, fmt.Errorf("phpsessgo: decompressed max size must not be negative")
+	}
+	if config.MaxSize == 0 {
+		config.MaxSize = DefaultDecompressedMaxSize
+	}
+
+	return &CompressingSessionHandler{
+		SessionHandler: handler,
+		config:         config,
+	}, nil
+}
+
+func (h *CompressingSessionHandler) Read(sessionID string) (string, error) {
+	data, err := h.SessionHandler.Read(sessionID)
+	if err != nil {
+		return "", err
+	}
+	return h.decompress(data)
+}
+
+func (h *CompressingSessionHandler) Write(sessionID string, sessionData string) error {
+	compressed, err := h.compress(sessionData)
+	if err != nil {
+		return err
+	}
+	return h.SessionHandler.Write(sessionID, compressed)
+}
+
+// ReadContext read and decompress the session data with context of the wrapped handler
+func (h *CompressingSessionHandler) ReadContext(ctx context.Context, sessionID string) (string, error) {
+	data, err := NewContextSessionHandler(h.SessionHandler).ReadContext(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	return h.decompress(data)
+}
+
+// WriteContext compress and write the session data with context of the wrapped handler
+func (h *CompressingSessionHandler) WriteContext(ctx context.Context, sessionID string, sessionData string) error {
+	compressed, err := h.compress(sessionData)
+	if err != nil {
+		return err
+	}
+	return NewContextSessionHandler(h.SessionHandler).WriteContext(ctx, sessionID, compressed)
+}
+
+// Unwrap return the wrapped handler
+func (h *CompressingSessionHandler) Unwrap() SessionHandler {
+	return h.SessionHandler
+}
+
+func (h *CompressingSessionHandler) bindRequest(w http.ResponseWriter, r *http.Request) (SessionHandler, bool, error) {
+	inner, bound, err := bindRequest(h.SessionHandler, w, r)
+	if !bound || err != nil {
+		return h, false, err
+	}
+	return &CompressingSessionHandler{SessionHandler: inner, config: h.config}, true, nil
+}
+
+// UpdateTimestamp of the wrapped handler when it is supported
+func (h *CompressingSessionHandler) UpdateTimestamp(sessionID string, sessionData string) error {
+	return h.UpdateTimestampContext(context.Background(), sessionID, sessionData)
+}
+
+// UpdateTimestampContext is UpdateTimestamp with context of the wrapped handler
+func (h *CompressingSessionHandler) UpdateTimestampContext(ctx context.Context, sessionID string, sessionData string) error {
+	if _, ok := h.SessionHandler.(SessionUpdateTimestampHandler); !ok {
+		return nil
+	}
+	compressed, err := h.compress(sessionData)
+	if err != nil {
+		return err
+	}
+	return updateTimestamp(ctx, h.SessionHandler, sessionID, compressed)
+}
+
+// Destroy the session of the wrapped handler when it is supported
+func (h *CompressingSessionHandler) Destroy(sessionID string) error {
+	return h.DestroyContext(context.Background(), sessionID)
+}
+
+// DestroyContext is Destroy with context of the wrapped handler
+func (h *CompressingSessionHandler) DestroyContext(ctx context.Context, sessionID string) error {
+	return destroySession(ctx, h.SessionHandler, sessionID)
+}
+
+func (h *CompressingSessionHandler) compress(sessionData string) (string, error) {
+	if sessionData == "" || len(sessionData) < h.config.Threshold {
+		return sessionData, nil
+	}
+
+	data := []byte(sessionData)
+	switch h.config.Compression {
+	case CompressionLZF:
+		return string(lzfCompress(data)), nil
+	case CompressionLZ4:
+		header := make([]byte, 5)
+		binary.LittleEndian.PutUint32(header[1:], uint32(len(data)))
+		header[0] = crc8(header[1:])
+		return string(append(header, lz4CompressBlock(data)...)), nil
+	default:
+		var buffer bytes.Buffer
+		writer := gzip.NewWriter(&buffer)
+		if _, err := writer.Write(data); err != nil {
+			return "", err
+		}
+		if err := writer.Close(); err != nil {
+			return "", err
+		}
+		return buffer.String(), nil
+	}
+}
+
+// decompress is adoption of redis_uncompress() of phpredis, data of the configured compression
+// which fail to decompress is returned as is. Zstd data is refused instead of returned compressed
+func (h *CompressingSessionHandler) decompress(sessionData string) (string, error) {
+	data := []byte(sessionData)
+	if len(data) == 0 {
+		return sessionData, nil
+	}
+	if bytes.HasPrefix(data, zstdMagic) {
+		return "", ErrUnsupportedCompression
+	}
+
+	switch h.config.Compression {
+	case CompressionLZF:
+		if decompressed, err := lzfDecompress(data, h.config.MaxSize); err == nil {
+			return string(decompressed), nil
+		}
+	case CompressionLZ4:
+		// the crc8 match by chance for 1 of 256 plain sessions, the size is checked before decoding
+		if len(data) > 5 && crc8(data[1:5]) == data[0] {
+			size := int64(binary.LittleEndian.Uint32(data[1:5]))
+			if size > int64(h.config.MaxSize) {
+				return sessionData, nil
+			}
+			if decompressed, err := lz4DecompressBlock(data[5:], int(size)); err == nil {
+				return string(decompressed), nil
+			}
+		}
+	case CompressionGzip:
+		if bytes.HasPrefix(data, gzipMagic) {
+			if reader, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
+				limited := io.LimitReader(reader, int64(h.config.MaxSize)+1)
+				if decompressed, err := ioutil.ReadAll(limited); err == nil && len(decompressed) <= h.config.MaxSize {
+					return string(decompressed), nil
+				}
+			}
+		}
+	}
+	return sessionData, nil
+}
+
+// crc8 is checksum of the LZ4 length header of phpredis
+func crc8(data []byte) byte {
+	crc := byte(0xff)
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x31
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
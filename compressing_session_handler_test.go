@@ -0,0 +1,144 @@
+package phpsessgo_test
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/stretchr/testify/require"
+)
+
+const magentoValidatorSession = `core|a:1:{s:23:"_session_validator_data";a:4:{s:11:"remote_addr";s:13:"195.91.253.98";s:8:"http_via";s:0:"";s:20:"http_x_forwarded_for";s:0:"";s:15:"http_user_agent";s:9:"Mozilla/5";}}` +
+	`customer|a:1:{s:23:"_session_validator_data";a:4:{s:11:"remote_addr";s:13:"195.91.253.98";s:8:"http_via";s:0:"";s:20:"http_x_forwarded_for";s:0:"";s:15:"http_user_agent";s:9:"Mozilla/5";}}`
+
+// phpredisLZ4Session is the session compressed by liblz4 with phpredis header
+const phpredisLZ4Session = "3774010000" +
+	"f01d636f72657c613a313a7b733a32333a225f73657373696f6e5f76616c696461746f725f64617461223b613a342400f01531313a2272656d6f74655f61646472223b733a31333a223139352e39312e3235332e39381500b0383a22687474705f7669610f0030303a2207002332301700b0785f666f727761726465640a00001c000323002331352300a0757365725f6167656e741e00ff09393a224d6f7a696c6c612f35223b7d7d637573746f6d6572bc009c5035223b7d7d"
+
+// userSession is compressed below by LZ4_compress_default() of liblz4 1.9.4, the
+// library phpredis link to, and by lzf_compress() of golzf, a port of liblzf 3.6
+const userSession = `count|i:3;user|a:2:{s:4:"name";s:3:"bob";s:4:"role";s:5:"admin";}user_copy|a:2:{s:4:"name";s:3:"bob";s:4:"role";s:5:"admin";}`
+
+const liblz4UserSession = "f67d000000" +
+	"f018636f756e747c693a333b757365727c613a323a7b733a343a226e616d65223b733a333a22626f620a0061343a22726f6c1500b0353a2261646d696e223b7d37005f5f636f70793c001b50696e223b7d"
+
+const liblzfUserSession = "1f636f756e747c693a333b757365727c613a323a7b733a343a226e616d65223b73063a333a22626f624009201402726f6c60140a353a2261646d696e223b7d4036045f636f7079e0283b013b7d"
+
+func TestCompressingSessionHandler(t *testing.T) {
+	for _, compression := range []phpsessgo.Compression{phpsessgo.CompressionLZF, phpsessgo.CompressionLZ4, phpsessgo.CompressionGzip} {
+		t.Run(string(compression), func(t *testing.T) {
+			store := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+			defer store.Close()
+
+			handler, err := phpsessgo.NewCompressingSessionHandler(store, phpsessgo.CompressingSessionHandlerConfig{
+				Compression: compression,
+				Threshold:   64,
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, handler.Write("some-session-id", magentoValidatorSession))
+			compressed, _ := store.Read("some-session-id")
+			require.Less(t, len(compressed), len(magentoValidatorSession))
+
+			data, err := handler.ReadContext(context.Background(), "some-session-id")
+			require.NoError(t, err)
+			require.Equal(t, magentoValidatorSession, data)
+
+			require.NoError(t, handler.WriteContext(context.Background(), "small-session-id", `hello|s:5:"world";`))
+			stored, _ := store.Read("small-session-id")
+			require.Equal(t, `hello|s:5:"world";`, stored)
+
+			data, err = handler.Read("small-session-id")
+			require.NoError(t, err)
+			require.Equal(t, `hello|s:5:"world";`, data)
+		})
+	}
+}
+
+func TestCompressingSessionHandler_PHPRedis(t *testing.T) {
+	store := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer store.Close()
+
+	t.Run("lz4", func(t *testing.T) {
+		raw, _ := hex.DecodeString(phpredisLZ4Session)
+		require.NoError(t, store.Write("lz4-session-id", string(raw)))
+
+		handler, err := phpsessgo.NewCompressingSessionHandler(store, phpsessgo.CompressingSessionHandlerConfig{Compression: phpsessgo.CompressionLZ4})
+		require.NoError(t, err)
+
+		data, err := handler.Read("lz4-session-id")
+		require.NoError(t, err)
+		require.Equal(t, magentoValidatorSession, data)
+	})
+
+	t.Run("liblz4", func(t *testing.T) {
+		raw, _ := hex.DecodeString(liblz4UserSession)
+		require.NoError(t, store.Write("liblz4-session-id", string(raw)))
+
+		handler, err := phpsessgo.NewCompressingSessionHandler(store, phpsessgo.CompressingSessionHandlerConfig{Compression: phpsessgo.CompressionLZ4})
+		require.NoError(t, err)
+
+		data, err := handler.Read("liblz4-session-id")
+		require.NoError(t, err)
+		require.Equal(t, userSession, data)
+	})
+
+	t.Run("liblzf", func(t *testing.T) {
+		raw, _ := hex.DecodeString(liblzfUserSession)
+		require.NoError(t, store.Write("liblzf-session-id", string(raw)))
+
+		handler, err := phpsessgo.NewCompressingSessionHandler(store, phpsessgo.CompressingSessionHandlerConfig{Compression: phpsessgo.CompressionLZF})
+		require.NoError(t, err)
+
+		data, err := handler.Read("liblzf-session-id")
+		require.NoError(t, err)
+		require.Equal(t, userSession, data)
+	})
+
+	t.Run("lzf", func(t *testing.T) {
+		// literal "abc" followed by back reference of 9 bytes at distance 3
+		require.NoError(t, store.Write("lzf-session-id", "\x02abc\xe0\x00\x02"))
+
+		handler, err := phpsessgo.NewCompressingSessionHandler(store, phpsessgo.CompressingSessionHandlerConfig{Compression: phpsessgo.CompressionLZF})
+		require.NoError(t, err)
+
+		data, err := handler.Read("lzf-session-id")
+		require.NoError(t, err)
+		require.Equal(t, strings.Repeat("abc", 4), data)
+	})
+
+	t.Run("plain with matching lz4 header", func(t *testing.T) {
+		// crc8("lk|s") is 'b', the header claim 1.8 GB of decompressed data
+		require.NoError(t, store.Write("plain-session-id", `blk|s:3:"abc";`))
+
+		handler, err := phpsessgo.NewCompressingSessionHandler(store, phpsessgo.CompressingSessionHandlerConfig{Compression: phpsessgo.CompressionLZ4})
+		require.NoError(t, err)
+
+		data, err := handler.Read("plain-session-id")
+		require.NoError(t, err)
+		require.Equal(t, `blk|s:3:"abc";`, data)
+	})
+
+	t.Run("zstd", func(t *testing.T) {
+		require.NoError(t, store.Write("zstd-session-id", "\x28\xb5\x2f\xfd\x00"))
+
+		handler, err := phpsessgo.NewCompressingSessionHandler(store, phpsessgo.CompressingSessionHandlerConfig{Compression: phpsessgo.CompressionLZ4})
+		require.NoError(t, err)
+
+		_, err = handler.Read("zstd-session-id")
+		require.Equal(t, phpsessgo.ErrUnsupportedCompression, err)
+	})
+}
+
+func TestNewCompressingSessionHandler_InvalidConfig(t *testing.T) {
+	_, err := phpsessgo.NewCompressingSessionHandler(nil, phpsessgo.CompressingSessionHandlerConfig{Compression: "zstd"})
+	require.Error(t, err)
+
+	_, err = phpsessgo.NewCompressingSessionHandler(nil, phpsessgo.CompressingSessionHandlerConfig{Compression: phpsessgo.CompressionLZF, Threshold: -1})
+	require.Error(t, err)
+
+	_, err = phpsessgo.NewCompressingSessionHandler(nil, phpsessgo.CompressingSessionHandlerConfig{Compression: phpsessgo.CompressionLZF, MaxSize: -1})
+	require.Error(t, err)
+}
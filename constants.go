@@ -3,4 +3,6 @@ package phpsessgo
 const (
 	DefaultSessionName    = "PHPSESSID"
 	DefaultRedisKeyPrefix = "PHPREDIS_SESSION:"
+	DefaultMetadataKey    = "__phpsessgo_meta"
+	DefaultFingerprintKey = "__phpsessgo_fingerprint"
 )
@@ -0,0 +1,278 @@
+package phpsessgo
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPSessionHandler is SessionHandler keeping the data in the request and the response
+// instead of a storage. SessionManager use it in place of Read and Write, also through
+// CompressingSessionHandler, EncryptingSessionHandler and MigratingSessionHandler wrapping it
+type HTTPSessionHandler interface {
+	SessionHandler
+	ReadRequest(r *http.Request, sessionID string) (string, error)
+	WriteResponse(w http.ResponseWriter, r *http.Request, sessionID, sessionData string) error
+}
+
+const (
+	// DefaultSessionDataCookieName is name of the first cookie holding the session data
+	DefaultSessionDataCookieName = "PHPSESSDATA"
+	// DefaultCookieChunkSize keep every cookie below the 4KB browser limit including its attributes
+	DefaultCookieChunkSize = 3800
+	// DefaultCookieMaxSize is default limit of the sealed data across all chunks
+	DefaultCookieMaxSize = 4 * DefaultCookieChunkSize
+)
+
+var (
+	// ErrCookieSessionTooLarge is returned when the sealed session data exceed CookieSessionHandlerConfig.MaxSize
+	ErrCookieSessionTooLarge = errors.New("phpsessgo: session data too large for cookies")
+	// ErrCookieSessionRequireRequest is returned when CookieSessionHandler is used without the request
+	ErrCookieSessionRequireRequest = errors.New("phpsessgo: cookie session handler require the request, use it with SessionManager")
+	// ErrHeadersCommitted is returned when HTTPSessionHandler save the session after the response headers were written
+	ErrHeadersCommitted = errors.New("phpsessgo: session saved after the response headers were written")
+	// ErrCachedHTTPSessionHandler is returned when CachingSessionHandler wrap HTTPSessionHandler,
+	// the data is in the request already so there is nothing to cache
+	ErrCachedHTTPSessionHandler = errors.New("phpsessgo: caching session handler can't wrap HTTPSessionHandler")
+	// ErrUnboundHTTPSessionHandler is returned when HTTPSessionHandler is wrapped by handler which
+	// can't bind it to the request
+	ErrUnboundHTTPSessionHandler = errors.New("phpsessgo: HTTPSessionHandler wrapped by handler which can't bind it to the request")
+)
+
+// WrappingSessionHandler is SessionHandler decorating other one. SessionManager reject
+// HTTPSessionHandler wrapped by it, as the wrapped handler can't be bound to the request
+type WrappingSessionHandler interface {
+	SessionHandler
+	Unwrap() SessionHandler
+}
+
+// requestBinder is WrappingSessionHandler able to bind the wrapped HTTPSessionHandler to the request,
+// it return copy of itself wrapping the bound handler and report whether it was bound
+type requestBinder interface {
+	bindRequest(w http.ResponseWriter, r *http.Request) (SessionHandler, bool, error)
+}
+
+// CookieSessionHandlerConfig configure CookieSessionHandler
+type CookieSessionHandlerConfig struct {
+	// Key is AES key of 16, 24 or 32 bytes sealing the data with AES-GCM
+	Key []byte
+	// CookieName of the first chunk, next chunks are suffixed with "_1", "_2", ...
+	// Default to DefaultSessionDataCookieName
+	CookieName string
+	// ChunkSize is maximum length of one cookie value, default to DefaultCookieChunkSize
+	ChunkSize int
+	// MaxSize is maximum length of the sealed data, default to DefaultCookieMaxSize
+	MaxSize int
+	// Expiration of the cookies, zero mean until the browser is closed
+	Expiration time.Duration
+
+	CookiePath     string
+	CookieDomain   string
+	CookieSecure   bool
+	CookieHttpOnly bool
+	CookieSameSite http.SameSite
+}
+
+// CookieSessionHandler keep the encoded session in encrypted and authenticated cookies,
+// split across more cookies when it doesn't fit into one
+type CookieSessionHandler struct {
+	config CookieSessionHandlerConfig
+	aead   cipher.AEAD
+}
+
+// NewCookieSessionHandler create new instance of CookieSessionHandler
+func NewCookieSessionHandler(config CookieSessionHandlerConfig) (*CookieSessionHandler, error) {
+	block, err := aes.NewCipher(config.Key)
+	if err != nil {
+		return nil, fmt.Errorf("phpsessgo: cookie session key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.CookieName == "" {
+		config.CookieName = DefaultSessionDataCookieName
+	}
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = DefaultCookieChunkSize
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultCookieMaxSize
+	}
+
+	return &CookieSessionHandler{config: config, aead: aead}, nil
+}
+
+func (h *CookieSessionHandler) Close() {}
+
+func (h *CookieSessionHandler) Read(sessionID string) (string, error) {
+	return "", ErrCookieSessionRequireRequest
+}
+
+func (h *CookieSessionHandler) Write(sessionID string, sessionData string) error {
+	return ErrCookieSessionRequireRequest
+}
+
+// ReadRequest join the chunks and open the data, data which fail to open is treated as empty session
+func (h *CookieSessionHandler) ReadRequest(r *http.Request, sessionID string) (string, error) {
+	var builder strings.Builder
+	for i := 0; ; i++ {
+		cookie, err := r.Cookie(h.chunkName(i))
+		if err != nil {
+			break
+		}
+		builder.WriteString(cookie.Value)
+	}
+	if builder.Len() == 0 || builder.Len() > h.config.MaxSize {
+		return "", nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(builder.String())
+	if err != nil || len(sealed) < h.aead.NonceSize() {
+		return "", nil
+	}
+	nonce := sealed[:h.aead.NonceSize()]
+	data, err := h.aead.Open(nil, nonce, sealed[h.aead.NonceSize():], []byte(sessionID))
+	if err != nil {
+		return "", nil
+	}
+	return string(data), nil
+}
+
+// WriteResponse seal the data into chunk cookies and expire chunks left from bigger data of the request
+func (h *CookieSessionHandler) WriteResponse(w http.ResponseWriter, r *http.Request, sessionID, sessionData string) error {
+	var chunks []string
+	if sessionData != "" {
+		nonce := make([]byte, h.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return err
+		}
+		value := base64.RawURLEncoding.EncodeToString(h.aead.Seal(nonce, nonce, []byte(sessionData), []byte(sessionID)))
+		if len(value) > h.config.MaxSize {
+			return fmt.Errorf("%w: %d bytes, limit %d", ErrCookieSessionTooLarge, len(value), h.config.MaxSize)
+		}
+
+		for len(value) > h.config.ChunkSize {
+			chunks = append(chunks, value[:h.config.ChunkSize])
+			value = value[h.config.ChunkSize:]
+		}
+		chunks = append(chunks, value)
+	}
+
+	for i, chunk := range chunks {
+		http.SetCookie(w, h.cookie(h.chunkName(i), chunk))
+	}
+
+	if r == nil {
+		return nil
+	}
+	for i := len(chunks); ; i++ {
+		if _, err := r.Cookie(h.chunkName(i)); err != nil {
+			break
+		}
+		cookie := h.cookie(h.chunkName(i), "")
+		cookie.MaxAge = -1
+		http.SetCookie(w, cookie)
+	}
+	return nil
+}
+
+func (h *CookieSessionHandler) chunkName(i int) string {
+	if i == 0 {
+		return h.config.CookieName
+	}
+	return h.config.CookieName + "_" + strconv.Itoa(i)
+}
+
+func (h *CookieSessionHandler) cookie(name, value string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     h.config.CookiePath,
+		Domain:   h.config.CookieDomain,
+		Secure:   h.config.CookieSecure,
+		HttpOnly: h.config.CookieHttpOnly,
+		SameSite: h.config.CookieSameSite,
+	}
+	if h.config.Expiration > 0 {
+		cookie.MaxAge = int(h.config.Expiration / time.Second)
+	}
+	return cookie
+}
+
+// bindRequest bind HTTPSessionHandler to the request and the response, also when it is wrapped
+// by handler implementing requestBinder. It report whether the handler was bound
+func bindRequest(handler SessionHandler, w http.ResponseWriter, r *http.Request) (SessionHandler, bool, error) {
+	switch h := handler.(type) {
+	case HTTPSessionHandler:
+		return &requestSessionHandler{HTTPSessionHandler: h, w: w, r: r}, true, nil
+	case requestBinder:
+		return h.bindRequest(w, r)
+	case WrappingSessionHandler:
+		if wrapsHTTPSessionHandler(h.Unwrap()) {
+			return handler, false, ErrUnboundHTTPSessionHandler
+		}
+	}
+	return handler, false, nil
+}
+
+// wrapsHTTPSessionHandler report whether the handler is HTTPSessionHandler or wrap one
+func wrapsHTTPSessionHandler(handler SessionHandler) bool {
+	switch h := handler.(type) {
+	case HTTPSessionHandler:
+		return true
+	case *MigratingSessionHandler:
+		return wrapsHTTPSessionHandler(h.SessionHandler) || wrapsHTTPSessionHandler(h.Old)
+	case WrappingSessionHandler:
+		return wrapsHTTPSessionHandler(h.Unwrap())
+	}
+	return false
+}
+
+// writtenResponseWriter tell whether the response headers were written, like gin.ResponseWriter
+type writtenResponseWriter interface {
+	Written() bool
+}
+
+// requestSessionHandler bind HTTPSessionHandler to the request and the response
+type requestSessionHandler struct {
+	HTTPSessionHandler
+	w http.ResponseWriter
+	r *http.Request
+}
+
+func (h *requestSessionHandler) Read(sessionID string) (string, error) {
+	return h.ReadContext(context.Background(), sessionID)
+}
+
+func (h *requestSessionHandler) Write(sessionID, sessionData string) error {
+	return h.WriteContext(context.Background(), sessionID, sessionData)
+}
+
+func (h *requestSessionHandler) ReadContext(ctx context.Context, sessionID string) (string, error) {
+	if h.r == nil {
+		return "", ErrCookieSessionRequireRequest
+	}
+	return h.ReadRequest(h.r, sessionID)
+}
+
+func (h *requestSessionHandler) WriteContext(ctx context.Context, sessionID, sessionData string) error {
+	if h.w == nil {
+		return ErrCookieSessionRequireRequest
+	}
+	// cookies set after the headers are silently dropped
+	if written, ok := h.w.(writtenResponseWriter); ok && written.Written() {
+		return ErrHeadersCommitted
+	}
+	return h.WriteResponse(h.w, h.r, sessionID, sessionData)
+}
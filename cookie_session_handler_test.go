@@ -0,0 +1,249 @@
+package phpsessgo_test
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieSessionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler, err := phpsessgo.NewCookieSessionHandler(phpsessgo.CookieSessionHandlerConfig{
+		Key:            []byte(strings.Repeat("k", 32)),
+		ChunkSize:      100,
+		MaxSize:        1000,
+		CookieHttpOnly: true,
+	})
+	require.NoError(t, err)
+
+	sidCreator := mock.NewMockSessionIDCreator(ctrl)
+	manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{})
+	require.NoError(t, err)
+
+	// request send back the session data cookies of the previous response
+	request := func(previous *httptest.ResponseRecorder) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if previous == nil {
+			return req
+		}
+		req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "some-session-id"})
+		for _, cookie := range previous.Result().Cookies() {
+			if cookie.Name != "PHPSESSID" && cookie.MaxAge >= 0 {
+				req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+			}
+		}
+		return req
+	}
+
+	sidCreator.EXPECT().CreateSID().Return("some-session-id")
+	rr := httptest.NewRecorder()
+	session, err := manager.Start(rr, request(nil))
+	require.NoError(t, err)
+
+	session.Value["hello"] = "world"
+	require.NoError(t, manager.Save(session))
+	require.Len(t, rr.Result().Cookies(), 2)
+
+	t.Run("read back", func(t *testing.T) {
+		next := httptest.NewRecorder()
+		session, err := manager.Start(next, request(rr))
+		require.NoError(t, err)
+		require.Equal(t, "some-session-id", session.SessionID)
+		require.Equal(t, "world", session.Value["hello"])
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		req := request(rr)
+		chunked := httptest.NewRecorder()
+		session, err := manager.Start(chunked, req)
+		require.NoError(t, err)
+
+		session.Value["text"] = strings.Repeat("lorem ipsum ", 30)
+		require.NoError(t, manager.Save(session))
+
+		names := []string{}
+		for _, cookie := range chunked.Result().Cookies() {
+			require.LessOrEqual(t, len(cookie.Value), 100)
+			require.True(t, cookie.HttpOnly)
+			names = append(names, cookie.Name)
+		}
+		require.Contains(t, names, "PHPSESSDATA_4")
+
+		session, err = manager.Start(httptest.NewRecorder(), request(chunked))
+		require.NoError(t, err)
+		require.Equal(t, strings.Repeat("lorem ipsum ", 30), session.Value["text"])
+
+		// smaller data expire chunks which are no longer used
+		shrunk := httptest.NewRecorder()
+		session, err = manager.Start(shrunk, request(chunked))
+		require.NoError(t, err)
+		delete(session.Value, "text")
+		require.NoError(t, manager.Save(session))
+
+		expired := 0
+		for _, cookie := range shrunk.Result().Cookies() {
+			if cookie.MaxAge < 0 {
+				expired++
+			}
+		}
+		require.Equal(t, len(names)-1, expired)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		session, err := manager.Start(httptest.NewRecorder(), request(rr))
+		require.NoError(t, err)
+
+		random := make([]byte, 1000)
+		rand.New(rand.NewSource(1)).Read(random)
+		session.Value["random"] = string(random)
+
+		err = manager.Save(session)
+		require.True(t, errors.Is(err, phpsessgo.ErrCookieSessionTooLarge))
+	})
+
+	t.Run("data bound to session ID", func(t *testing.T) {
+		req := request(rr)
+		req.Header.Set("Cookie", strings.Replace(req.Header.Get("Cookie"), "PHPSESSID=some-session-id", "PHPSESSID=other-session-id", 1))
+
+		session, err := manager.Start(httptest.NewRecorder(), req)
+		require.NoError(t, err)
+		require.Equal(t, "other-session-id", session.SessionID)
+		require.Empty(t, session.Value)
+	})
+
+	t.Run("without request", func(t *testing.T) {
+		_, err := handler.Read("some-session-id")
+		require.Equal(t, phpsessgo.ErrCookieSessionRequireRequest, err)
+		require.Equal(t, phpsessgo.ErrCookieSessionRequireRequest, handler.Write("some-session-id", ""))
+	})
+}
+
+func TestNewCookieSessionHandler_InvalidKey(t *testing.T) {
+	_, err := phpsessgo.NewCookieSessionHandler(phpsessgo.CookieSessionHandlerConfig{Key: []byte("short")})
+	require.Error(t, err)
+}
+
+func TestCookieSessionHandler_Decorated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cookieHandler, err := phpsessgo.NewCookieSessionHandler(phpsessgo.CookieSessionHandlerConfig{Key: []byte(strings.Repeat("k", 32))})
+	require.NoError(t, err)
+	sidCreator := mock.NewMockSessionIDCreator(ctrl)
+
+	t.Run("compressed", func(t *testing.T) {
+		handler, err := phpsessgo.NewCompressingSessionHandler(cookieHandler, phpsessgo.CompressingSessionHandlerConfig{Compression: phpsessgo.CompressionGzip})
+		require.NoError(t, err)
+		manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{})
+		require.NoError(t, err)
+
+		sidCreator.EXPECT().CreateSID().Return("some-session-id")
+		rr := httptest.NewRecorder()
+		session, err := manager.Start(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.NoError(t, err)
+		session.Value["text"] = strings.Repeat("lorem ipsum ", 100)
+		require.NoError(t, manager.Save(session))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for _, cookie := range rr.Result().Cookies() {
+			req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+		session, err = manager.Start(httptest.NewRecorder(), req)
+		require.NoError(t, err)
+		require.Equal(t, strings.Repeat("lorem ipsum ", 100), session.Value["text"])
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		handler := phpsessgo.NewCachingSessionHandler(cookieHandler, phpsessgo.CachingSessionHandlerConfig{})
+		_, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{})
+		require.Equal(t, phpsessgo.ErrCachedHTTPSessionHandler, err)
+	})
+
+	t.Run("migrated", func(t *testing.T) {
+		old := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+		require.NoError(t, old.Write("some-session-id", `text|s:3:"old";`))
+		handler := phpsessgo.NewMigratingSessionHandler(cookieHandler, old, phpsessgo.MigratingSessionHandlerConfig{})
+		manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "some-session-id"})
+		rr := httptest.NewRecorder()
+		session, err := manager.Start(rr, req)
+		require.NoError(t, err)
+		require.Equal(t, "old", session.Value["text"])
+		session.Value["text"] = "new"
+		require.NoError(t, manager.Save(session))
+
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "some-session-id"})
+		for _, cookie := range rr.Result().Cookies() {
+			req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+		session, err = manager.Start(httptest.NewRecorder(), req)
+		require.NoError(t, err)
+		require.Equal(t, "new", session.Value["text"])
+		require.Equal(t, uint64(1), handler.Stats().OldHits())
+		require.Equal(t, uint64(1), handler.Stats().NewHits())
+	})
+
+	t.Run("wrapped by unknown decorator", func(t *testing.T) {
+		handler := &decoratingSessionHandler{SessionHandler: cookieHandler}
+		_, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{})
+		require.Equal(t, phpsessgo.ErrUnboundHTTPSessionHandler, err)
+	})
+
+	t.Run("saved after headers", func(t *testing.T) {
+		manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, cookieHandler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{})
+		require.NoError(t, err)
+		middleware := phpsessgo.NewMiddleware(manager, phpsessgo.MiddlewareConfig{})
+
+		var saveErr error
+		sidCreator.EXPECT().CreateSID().Return("some-session-id")
+		middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello"))
+			session := phpsessgo.SessionFromContext(r.Context())
+			session.Value["hello"] = "world"
+			saveErr = manager.Save(session)
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, phpsessgo.ErrHeadersCommitted, saveErr)
+	})
+
+	t.Run("changed after headers", func(t *testing.T) {
+		manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, cookieHandler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{})
+		require.NoError(t, err)
+		var lateErr error
+		middleware := phpsessgo.NewMiddleware(manager, phpsessgo.MiddlewareConfig{
+			LateSaveErrorHandler: func(r *http.Request, err error) {
+				lateErr = err
+			},
+		})
+
+		sidCreator.EXPECT().CreateSID().Return("some-session-id")
+		middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello"))
+			session := phpsessgo.SessionFromContext(r.Context())
+			session.Value["hello"] = "world"
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, phpsessgo.ErrHeadersCommitted, lateErr)
+	})
+}
+
+// decoratingSessionHandler is user decorator which can't bind the wrapped handler to the request
+type decoratingSessionHandler struct {
+	phpsessgo.SessionHandler
+}
+
+func (h *decoratingSessionHandler) Unwrap() phpsessgo.SessionHandler {
+	return h.SessionHandler
+}
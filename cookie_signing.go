@@ -0,0 +1,56 @@
+package phpsessgo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// signatureSeparator split the session ID and its signature, it is not valid session ID character
+const signatureSeparator = "."
+
+// SignSessionID return "<session ID>.<base64url HMAC-SHA256 of the session ID>"
+func SignSessionID(sessionID string, key []byte) string {
+	return sessionID + signatureSeparator + sessionIDSignature(sessionID, key)
+}
+
+// VerifySignedSessionID return the session ID and index of the key when the signature match any of the keys
+func VerifySignedSessionID(value string, keys [][]byte) (sessionID string, keyIndex int, ok bool) {
+	i := strings.LastIndex(value, signatureSeparator)
+	if i < 0 {
+		return "", 0, false
+	}
+
+	sessionID, signature := value[:i], value[i+1:]
+	for keyIndex, key := range keys {
+		if hmac.Equal([]byte(signature), []byte(sessionIDSignature(sessionID, key))) {
+			return sessionID, keyIndex, true
+		}
+	}
+	return "", 0, false
+}
+
+func sessionIDSignature(sessionID string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(sessionID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signsCookies report whether the session cookie is signed
+func (m *sessionManager) signsCookies() bool {
+	return len(m.config.CookieSigningKeys) > 0
+}
+
+// verifyCookie return the session ID of the cookie value and whether the cookie have to be
+// signed again with the primary key, because it was unsigned or signed with older key.
+// Unsigned value is accepted only with AcceptUnsignedCookies
+func (m *sessionManager) verifyCookie(value string) (sessionID string, resign, ok bool) {
+	if sessionID, keyIndex, ok := VerifySignedSessionID(value, m.config.CookieSigningKeys); ok {
+		return sessionID, keyIndex != 0, true
+	}
+	if m.config.AcceptUnsignedCookies && !strings.Contains(value, signatureSeparator) {
+		return value, true, true
+	}
+	return "", false, false
+}
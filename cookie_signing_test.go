@@ -0,0 +1,119 @@
+package phpsessgo_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignSessionID(t *testing.T) {
+	oldKey, newKey := []byte("old-key"), []byte("new-key")
+
+	signed := phpsessgo.SignSessionID("some-session-id", oldKey)
+	require.True(t, strings.HasPrefix(signed, "some-session-id."))
+
+	sessionID, keyIndex, ok := phpsessgo.VerifySignedSessionID(signed, [][]byte{newKey, oldKey})
+	require.True(t, ok)
+	require.Equal(t, "some-session-id", sessionID)
+	require.Equal(t, 1, keyIndex)
+
+	_, keyIndex, ok = phpsessgo.VerifySignedSessionID(signed, [][]byte{oldKey, newKey})
+	require.True(t, ok)
+	require.Equal(t, 0, keyIndex)
+
+	_, _, ok = phpsessgo.VerifySignedSessionID(signed, [][]byte{newKey})
+	require.False(t, ok)
+
+	_, _, ok = phpsessgo.VerifySignedSessionID("other-session-id"+signed[len("some-session-id"):], [][]byte{oldKey})
+	require.False(t, ok)
+
+	_, _, ok = phpsessgo.VerifySignedSessionID("some-session-id", [][]byte{oldKey})
+	require.False(t, ok)
+}
+
+func TestSessionManager_SignedCookies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := []byte("some-key")
+	sidCreator := mock.NewMockSessionIDCreator(ctrl)
+	handler := mock.NewMockSessionHandler(ctrl)
+	stats := &phpsessgo.SessionIDStats{}
+
+	newManager := func(acceptUnsigned bool) phpsessgo.SessionManager {
+		manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{
+			CookieSigningKeys:     [][]byte{key, []byte("retired-key")},
+			AcceptUnsignedCookies: acceptUnsigned,
+			IDStats:               stats,
+		})
+		require.NoError(t, err)
+		return manager
+	}
+
+	start := func(manager phpsessgo.SessionManager, cookie string) (*phpsessgo.Session, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: cookie})
+		rr := httptest.NewRecorder()
+		session, err := manager.Start(rr, req)
+		require.NoError(t, err)
+		return session, rr
+	}
+
+	t.Run("new session", func(t *testing.T) {
+		sidCreator.EXPECT().CreateSID().Return("new-session-id")
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rr := httptest.NewRecorder()
+		_, err := newManager(false).Start(rr, req)
+		require.NoError(t, err)
+		require.Equal(t, "PHPSESSID="+phpsessgo.SignSessionID("new-session-id", key), rr.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("signed cookie", func(t *testing.T) {
+		handler.EXPECT().Read("some-session-id").Return(`hello|s:5:"world";`, nil)
+
+		session, rr := start(newManager(false), phpsessgo.SignSessionID("some-session-id", key))
+		require.Equal(t, "some-session-id", session.SessionID)
+		require.Equal(t, "world", session.Value["hello"])
+		require.Empty(t, rr.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("cookie signed with retired key", func(t *testing.T) {
+		handler.EXPECT().Read("some-session-id").Return(`hello|s:5:"world";`, nil)
+
+		session, rr := start(newManager(false), phpsessgo.SignSessionID("some-session-id", []byte("retired-key")))
+		require.Equal(t, "some-session-id", session.SessionID)
+		require.Equal(t, "PHPSESSID="+phpsessgo.SignSessionID("some-session-id", key), rr.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("forged cookie", func(t *testing.T) {
+		sidCreator.EXPECT().CreateSID().Return("new-session-id")
+
+		session, _ := start(newManager(true), phpsessgo.SignSessionID("some-session-id", []byte("forged-key")))
+		require.Equal(t, "new-session-id", session.SessionID)
+		require.Equal(t, uint64(1), stats.Unverified())
+		require.Equal(t, uint64(0), stats.Rejected())
+	})
+
+	t.Run("unsigned cookie", func(t *testing.T) {
+		sidCreator.EXPECT().CreateSID().Return("new-session-id")
+		session, _ := start(newManager(false), "php-session-id")
+		require.Equal(t, "new-session-id", session.SessionID)
+
+		handler.EXPECT().Read("php-session-id").Return(`hello|s:5:"world";`, nil)
+		session, rr := start(newManager(true), "php-session-id")
+		require.Equal(t, "php-session-id", session.SessionID)
+		require.Equal(t, "PHPSESSID="+phpsessgo.SignSessionID("php-session-id", key), rr.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		require.Error(t, phpsessgo.SessionManagerConfig{AcceptUnsignedCookies: true}.Validate())
+		require.Error(t, phpsessgo.SessionManagerConfig{CookieSigningKeys: [][]byte{nil}}.Validate())
+	})
+}
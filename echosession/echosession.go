@@ -0,0 +1,81 @@
+// Package echosession provide phpsessgo middleware for echo web framework
+package echosession
+
+import (
+	"github.com/eligundry/phpsessgo"
+	"github.com/labstack/echo/v4"
+)
+
+// ContextKey of the session in echo.Context
+const ContextKey = "phpsessgo.session"
+
+// Config of the echo middleware
+type Config struct {
+	// Skipper define a function to skip the middleware
+	Skipper func(c echo.Context) bool
+	// ErrorHandler called when the session failed to save after the response is committed.
+	// Default log the error with echo logger
+	ErrorHandler func(c echo.Context, err error)
+}
+
+// Middleware start the session for every request and save it before the response is committed
+func Middleware(manager phpsessgo.SessionManager) echo.MiddlewareFunc {
+	return MiddlewareWithConfig(manager, Config{})
+}
+
+// MiddlewareWithConfig is Middleware with custom configuration
+func MiddlewareWithConfig(manager phpsessgo.SessionManager, config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = func(c echo.Context) bool { return false }
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(c echo.Context, err error) {
+			c.Logger().Error(err)
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			session, err := manager.Start(c.Response(), req)
+			if err != nil {
+				return err
+			}
+
+			c.Set(ContextKey, session)
+			c.SetRequest(req.WithContext(phpsessgo.ContextWithSession(req.Context(), session)))
+
+			saved := false
+			save := func() error {
+				if saved {
+					return nil
+				}
+				saved = true
+				return manager.SaveContext(req.Context(), session)
+			}
+
+			c.Response().Before(func() {
+				if err := save(); err != nil {
+					config.ErrorHandler(c, err)
+				}
+			})
+
+			if err = next(c); err != nil {
+				return err
+			}
+
+			// nothing has been written yet, so echo still can respond with the error
+			return save()
+		}
+	}
+}
+
+// Get return the session started by the middleware or nil when there is none
+func Get(c echo.Context) *phpsessgo.Session {
+	session, _ := c.Get(ContextKey).(*phpsessgo.Session)
+	return session
+}
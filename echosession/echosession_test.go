@@ -0,0 +1,90 @@
+package echosession_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/echosession"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	handler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	manager, err := phpsessgo.NewSessionManager(
+		phpsessgo.DefaultSessionName,
+		&phpsessgo.UUIDCreator{},
+		handler,
+		&phpsessgo.PHPSessionEncoder{},
+		phpsessgo.SessionManagerConfig{},
+	)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(echosession.Middleware(manager))
+	e.GET("/", func(c echo.Context) error {
+		session := echosession.Get(c)
+		require.Equal(t, session, phpsessgo.SessionFromContext(c.Request().Context()))
+
+		session.Value["hello"] = "world"
+		return c.String(http.StatusOK, session.SessionID)
+	})
+
+	rr := httptest.NewRecorder()
+	e.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	sessionID := rr.Body.String()
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, manager.SetCookieString(sessionID), rr.Header().Get("Set-Cookie"))
+
+	data, _ := handler.Read(sessionID)
+	require.Equal(t, `hello|s:5:"world";`, data)
+}
+
+func TestMiddleware_SaveFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := phpsessgo.NewSession()
+	manager := phpsessgo.NewMockSessionManager(ctrl)
+
+	var reported error
+	e := echo.New()
+	e.Use(echosession.MiddlewareWithConfig(manager, echosession.Config{
+		ErrorHandler: func(c echo.Context, err error) {
+			reported = err
+		},
+	}))
+	e.GET("/no-content", func(c echo.Context) error {
+		return nil
+	})
+	e.GET("/content", func(c echo.Context) error {
+		return c.String(http.StatusOK, "some-body")
+	})
+
+	t.Run("before response is committed", func(t *testing.T) {
+		manager.EXPECT().Start(gomock.Any(), gomock.Any()).Return(session, nil)
+		manager.EXPECT().SaveContext(gomock.Any(), session).Return(fmt.Errorf("some-error"))
+
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/no-content", nil))
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Nil(t, reported)
+	})
+
+	t.Run("while response is committed", func(t *testing.T) {
+		manager.EXPECT().Start(gomock.Any(), gomock.Any()).Return(session, nil)
+		manager.EXPECT().SaveContext(gomock.Any(), session).Return(fmt.Errorf("some-error"))
+
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/content", nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.EqualError(t, reported, "some-error")
+	})
+}
@@ -0,0 +1,196 @@
+package phpsessgo
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// encryptedPrefix mark the session data sealed by EncryptingSessionHandler
+const encryptedPrefix = "phpsessgo:enc:v1:"
+
+var (
+	// ErrUnknownKeyID is returned when the session data is sealed with key which is not configured
+	ErrUnknownKeyID = errors.New("phpsessgo: unknown encryption key ID")
+	// ErrDecryptionFailed is returned when the session data can't be opened
+	ErrDecryptionFailed = errors.New("phpsessgo: session decryption failed")
+)
+
+// EncryptingSessionHandlerConfig configure EncryptingSessionHandler
+type EncryptingSessionHandlerConfig struct {
+	// Keys by key ID, each key is 16, 24 or 32 bytes for AES-128, AES-192 or AES-256.
+	// Keep retired keys to read sessions sealed before the rotation
+	Keys map[string][]byte
+	// CurrentKeyID is ID of the key sealing new data
+	CurrentKeyID string
+	// AllowPlaintext read data without the envelope as is, to migrate existing store
+	AllowPlaintext bool
+}
+
+// EncryptingSessionHandler seal the session data of the wrapped handler with AES-GCM.
+// The envelope is "phpsessgo:enc:v1:<key ID>:<base64 nonce and ciphertext>",
+// the session ID is authenticated so the data can't be moved to other session
+type EncryptingSessionHandler struct {
+	SessionHandler
+	config EncryptingSessionHandlerConfig
+	aeads  map[string]cipher.AEAD
+}
+
+// NewEncryptingSessionHandler create new instance of EncryptingSessionHandler
+func NewEncryptingSessionHandler(handler SessionHandler, config EncryptingSessionHandlerConfig) (*EncryptingSessionHandler, error) {
+	if _, ok := config.Keys[config.CurrentKeyID]; !ok {
+		return nil, fmt.Errorf("phpsessgo: current encryption key %q is not configured", config.CurrentKeyID)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(config.Keys))
+	for keyID, key := range config.Keys {
+		if keyID == "" || strings.Contains(keyID, ":") {
+			return nil, fmt.Errorf("phpsessgo: invalid encryption key ID %q", keyID)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("phpsessgo: encryption key %q: %w", keyID, err)
+		}
+		if aeads[keyID], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return &EncryptingSessionHandler{
+		SessionHandler: handler,
+		config:         config,
+		aeads:          aeads,
+	}, nil
+}
+
+func (h *EncryptingSessionHandler) Read(sessionID string) (string, error) {
+	data, err := h.SessionHandler.Read(sessionID)
+	if err != nil {
+		return "", err
+	}
+	return h.open(sessionID, data)
+}
+
+func (h *EncryptingSessionHandler) Write(sessionID string, sessionData string) error {
+	sealed, err := h.seal(sessionID, sessionData)
+	if err != nil {
+		return err
+	}
+	return h.SessionHandler.Write(sessionID, sealed)
+}
+
+// ReadContext read and open the session data with context of the wrapped handler
+func (h *EncryptingSessionHandler) ReadContext(ctx context.Context, sessionID string) (string, error) {
+	data, err := NewContextSessionHandler(h.SessionHandler).ReadContext(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	return h.open(sessionID, data)
+}
+
+// WriteContext seal and write the session data with context of the wrapped handler
+func (h *EncryptingSessionHandler) WriteContext(ctx context.Context, sessionID string, sessionData string) error {
+	sealed, err := h.seal(sessionID, sessionData)
+	if err != nil {
+		return err
+	}
+	return NewContextSessionHandler(h.SessionHandler).WriteContext(ctx, sessionID, sealed)
+}
+
+// Unwrap return the wrapped handler
+func (h *EncryptingSessionHandler) Unwrap() SessionHandler {
+	return h.SessionHandler
+}
+
+func (h *EncryptingSessionHandler) bindRequest(w http.ResponseWriter, r *http.Request) (SessionHandler, bool, error) {
+	inner, bound, err := bindRequest(h.SessionHandler, w, r)
+	if !bound || err != nil {
+		return h, false, err
+	}
+	return &EncryptingSessionHandler{SessionHandler: inner, config: h.config, aeads: h.aeads}, true, nil
+}
+
+// UpdateTimestamp of the wrapped handler when it is supported
+func (h *EncryptingSessionHandler) UpdateTimestamp(sessionID string, sessionData string) error {
+	return h.UpdateTimestampContext(context.Background(), sessionID, sessionData)
+}
+
+// UpdateTimestampContext is UpdateTimestamp with context of the wrapped handler
+func (h *EncryptingSessionHandler) UpdateTimestampContext(ctx context.Context, sessionID string, sessionData string) error {
+	if _, ok := h.SessionHandler.(SessionUpdateTimestampHandler); !ok {
+		return nil
+	}
+	sealed, err := h.seal(sessionID, sessionData)
+	if err != nil {
+		return err
+	}
+	return updateTimestamp(ctx, h.SessionHandler, sessionID, sealed)
+}
+
+// Destroy the session of the wrapped handler when it is supported
+func (h *EncryptingSessionHandler) Destroy(sessionID string) error {
+	return h.DestroyContext(context.Background(), sessionID)
+}
+
+// DestroyContext is Destroy with context of the wrapped handler
+func (h *EncryptingSessionHandler) DestroyContext(ctx context.Context, sessionID string) error {
+	return destroySession(ctx, h.SessionHandler, sessionID)
+}
+
+// NeedsRotation report whether the stored data is plaintext or sealed with other than the current key
+func (h *EncryptingSessionHandler) NeedsRotation(data string) bool {
+	if !strings.HasPrefix(data, encryptedPrefix) {
+		return data != ""
+	}
+	keyID := strings.SplitN(strings.TrimPrefix(data, encryptedPrefix), ":", 2)[0]
+	return keyID != h.config.CurrentKeyID
+}
+
+func (h *EncryptingSessionHandler) seal(sessionID, sessionData string) (string, error) {
+	aead := h.aeads[h.config.CurrentKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(sessionData), []byte(sessionID))
+	return encryptedPrefix + h.config.CurrentKeyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (h *EncryptingSessionHandler) open(sessionID, data string) (string, error) {
+	if data == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(data, encryptedPrefix) {
+		if h.config.AllowPlaintext {
+			return data, nil
+		}
+		return "", ErrDecryptionFailed
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(data, encryptedPrefix), ":", 2)
+	if len(parts) != 2 {
+		return "", ErrDecryptionFailed
+	}
+	aead, ok := h.aeads[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKeyID, parts[0])
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrDecryptionFailed
+	}
+	opened, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(sessionID))
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+	return string(opened), nil
+}
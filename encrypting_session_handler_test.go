@@ -0,0 +1,126 @@
+package phpsessgo_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptingSessionHandler(t *testing.T) {
+	store := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer store.Close()
+
+	oldKey := []byte(strings.Repeat("o", 32))
+	newKey := []byte(strings.Repeat("n", 16))
+
+	handler, err := phpsessgo.NewEncryptingSessionHandler(store, phpsessgo.EncryptingSessionHandlerConfig{
+		Keys:         map[string][]byte{"2019": oldKey},
+		CurrentKeyID: "2019",
+	})
+	require.NoError(t, err)
+
+	t.Run("seal and open", func(t *testing.T) {
+		require.NoError(t, handler.Write("some-session-id", `email|s:16:"john@example.com";`))
+
+		sealed, _ := store.Read("some-session-id")
+		require.True(t, strings.HasPrefix(sealed, "phpsessgo:enc:v1:2019:"))
+		require.NotContains(t, sealed, "john@example.com")
+
+		data, err := handler.Read("some-session-id")
+		require.NoError(t, err)
+		require.Equal(t, `email|s:16:"john@example.com";`, data)
+
+		data, err = handler.ReadContext(context.Background(), "some-session-id")
+		require.NoError(t, err)
+		require.Equal(t, `email|s:16:"john@example.com";`, data)
+	})
+
+	t.Run("data bound to session ID", func(t *testing.T) {
+		sealed, _ := store.Read("some-session-id")
+		require.NoError(t, store.Write("other-session-id", sealed))
+
+		_, err := handler.Read("other-session-id")
+		require.Equal(t, phpsessgo.ErrDecryptionFailed, err)
+	})
+
+	t.Run("key rotation", func(t *testing.T) {
+		rotated, err := phpsessgo.NewEncryptingSessionHandler(store, phpsessgo.EncryptingSessionHandlerConfig{
+			Keys:         map[string][]byte{"2019": oldKey, "2020": newKey},
+			CurrentKeyID: "2020",
+		})
+		require.NoError(t, err)
+
+		sealed, _ := store.Read("some-session-id")
+		require.True(t, rotated.NeedsRotation(sealed))
+
+		data, err := rotated.Read("some-session-id")
+		require.NoError(t, err)
+		require.NoError(t, rotated.WriteContext(context.Background(), "some-session-id", data))
+
+		sealed, _ = store.Read("some-session-id")
+		require.True(t, strings.HasPrefix(sealed, "phpsessgo:enc:v1:2020:"))
+		require.False(t, rotated.NeedsRotation(sealed))
+
+		_, err = handler.Read("some-session-id")
+		require.True(t, errors.Is(err, phpsessgo.ErrUnknownKeyID))
+	})
+
+	t.Run("plaintext fallback", func(t *testing.T) {
+		require.NoError(t, store.Write("php-session-id", `hello|s:5:"world";`))
+
+		_, err := handler.Read("php-session-id")
+		require.Equal(t, phpsessgo.ErrDecryptionFailed, err)
+
+		migrating, err := phpsessgo.NewEncryptingSessionHandler(store, phpsessgo.EncryptingSessionHandlerConfig{
+			Keys:           map[string][]byte{"2019": oldKey},
+			CurrentKeyID:   "2019",
+			AllowPlaintext: true,
+		})
+		require.NoError(t, err)
+
+		data, err := migrating.Read("php-session-id")
+		require.NoError(t, err)
+		require.Equal(t, `hello|s:5:"world";`, data)
+		require.True(t, migrating.NeedsRotation(`hello|s:5:"world";`))
+
+		data, err = migrating.Read("not-exist")
+		require.NoError(t, err)
+		require.Empty(t, data)
+	})
+
+	t.Run("destroy", func(t *testing.T) {
+		require.NoError(t, handler.Destroy("php-session-id"))
+		require.NotContains(t, store.List(), "php-session-id")
+	})
+}
+
+func TestNewEncryptingSessionHandler_InvalidConfig(t *testing.T) {
+	testcases := []struct {
+		testName string
+		config   phpsessgo.EncryptingSessionHandlerConfig
+	}{
+		{"missing current key", phpsessgo.EncryptingSessionHandlerConfig{
+			Keys:         map[string][]byte{"a": make([]byte, 32)},
+			CurrentKeyID: "b",
+		}},
+		{"invalid key size", phpsessgo.EncryptingSessionHandlerConfig{
+			Keys:         map[string][]byte{"a": make([]byte, 10)},
+			CurrentKeyID: "a",
+		}},
+		{"invalid key ID", phpsessgo.EncryptingSessionHandlerConfig{
+			Keys:         map[string][]byte{"a:b": make([]byte, 32)},
+			CurrentKeyID: "a:b",
+		}},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.testName, func(t *testing.T) {
+			_, err := phpsessgo.NewEncryptingSessionHandler(nil, tt.config)
+			require.Error(t, err)
+		})
+	}
+}
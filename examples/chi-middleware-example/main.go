@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/chisession"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-redis/redis/v8"
+)
+
+func main() {
+	sessionManager, err := phpsessgo.NewRedisSessionManager(
+		redis.NewClient(&redis.Options{Addr: redisAddr()}),
+		phpsessgo.SessionManagerConfig{
+			Expiration:     time.Hour * 24,
+			CookiePath:     "/",
+			CookieHttpOnly: true,
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	router := chi.NewRouter()
+	router.Use(chisession.Middleware(sessionManager))
+
+	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		session := chisession.Get(r)
+
+		// PHP: $_SESSION["hello"] = "world";
+		session.Value["hello"] = "world"
+
+		// PHP: session_id();
+		w.Write([]byte(session.SessionID))
+	})
+
+	log.Println("listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", router))
+}
+
+func redisAddr() string {
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		return addr
+	}
+	return "localhost:6379"
+}
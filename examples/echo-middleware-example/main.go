@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/echosession"
+	"github.com/go-redis/redis/v8"
+	"github.com/labstack/echo/v4"
+)
+
+func main() {
+	sessionManager, err := phpsessgo.NewRedisSessionManager(
+		redis.NewClient(&redis.Options{Addr: redisAddr()}),
+		phpsessgo.SessionManagerConfig{
+			Expiration:     time.Hour * 24,
+			CookiePath:     "/",
+			CookieHttpOnly: true,
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	e := echo.New()
+	e.Use(echosession.Middleware(sessionManager))
+
+	e.GET("/", func(c echo.Context) error {
+		session := echosession.Get(c)
+
+		// PHP: $_SESSION["hello"] = "world";
+		session.Value["hello"] = "world"
+
+		// PHP: session_id();
+		return c.String(http.StatusOK, session.SessionID)
+	})
+
+	e.Logger.Fatal(e.Start(":8080"))
+}
+
+func redisAddr() string {
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		return addr
+	}
+	return "localhost:6379"
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/ginsession"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+func main() {
+	sessionManager, err := phpsessgo.NewRedisSessionManager(
+		redis.NewClient(&redis.Options{Addr: redisAddr()}),
+		phpsessgo.SessionManagerConfig{
+			Expiration:     time.Hour * 24,
+			CookiePath:     "/",
+			CookieHttpOnly: true,
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	router := gin.Default()
+	router.Use(ginsession.Middleware(sessionManager))
+
+	router.GET("/", func(c *gin.Context) {
+		session := ginsession.Get(c)
+
+		// PHP: $_SESSION["hello"] = "world";
+		session.Value["hello"] = "world"
+
+		// PHP: session_id();
+		c.String(http.StatusOK, session.SessionID)
+	})
+
+	log.Fatal(router.Run(":8080"))
+}
+
+func redisAddr() string {
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		return addr
+	}
+	return "localhost:6379"
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/go-redis/redis/v8"
+)
+
+func main() {
+	sessionManager, err := phpsessgo.NewRedisSessionManager(
+		redis.NewClient(&redis.Options{Addr: redisAddr()}),
+		phpsessgo.SessionManagerConfig{
+			Expiration:     time.Hour * 24,
+			CookiePath:     "/",
+			CookieHttpOnly: true,
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// PHP: session_start();
+		session, err := sessionManager.Start(w, r)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		defer sessionManager.Save(session)
+
+		// PHP: $_SESSION["hello"] = "world";
+		session.Value["hello"] = "world"
+
+		// PHP: session_id();
+		w.Write([]byte(session.SessionID))
+	})
+
+	log.Println("listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
+
+func redisAddr() string {
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		return addr
+	}
+	return "localhost:6379"
+}
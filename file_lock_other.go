@@ -0,0 +1,11 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package phpsessgo
+
+import "os"
+
+// lockFile is no-op where flock() is not available
+func lockFile(file *os.File) error {
+	return nil
+}
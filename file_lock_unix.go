@@ -0,0 +1,14 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package phpsessgo
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile take exclusive flock() like PHP files handler, it is released by closing the file
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+}
@@ -0,0 +1,64 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package phpsessgo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSessionHandler_Lock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phpsessgo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	handler, err := NewFileSessionHandler(dir)
+	require.NoError(t, err)
+	require.NoError(t, handler.Write("abc123", "a|i:1;"))
+
+	// waitLocked run fn while PHP hold the lock of the session file, fn must wait for its release
+	waitLocked := func(fn func() error) {
+		file, err := os.Open(filepath.Join(dir, "sess_abc123"))
+		require.NoError(t, err)
+		require.NoError(t, lockFile(file))
+
+		done := make(chan error, 1)
+		go func() { done <- fn() }()
+		select {
+		case <-done:
+			t.Fatal("session file accessed while locked")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		require.NoError(t, file.Close())
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("session file still locked")
+		}
+	}
+
+	waitLocked(func() error {
+		return handler.Write("abc123", "a|i:2;")
+	})
+
+	var data string
+	waitLocked(func() (err error) {
+		data, err = handler.Read("abc123")
+		return
+	})
+	require.Equal(t, "a|i:2;", data)
+
+	// the file is truncated once locked, shorter data doesn't keep the tail of the previous one
+	require.NoError(t, handler.Write("abc123", "a|N;"))
+	data, err = handler.Read("abc123")
+	require.NoError(t, err)
+	require.Equal(t, "a|N;", data)
+}
@@ -0,0 +1,246 @@
+package phpsessgo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFileSessionPrefix is prefix of the session files of PHP files handler
+const DefaultFileSessionPrefix = "sess_"
+
+// FileSessionHandler is adoption of PHP files save handler, session is stored in "<dir>/sess_<id>"
+type FileSessionHandler struct {
+	SessionHandler
+	// Dir is directory of the session files
+	Dir string
+	// Depth is number of subdirectory levels named by the session ID characters, like "N;/path"
+	Depth int
+	// Mode of new session files, default to 0600 like PHP
+	Mode os.FileMode
+}
+
+// NewFileSessionHandler create FileSessionHandler from session.save_path, "/path", "N;/path" or "N;MODE;/path"
+func NewFileSessionHandler(savePath string) (*FileSessionHandler, error) {
+	handler := &FileSessionHandler{Mode: 0600}
+
+	parts := strings.Split(savePath, ";")
+	handler.Dir = parts[len(parts)-1]
+	if handler.Dir == "" {
+		return nil, fmt.Errorf("phpsessgo: empty session save path")
+	}
+
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("phpsessgo: invalid session save path %q", savePath)
+	}
+	if len(parts) >= 2 {
+		depth, err := strconv.Atoi(parts[0])
+		if err != nil || depth < 0 {
+			return nil, fmt.Errorf("phpsessgo: invalid session save path depth %q", parts[0])
+		}
+		handler.Depth = depth
+	}
+	if len(parts) == 3 {
+		mode, err := strconv.ParseUint(parts[1], 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("phpsessgo: invalid session save path mode %q", parts[1])
+		}
+		handler.Mode = os.FileMode(mode)
+	}
+
+	return handler, nil
+}
+
+// Close the resource
+func (h *FileSessionHandler) Close() {}
+
+// Read the session file under the lock PHP files handler take, so a session being written
+// by PHP is not read half-written
+func (h *FileSessionHandler) Read(sessionID string) (string, error) {
+	path, err := h.path(sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadAll(file)
+	return string(data), err
+}
+
+// Write the session file under the lock PHP files handler take. The file is truncated
+// once locked, like PHP does, instead of when it is opened
+func (h *FileSessionHandler) Write(sessionID string, sessionData string) error {
+	path, err := h.path(sessionID)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, h.mode())
+	if err != nil {
+		return err
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Truncate(0); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.WriteAt([]byte(sessionData), 0); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Destroy remove the session file
+func (h *FileSessionHandler) Destroy(sessionID string) error {
+	path, err := h.path(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// UpdateTimestamp touch the session file like PHP files handler
+func (h *FileSessionHandler) UpdateTimestamp(sessionID string, sessionData string) error {
+	path, err := h.path(sessionID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return os.Chtimes(path, now, now)
+}
+
+// RewriteSession replace the session file content if it was not changed. The file is
+// locked like PHP files handler does and its modification time is kept for Gc
+func (h *FileSessionHandler) RewriteSession(sessionID, oldData, newData string) (bool, error) {
+	path, err := h.path(sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return false, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil || string(data) != oldData {
+		return false, err
+	}
+
+	if err := file.Truncate(0); err != nil {
+		return false, err
+	}
+	if _, err := file.WriteAt([]byte(newData), 0); err != nil {
+		return false, err
+	}
+	return true, os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// Gc remove session files not modified for maxLifetime, it return number of removed files.
+// Like PHP, only sessions directly in Dir are collected when Depth is set
+func (h *FileSessionHandler) Gc(maxLifetime time.Duration) (int, error) {
+	files, err := ioutil.ReadDir(h.Dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	deadline := time.Now().Add(-maxLifetime)
+	for _, file := range files {
+		if file.IsDir() || !strings.HasPrefix(file.Name(), DefaultFileSessionPrefix) || file.ModTime().After(deadline) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(h.Dir, file.Name())); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// WalkSessions call fn for every session file, including the subdirectories when Depth is set
+func (h *FileSessionHandler) WalkSessions(fn func(info SessionInfo) error) error {
+	return h.walkDir(h.Dir, h.Depth, fn)
+}
+
+func (h *FileSessionHandler) walkDir(dir string, depth int, fn func(info SessionInfo) error) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			if depth > 0 {
+				if err := h.walkDir(filepath.Join(dir, file.Name()), depth-1, fn); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if depth > 0 || !strings.HasPrefix(file.Name(), DefaultFileSessionPrefix) {
+			continue
+		}
+		info := SessionInfo{
+			ID:        strings.TrimPrefix(file.Name(), DefaultFileSessionPrefix),
+			LastWrite: file.ModTime(),
+		}
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// path return the session file path, the session ID is validated to stay in Dir
+func (h *FileSessionHandler) path(sessionID string) (string, error) {
+	if !ValidSessionID(sessionID) || len(sessionID) < h.Depth {
+		return "", fmt.Errorf("phpsessgo: invalid session ID %q", sessionID)
+	}
+
+	dir := h.Dir
+	for i := 0; i < h.Depth; i++ {
+		dir = filepath.Join(dir, sessionID[i:i+1])
+	}
+	return filepath.Join(dir, DefaultFileSessionPrefix+sessionID), nil
+}
+
+func (h *FileSessionHandler) mode() os.FileMode {
+	if h.Mode == 0 {
+		return 0600
+	}
+	return h.Mode
+}
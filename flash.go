@@ -0,0 +1,194 @@
+package phpsessgo
+
+import (
+	"sort"
+
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+// FlashStorage keep one-shot messages in the session value
+type FlashStorage interface {
+	// Add the message to the bucket of the flash type
+	Add(value phpencode.PhpSession, flashType string, message phptype.Value)
+	// Peek return messages of the flash type without removing them
+	Peek(value phpencode.PhpSession, flashType string) []phptype.Value
+	// Take return and remove messages of the flash type
+	Take(value phpencode.PhpSession, flashType string) []phptype.Value
+	// Age return the value to be saved at the end of the request
+	Age(value phpencode.PhpSession) phpencode.PhpSession
+}
+
+// DefaultSymfonyFlashKey is session key of Symfony FlashBag in native session storage
+const DefaultSymfonyFlashKey = "_sf2_flashes"
+
+// SymfonyFlashStorage is FlashStorage compatible with Symfony FlashBag,
+// messages are grouped by type in one session key and removed when they are read
+type SymfonyFlashStorage struct {
+	// Key of the session value, default to DefaultSymfonyFlashKey
+	Key string
+}
+
+func (s *SymfonyFlashStorage) Add(value phpencode.PhpSession, flashType string, message phptype.Value) {
+	bag, ok := value[s.key()].(phptype.Array)
+	if !ok {
+		bag = phptype.Array{}
+		value[s.key()] = bag
+	}
+	bag[flashType] = phptype.Slice(append(flashList(bag[flashType]), message))
+}
+
+func (s *SymfonyFlashStorage) Peek(value phpencode.PhpSession, flashType string) []phptype.Value {
+	bag, _ := value[s.key()].(phptype.Array)
+	return flashList(bag[flashType])
+}
+
+func (s *SymfonyFlashStorage) Take(value phpencode.PhpSession, flashType string) []phptype.Value {
+	bag, _ := value[s.key()].(phptype.Array)
+	messages := flashList(bag[flashType])
+	delete(bag, flashType)
+	return messages
+}
+
+func (s *SymfonyFlashStorage) Age(value phpencode.PhpSession) phpencode.PhpSession {
+	return value
+}
+
+func (s *SymfonyFlashStorage) key() string {
+	if s.Key == "" {
+		return DefaultSymfonyFlashKey
+	}
+	return s.Key
+}
+
+// LaravelFlashKey is session key of Laravel flash data bookkeeping
+const LaravelFlashKey = "_flash"
+
+// LaravelFlashStorage is FlashStorage compatible with Laravel Session::flash(),
+// the flash type is the session key and is listed in "_flash.new" until the end of
+// the request, then in "_flash.old" until the end of the next request.
+// The message is stored as is, so session('status') in PHP return the string,
+// and like Session::flash() adding another message of the type replace it
+type LaravelFlashStorage struct{}
+
+func (s *LaravelFlashStorage) Add(value phpencode.PhpSession, flashType string, message phptype.Value) {
+	value[flashType] = message
+
+	old, new := s.keys(value)
+	s.setKeys(value, removeFlashKey(old, flashType), appendFlashKey(new, flashType))
+}
+
+func (s *LaravelFlashStorage) Peek(value phpencode.PhpSession, flashType string) []phptype.Value {
+	old, new := s.keys(value)
+	if !containsFlashKey(old, flashType) && !containsFlashKey(new, flashType) {
+		return nil
+	}
+	return flashList(value[flashType])
+}
+
+func (s *LaravelFlashStorage) Take(value phpencode.PhpSession, flashType string) []phptype.Value {
+	messages := s.Peek(value, flashType)
+	if messages == nil {
+		return nil
+	}
+
+	delete(value, flashType)
+	old, new := s.keys(value)
+	s.setKeys(value, removeFlashKey(old, flashType), removeFlashKey(new, flashType))
+	return messages
+}
+
+// Age is adoption of Illuminate\Session\Store::ageFlashData(), the session value is left untouched
+// so saving more than once in the request doesn't age the flash data twice
+func (s *LaravelFlashStorage) Age(value phpencode.PhpSession) phpencode.PhpSession {
+	if _, ok := value[LaravelFlashKey]; !ok {
+		return value
+	}
+
+	old, new := s.keys(value)
+	aged := make(phpencode.PhpSession, len(value))
+	for k, v := range value {
+		aged[k] = v
+	}
+	for _, key := range old {
+		delete(aged, key)
+	}
+	s.setKeys(aged, new, nil)
+	return aged
+}
+
+func (s *LaravelFlashStorage) keys(value phpencode.PhpSession) (old, new []string) {
+	flash, _ := value[LaravelFlashKey].(phptype.Array)
+	for _, key := range flashList(flash["old"]) {
+		if k, ok := key.(string); ok {
+			old = append(old, k)
+		}
+	}
+	for _, key := range flashList(flash["new"]) {
+		if k, ok := key.(string); ok {
+			new = append(new, k)
+		}
+	}
+	return
+}
+
+func (s *LaravelFlashStorage) setKeys(value phpencode.PhpSession, old, new []string) {
+	oldList, newList := phptype.Slice{}, phptype.Slice{}
+	for _, key := range old {
+		oldList = append(oldList, key)
+	}
+	for _, key := range new {
+		newList = append(newList, key)
+	}
+	value[LaravelFlashKey] = phptype.Array{"old": oldList, "new": newList}
+}
+
+func containsFlashKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func appendFlashKey(keys []string, key string) []string {
+	if containsFlashKey(keys, key) {
+		return keys
+	}
+	return append(keys, key)
+}
+
+func removeFlashKey(keys []string, key string) (result []string) {
+	for _, k := range keys {
+		if k != key {
+			result = append(result, k)
+		}
+	}
+	return
+}
+
+// flashList return the PHP list as slice, decoded list is Array with integer keys
+// and value other than list is single message
+func flashList(v phptype.Value) []phptype.Value {
+	switch list := v.(type) {
+	case nil:
+		return nil
+	case phptype.Slice:
+		return append([]phptype.Value(nil), list...)
+	case phptype.Array:
+		indexes := make([]int, 0, len(list))
+		for k := range list {
+			if i, ok := k.(int); ok {
+				indexes = append(indexes, i)
+			}
+		}
+		sort.Ints(indexes)
+		values := make([]phptype.Value, 0, len(indexes))
+		for _, i := range indexes {
+			values = append(values, list[i])
+		}
+		return values
+	}
+	return []phptype.Value{v}
+}
@@ -0,0 +1,87 @@
+package phpsessgo_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/phptype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_SymfonyFlashes(t *testing.T) {
+	encoder := &phpsessgo.PHPSessionEncoder{}
+
+	session := phpsessgo.NewSession()
+	value, err := encoder.Decode(`_sf2_flashes|a:1:{s:6:"notice";a:1:{i:0;s:5:"Hello";}}`)
+	require.NoError(t, err)
+	session.Value = value
+
+	session.AddFlash("notice", "World")
+	session.AddFlash("error", "Oops")
+
+	require.Equal(t, []phptype.Value{"Hello", "World"}, session.PeekFlashes("notice"))
+	require.Equal(t, []phptype.Value{"Hello", "World"}, session.Flashes("notice"))
+	require.Empty(t, session.Flashes("notice"))
+
+	encoded, err := encoder.Encode(session.Value)
+	require.NoError(t, err)
+	require.Equal(t, `_sf2_flashes|a:1:{s:5:"error";a:1:{i:0;s:4:"Oops";}}`, encoded)
+}
+
+func TestSession_LaravelFlashes(t *testing.T) {
+	handler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	encoder := &phpsessgo.PHPSessionEncoder{}
+	manager, err := phpsessgo.NewSessionManager("laravel_session", nil, handler, encoder, phpsessgo.SessionManagerConfig{
+		FlashStorage: &phpsessgo.LaravelFlashStorage{},
+	})
+	require.NoError(t, err)
+
+	start := func() *phpsessgo.Session {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "laravel_session", Value: "some-session-id"})
+		session, err := manager.Start(httptest.NewRecorder(), req)
+		require.NoError(t, err)
+		return session
+	}
+
+	// flashed by PHP in the previous request and already aged
+	require.NoError(t, handler.Write("some-session-id", `status|s:5:"Saved";_flash|a:2:{s:3:"old";a:1:{i:0;s:6:"status";}s:3:"new";a:0:{}}`))
+
+	session := start()
+	require.Equal(t, []phptype.Value{"Saved"}, session.PeekFlashes("status"))
+	session.AddFlash("warning", "Low stock")
+	require.Equal(t, []phptype.Value{"Low stock"}, session.PeekFlashes("warning"))
+	require.NoError(t, manager.Save(session))
+	require.NoError(t, manager.Save(session))
+
+	data, _ := handler.Read("some-session-id")
+	saved, err := encoder.Decode(data)
+	require.NoError(t, err)
+	require.NotContains(t, saved, "status")
+	require.Equal(t, "Low stock", saved["warning"])
+	require.Equal(t, phptype.Array{
+		"old": phptype.Array{0: "warning"},
+		"new": phptype.Array{},
+	}, saved["_flash"])
+
+	session = start()
+	require.Empty(t, session.PeekFlashes("status"))
+	require.Equal(t, []phptype.Value{"Low stock"}, session.Flashes("warning"))
+	require.Empty(t, session.PeekFlashes("warning"))
+	require.NoError(t, manager.Save(session))
+
+	session = start()
+	require.Empty(t, session.PeekFlashes("warning"))
+	require.NotContains(t, session.Value, "warning")
+
+	t.Run("message replaced like Session::flash()", func(t *testing.T) {
+		session.AddFlash("error", "first")
+		session.AddFlash("error", "second")
+		require.Equal(t, []phptype.Value{"second"}, session.PeekFlashes("error"))
+		require.Equal(t, "second", session.Value["error"])
+	})
+}
@@ -0,0 +1,138 @@
+// Package ginsession provide phpsessgo middleware for gin web framework
+package ginsession
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKey of the session in gin.Context
+const ContextKey = "phpsessgo.session"
+
+// Config of the gin middleware
+type Config struct {
+	// ErrorHandler called when the session failed to start or to save.
+	// Default abort with 500 Internal Server Error when the response is not written yet
+	ErrorHandler func(c *gin.Context, err error)
+}
+
+// Middleware start the session for every request and save it before the response is written
+func Middleware(manager phpsessgo.SessionManager) gin.HandlerFunc {
+	return MiddlewareWithConfig(manager, Config{})
+}
+
+// MiddlewareWithConfig is Middleware with custom configuration
+func MiddlewareWithConfig(manager phpsessgo.SessionManager, config Config) gin.HandlerFunc {
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = defaultErrorHandler
+	}
+
+	return func(c *gin.Context) {
+		// the session write to the response through the guarded writer too
+		writer := &responseWriter{ResponseWriter: c.Writer, context: c, manager: manager, errorHandler: config.ErrorHandler}
+		session, err := manager.Start(writer, c.Request)
+		if err != nil {
+			config.ErrorHandler(c, err)
+			c.Abort()
+			return
+		}
+		writer.session = session
+
+		c.Set(ContextKey, session)
+		c.Request = c.Request.WithContext(phpsessgo.ContextWithSession(c.Request.Context(), session))
+		c.Writer = writer
+
+		c.Next()
+		writer.save()
+	}
+}
+
+// Get return the session started by the middleware or nil when there is none
+func Get(c *gin.Context) *phpsessgo.Session {
+	value, _ := c.Get(ContextKey)
+	session, _ := value.(*phpsessgo.Session)
+	return session
+}
+
+func defaultErrorHandler(c *gin.Context, err error) {
+	c.Error(err)
+	if !c.Writer.Written() {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}
+}
+
+// responseWriter save the session before the first header write
+type responseWriter struct {
+	gin.ResponseWriter
+	context      *gin.Context
+	manager      phpsessgo.SessionManager
+	session      *phpsessgo.Session
+	errorHandler func(c *gin.Context, err error)
+	saved        bool
+	aborted      bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.save()
+	if w.aborted {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	w.save()
+	if w.aborted {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.save()
+	if w.aborted {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	w.save()
+	if w.aborted {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseWriter) Flush() {
+	w.save()
+	if w.aborted {
+		return
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.save()
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *responseWriter) save() {
+	if w.saved {
+		return
+	}
+	w.saved = true
+
+	if err := w.manager.SaveContext(w.context.Request.Context(), w.session); err != nil {
+		// the error handler write through the original writer
+		w.context.Writer = w.ResponseWriter
+		w.errorHandler(w.context, err)
+		w.context.Writer = w
+		// the error handler has taken over the response
+		w.aborted = w.ResponseWriter.Written()
+	}
+}
@@ -0,0 +1,128 @@
+package ginsession_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/ginsession"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	manager, err := phpsessgo.NewSessionManager(
+		phpsessgo.DefaultSessionName,
+		&phpsessgo.UUIDCreator{},
+		handler,
+		&phpsessgo.PHPSessionEncoder{},
+		phpsessgo.SessionManagerConfig{},
+	)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(ginsession.Middleware(manager))
+	router.GET("/", func(c *gin.Context) {
+		session := ginsession.Get(c)
+		require.Equal(t, session, phpsessgo.SessionFromContext(c.Request.Context()))
+
+		session.Value["hello"] = "world"
+		c.String(http.StatusOK, session.SessionID)
+
+		// saved before the first write
+		data, _ := handler.Read(session.SessionID)
+		require.Equal(t, `hello|s:5:"world";`, data)
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, manager.SetCookieString(rr.Body.String()), rr.Header().Get("Set-Cookie"))
+}
+
+func TestMiddleware_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager := phpsessgo.NewMockSessionManager(ctrl)
+
+	router := gin.New()
+	router.Use(ginsession.Middleware(manager))
+	router.GET("/", func(c *gin.Context) {})
+	router.GET("/write", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+
+	t.Run("start failed", func(t *testing.T) {
+		manager.EXPECT().Start(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some-error"))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("save failed", func(t *testing.T) {
+		session := phpsessgo.NewSession()
+		manager.EXPECT().Start(gomock.Any(), gomock.Any()).Return(session, nil)
+		manager.EXPECT().SaveContext(gomock.Any(), session).Return(fmt.Errorf("some-error"))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+	t.Run("save failed on write", func(t *testing.T) {
+		session := phpsessgo.NewSession()
+		manager.EXPECT().Start(gomock.Any(), gomock.Any()).Return(session, nil)
+		manager.EXPECT().SaveContext(gomock.Any(), session).Return(fmt.Errorf("some-error"))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/write", nil))
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Empty(t, rr.Body.String())
+	})
+}
+
+func TestMiddleware_HTTPSessionHandler(t *testing.T) {
+	handler := &recordingHTTPSessionHandler{MemorySessionHandler: phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})}
+	manager, err := phpsessgo.NewSessionManager(phpsessgo.DefaultSessionName, &phpsessgo.UUIDCreator{}, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{})
+	require.NoError(t, err)
+
+	var writer http.ResponseWriter
+	router := gin.New()
+	router.Use(ginsession.Middleware(manager))
+	router.GET("/", func(c *gin.Context) {
+		writer = c.Writer
+		c.String(http.StatusOK, "hello")
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// the session is written through the guarded writer, not the one it wraps
+	require.NotNil(t, handler.response)
+	require.True(t, writer == handler.response)
+}
+
+// recordingHTTPSessionHandler record the response the session is written to
+type recordingHTTPSessionHandler struct {
+	*phpsessgo.MemorySessionHandler
+	response http.ResponseWriter
+}
+
+func (h *recordingHTTPSessionHandler) ReadRequest(r *http.Request, sessionID string) (string, error) {
+	return "", nil
+}
+
+func (h *recordingHTTPSessionHandler) WriteResponse(w http.ResponseWriter, r *http.Request, sessionID, sessionData string) error {
+	h.response = w
+	return nil
+}
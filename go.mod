@@ -5,10 +5,18 @@ go 1.15
 require (
 	github.com/alicebob/gopher-json v0.0.0-20180125190556-5a6b3ba71ee6 // indirect
 	github.com/alicebob/miniredis v2.5.0+incompatible
-	github.com/go-redis/redis v6.15.2+incompatible
+	github.com/cespare/xxhash/v2 v2.1.2 // indirect
+	github.com/gin-gonic/gin v1.7.4
+	github.com/go-chi/chi/v5 v5.0.7
+	github.com/go-redis/redis/v8 v8.11.3
+	github.com/go-sql-driver/mysql v1.6.0
 	github.com/golang/mock v1.2.0
 	github.com/gomodule/redigo v2.0.0+incompatible // indirect
+	github.com/google/go-cmp v0.5.8 // indirect
 	github.com/google/uuid v1.1.1
+	github.com/labstack/echo/v4 v4.6.1
+	github.com/lib/pq v1.10.3
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/onsi/gomega v1.16.0 // indirect
 	github.com/stretchr/testify v1.5.1
 	github.com/yuin/gopher-lua v0.0.0-20190206043414-8bfc7677f583 // indirect
@@ -0,0 +1,268 @@
+package phpsessgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+// JSONClassKey hold the class name of object encoded by JSONSessionEncoder
+const JSONClassKey = "__class"
+
+// JSONSessionEncoder store the session as JSON object, readable by non PHP services.
+// Arrays become JSON objects or lists, objects become JSON objects with JSONClassKey,
+// so integer-like string keys and object visibility don't survive the round trip
+// unless Tagged is set. EncodeOrdered and DecodeOrdered keep the order of variables,
+// the order of arrays only when Tagged
+type JSONSessionEncoder struct {
+	SessionEncoder
+	// Indent the output, useful for humans
+	Indent string
+	// Tagged encode the values in the lossless representation of phptype.MarshalJSON
+	Tagged bool
+}
+
+func (e *JSONSessionEncoder) Encode(session phpencode.PhpSession) (string, error) {
+	values := make(map[string]interface{}, len(session))
+	for key, value := range session {
+		if !e.Tagged {
+			values[key] = jsonValue(value)
+			continue
+		}
+		data, err := phptype.MarshalJSON(value)
+		if err != nil {
+			return "", fmt.Errorf("phpsessgo: failed to encode JSON session: %v", err)
+		}
+		values[key] = json.RawMessage(data)
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if e.Indent != "" {
+		data, err = json.MarshalIndent(values, "", e.Indent)
+	} else {
+		data, err = json.Marshal(values)
+	}
+	if err != nil {
+		return "", fmt.Errorf("phpsessgo: failed to encode JSON session: %v", err)
+	}
+	return string(data), nil
+}
+
+func (e *JSONSessionEncoder) Decode(raw string) (phpencode.PhpSession, error) {
+	session := make(phpencode.PhpSession)
+	if raw == "" {
+		return session, nil
+	}
+
+	if e.Tagged {
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(raw), &values); err != nil {
+			return session, fmt.Errorf("phpsessgo: failed to decode JSON session: %v", err)
+		}
+		for key, data := range values {
+			value, err := phptype.UnmarshalJSON(data)
+			if err != nil {
+				return session, fmt.Errorf("phpsessgo: failed to decode JSON session: %v", err)
+			}
+			session[key] = value
+		}
+		return session, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	decoder.UseNumber()
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil {
+		return session, fmt.Errorf("phpsessgo: failed to decode JSON session: %v", err)
+	}
+
+	for key, value := range values {
+		session[key] = phpValue(value)
+	}
+	return session, nil
+}
+
+func (e *JSONSessionEncoder) EncodeOrdered(session *phptype.OrderedArray) (string, error) {
+	variables, order, err := sessionVariables(session)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range order {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		data, err := e.marshalValue(variables[name])
+		if err != nil {
+			return "", fmt.Errorf("phpsessgo: failed to encode JSON session: %v", err)
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+
+	if e.Indent == "" {
+		return buf.String(), nil
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", e.Indent); err != nil {
+		return "", fmt.Errorf("phpsessgo: failed to encode JSON session: %v", err)
+	}
+	return indented.String(), nil
+}
+
+func (e *JSONSessionEncoder) DecodeOrdered(raw string) (*phptype.OrderedArray, error) {
+	session := phptype.NewOrderedArray()
+	if raw == "" {
+		return session, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return session, fmt.Errorf("phpsessgo: failed to decode JSON session: not JSON object")
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return session, fmt.Errorf("phpsessgo: failed to decode JSON session: %v", err)
+		}
+		var data json.RawMessage
+		if err := decoder.Decode(&data); err != nil {
+			return session, fmt.Errorf("phpsessgo: failed to decode JSON session: %v", err)
+		}
+		value, err := e.unmarshalValue(data)
+		if err != nil {
+			return session, fmt.Errorf("phpsessgo: failed to decode JSON session: %v", err)
+		}
+		session.Set(token, value)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return session, fmt.Errorf("phpsessgo: failed to decode JSON session: %v", err)
+	}
+	return session, nil
+}
+
+func (e *JSONSessionEncoder) marshalValue(v phptype.Value) ([]byte, error) {
+	if e.Tagged {
+		return phptype.MarshalJSON(v)
+	}
+	return json.Marshal(jsonValue(v))
+}
+
+// unmarshalValue decode the value of ordered session, arrays keep their order only when Tagged
+func (e *JSONSessionEncoder) unmarshalValue(data []byte) (phptype.Value, error) {
+	if e.Tagged {
+		return phptype.UnmarshalOrderedJSON(data)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return phpValue(value), nil
+}
+
+func jsonValue(v phptype.Value) interface{} {
+	switch t := v.(type) {
+	case phptype.Array:
+		return jsonArray(t)
+	case map[phptype.Value]phptype.Value:
+		return jsonArray(t)
+	case *phptype.OrderedArray:
+		return jsonArray(t.Array)
+	case phptype.Slice:
+		result := make([]interface{}, len(t))
+		for i, value := range t {
+			result[i] = jsonValue(value)
+		}
+		return result
+	case *phptype.Object:
+		result := jsonObject(t.Members)
+		result[JSONClassKey] = t.ClassName
+		return result
+	case *phptype.ObjectSerialized:
+		if t.Value != nil {
+			return map[string]interface{}{JSONClassKey: t.ClassName, "data": jsonValue(t.Value)}
+		}
+		return map[string]interface{}{JSONClassKey: t.ClassName, "data": t.Data}
+	case *phptype.PhpSplArray:
+		return jsonValue(t.Array)
+	default:
+		return v
+	}
+}
+
+// jsonArray encode PHP list as JSON list, like json_encode()
+func jsonArray(array map[phptype.Value]phptype.Value) interface{} {
+	list := make([]interface{}, len(array))
+	for i := range list {
+		value, ok := array[i]
+		if !ok {
+			return jsonObject(array)
+		}
+		list[i] = jsonValue(value)
+	}
+	return list
+}
+
+func jsonObject(array map[phptype.Value]phptype.Value) map[string]interface{} {
+	result := make(map[string]interface{}, len(array))
+	for key, value := range array {
+		result[fmt.Sprint(key)] = jsonValue(value)
+	}
+	return result
+}
+
+// phpValue convert decoded JSON like json_decode($json, true), integer-like keys become int
+func phpValue(v interface{}) phptype.Value {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := strconv.Atoi(t.String()); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case []interface{}:
+		result := make(phptype.Array, len(t))
+		for i, value := range t {
+			result[i] = phpValue(value)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(phptype.Array, len(t))
+		for key, value := range t {
+			result[phpKey(key)] = phpValue(value)
+		}
+		if className, ok := t[JSONClassKey].(string); ok {
+			delete(result, JSONClassKey)
+			obj := &phptype.Object{ClassName: className, Members: result}
+			obj.Order, _ = obj.Keys()
+			return obj
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// phpKey cast decimal string key to int the same way PHP array does
+func phpKey(key string) phptype.Value {
+	i, err := strconv.Atoi(key)
+	if err != nil || strconv.Itoa(i) != key {
+		return key
+	}
+	return i
+}
@@ -0,0 +1,300 @@
+package phpsessgo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phpserialize"
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+// Ciphers supported by LaravelEncrypter
+const (
+	LaravelCipherAES256CBC = "AES-256-CBC"
+	LaravelCipherAES256GCM = "AES-256-GCM"
+)
+
+var (
+	// ErrInvalidLaravelPayload is returned when the payload is not Laravel encrypter envelope
+	ErrInvalidLaravelPayload = errors.New("phpsessgo: invalid laravel payload")
+	// ErrInvalidLaravelMAC is returned when the payload MAC or authentication tag doesn't match
+	ErrInvalidLaravelMAC = errors.New("phpsessgo: invalid laravel mac")
+)
+
+// LaravelEncrypter is adoption of Illuminate\Encryption\Encrypter without the value serialization
+type LaravelEncrypter struct {
+	key    []byte
+	cipher string
+}
+
+// NewLaravelEncrypter create encrypter from APP_KEY, with or without the "base64:" prefix
+func NewLaravelEncrypter(appKey, cipherName string) (*LaravelEncrypter, error) {
+	key := []byte(appKey)
+	if strings.HasPrefix(appKey, "base64:") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(appKey, "base64:"))
+		if err != nil {
+			return nil, fmt.Errorf("phpsessgo: invalid laravel app key: %w", err)
+		}
+		key = decoded
+	}
+
+	cipherName = strings.ToUpper(cipherName)
+	if cipherName != LaravelCipherAES256CBC && cipherName != LaravelCipherAES256GCM {
+		return nil, fmt.Errorf("phpsessgo: unsupported laravel cipher %q", cipherName)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("phpsessgo: laravel app key must be 32 bytes for %s", cipherName)
+	}
+
+	return &LaravelEncrypter{key: key, cipher: cipherName}, nil
+}
+
+type laravelPayload struct {
+	IV    string `json:"iv"`
+	Value string `json:"value"`
+	MAC   string `json:"mac"`
+	Tag   string `json:"tag"`
+}
+
+// Encrypt the value into base64 JSON envelope {iv, value, mac, tag}
+func (e *LaravelEncrypter) Encrypt(value []byte) (string, error) {
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return "", err
+	}
+
+	var payload laravelPayload
+	if e.cipher == LaravelCipherAES256GCM {
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return "", err
+		}
+		iv := make([]byte, gcm.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+			return "", err
+		}
+		sealed := gcm.Seal(nil, iv, value, nil)
+		tagStart := len(sealed) - gcm.Overhead()
+
+		payload.IV = base64.StdEncoding.EncodeToString(iv)
+		payload.Value = base64.StdEncoding.EncodeToString(sealed[:tagStart])
+		payload.Tag = base64.StdEncoding.EncodeToString(sealed[tagStart:])
+	} else {
+		iv := make([]byte, aes.BlockSize)
+		if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+			return "", err
+		}
+		padding := aes.BlockSize - len(value)%aes.BlockSize
+		padded := append(append([]byte(nil), value...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+		encrypted := make([]byte, len(padded))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+
+		payload.IV = base64.StdEncoding.EncodeToString(iv)
+		payload.Value = base64.StdEncoding.EncodeToString(encrypted)
+		payload.MAC = e.mac(payload.IV, payload.Value)
+	}
+
+	var buffer strings.Builder
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(payload); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(strings.TrimSuffix(buffer.String(), "\n"))), nil
+}
+
+// Decrypt the base64 JSON envelope
+func (e *LaravelEncrypter) Decrypt(encrypted string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, ErrInvalidLaravelPayload
+	}
+	var payload laravelPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, ErrInvalidLaravelPayload
+	}
+
+	iv, err := base64.StdEncoding.DecodeString(payload.IV)
+	if err != nil {
+		return nil, ErrInvalidLaravelPayload
+	}
+	value, err := base64.StdEncoding.DecodeString(payload.Value)
+	if err != nil {
+		return nil, ErrInvalidLaravelPayload
+	}
+
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.cipher == LaravelCipherAES256GCM {
+		tag, err := base64.StdEncoding.DecodeString(payload.Tag)
+		if err != nil || len(tag) != 16 {
+			return nil, ErrInvalidLaravelPayload
+		}
+		gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+		if err != nil {
+			return nil, ErrInvalidLaravelPayload
+		}
+		decrypted, err := gcm.Open(nil, iv, append(value, tag...), nil)
+		if err != nil {
+			return nil, ErrInvalidLaravelMAC
+		}
+		return decrypted, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(e.mac(payload.IV, payload.Value)), []byte(payload.MAC)) != 1 {
+		return nil, ErrInvalidLaravelMAC
+	}
+	if len(iv) != aes.BlockSize || len(value) == 0 || len(value)%aes.BlockSize != 0 {
+		return nil, ErrInvalidLaravelPayload
+	}
+
+	decrypted := make([]byte, len(value))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, value)
+	padding := int(decrypted[len(decrypted)-1])
+	if padding == 0 || padding > aes.BlockSize ||
+		!bytes.Equal(decrypted[len(decrypted)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, ErrInvalidLaravelPayload
+	}
+	return decrypted[:len(decrypted)-padding], nil
+}
+
+// mac is adoption of Encrypter::hash(), HMAC-SHA256 of the base64 IV and value
+func (e *LaravelEncrypter) mac(iv, value string) string {
+	h := hmac.New(sha256.New, e.key)
+	h.Write([]byte(iv + value))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// EncryptCookie encrypt the cookie value like EncryptCookies middleware,
+// the result is URL-encoded and ready for Set-Cookie header
+func (e *LaravelEncrypter) EncryptCookie(name, value string) (string, error) {
+	encrypted, err := e.Encrypt([]byte(e.cookiePrefix(name) + value))
+	if err != nil {
+		return "", err
+	}
+	return url.QueryEscape(encrypted), nil
+}
+
+// DecryptCookie decrypt the cookie value set by EncryptCookies middleware
+// and verify the cookie name prefix of CookieValuePrefix
+func (e *LaravelEncrypter) DecryptCookie(name, value string) (string, error) {
+	if unescaped, err := url.QueryUnescape(value); err == nil {
+		value = unescaped
+	}
+	decrypted, err := e.Decrypt(value)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := e.cookiePrefix(name)
+	if !strings.HasPrefix(string(decrypted), prefix) {
+		return "", ErrInvalidLaravelMAC
+	}
+	return strings.TrimPrefix(string(decrypted), prefix), nil
+}
+
+// cookiePrefix is adoption of CookieValuePrefix::create()
+func (e *LaravelEncrypter) cookiePrefix(name string) string {
+	h := hmac.New(sha1.New, e.key)
+	h.Write([]byte(name + "v2"))
+	return hex.EncodeToString(h.Sum(nil)) + "|"
+}
+
+// LaravelSessionEncoder is SessionEncoder for Laravel session payload, serialize() of the attributes.
+// With Encrypter the payload is encrypted like EncryptedStore, which serialize the serialized attributes again
+type LaravelSessionEncoder struct {
+	Encrypter *LaravelEncrypter
+}
+
+func (e *LaravelSessionEncoder) Encode(session phpencode.PhpSession) (string, error) {
+	attributes := make(phptype.Array, len(session))
+	for k, v := range session {
+		attributes[k] = v
+	}
+	serialized, err := phpserialize.Serialize(attributes)
+	if err != nil {
+		return "", err
+	}
+	if e.Encrypter == nil {
+		return serialized, nil
+	}
+
+	serialized, err = phpserialize.Serialize(serialized)
+	if err != nil {
+		return "", err
+	}
+	return e.Encrypter.Encrypt([]byte(serialized))
+}
+
+func (e *LaravelSessionEncoder) Decode(raw string) (phpencode.PhpSession, error) {
+	session := make(phpencode.PhpSession)
+	if raw == "" {
+		return session, nil
+	}
+
+	if e.Encrypter != nil {
+		decrypted, err := e.Encrypter.Decrypt(raw)
+		if err != nil {
+			return nil, err
+		}
+		value, err := phpserialize.UnSerialize(string(decrypted))
+		if err != nil {
+			return nil, err
+		}
+		serialized, ok := value.(string)
+		if !ok {
+			return nil, ErrInvalidLaravelPayload
+		}
+		raw = serialized
+	}
+
+	value, err := phpserialize.UnSerialize(raw)
+	if err != nil {
+		return nil, err
+	}
+	attributes, ok := value.(phptype.Array)
+	if !ok {
+		return nil, fmt.Errorf("phpsessgo: laravel session is %T, not array", value)
+	}
+	for k, v := range attributes {
+		session[fmt.Sprint(k)] = v
+	}
+	return session, nil
+}
+
+// LaravelCookieIDExtractor find session ID in the cookie encrypted by Laravel EncryptCookies middleware.
+// The cookie is owned by Laravel, so the session manager doesn't send it
+type LaravelCookieIDExtractor struct {
+	Encrypter *LaravelEncrypter
+}
+
+func (e *LaravelCookieIDExtractor) Extract(r *http.Request, sessionName string) (values []string) {
+	for _, cookie := range r.Cookies() {
+		if cookie.Name != sessionName {
+			continue
+		}
+		if sessionID, err := e.Encrypter.DecryptCookie(sessionName, cookie.Value); err == nil {
+			values = append(values, sessionID)
+		}
+	}
+	return
+}
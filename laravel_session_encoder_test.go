@@ -0,0 +1,137 @@
+package phpsessgo_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phptype"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtures are encrypted with OpenSSL and fixed IVs the same way as Laravel Encrypter calls
+// openssl_encrypt(), `make laravel-fixtures` print equivalent ones with Laravel itself
+const (
+	laravelAppKey = "base64:bQZ6WaQAqoOpvCwPBoTZsMehL3JoVwNbRL/0x/HhDrA="
+
+	laravelEncryptedSession = "eyJpdiI6IkFBRUNBd1FGQmdjSUNRb0xEQTBPRHc9PSIsInZhbHVlIjoiME1oSG1hdmpoKzI4K2YvSGUrbmZiUXhqUkVINlZ0TlB3NnBEcFJEYVNmME5RYmN5U1hybE1GQzFUWDdjdW1McGhvMC93UitKUGx3aWZlVWU0RWI5VVdSMEtEYnlnTWpPSGtOV21YWERPT1ZwM2tURXJkMTlGMXJMQkVDa1JaYXZuRFMwZGJYYlJRYjZWS3IrcHRtbnNRPT0iLCJtYWMiOiJmYzZiY2Y3M2FiNjYwYzZiZWFjOGFkMTExMTA4ZTg5NzZlNjBmMTBkY2FmYTJlNGI2OTZmMWRkYWFiMWJhZWJlIiwidGFnIjoiIn0="
+
+	laravelEncryptedSessionGCM = "eyJpdiI6IkFBRUNBd1FGQmdjSUNRb0wiLCJ2YWx1ZSI6ImZKTWs0Tm5kSTVNUVRMaThiMFNPaHNOaUZLd1ZGQitBNk9qQ2JXaEs3ZDJrTnphMGlvekVlcElGdGhCVFJIblRjNCtjU1NqWEhiY0phK1NNZVRoTUJwSi9zSmpjanpKbUF6TDVHLzlWZGZQK2Fway9IY0N1bkRpMEtnOGxzTTN0Y28yYzhnPT0iLCJtYWMiOiIiLCJ0YWciOiJjKzhSQmVyZ2dQb1BNeWF5dzBud2ZnPT0ifQ=="
+
+	laravelEncryptedCookie = "eyJpdiI6IkR3NE5EQXNLQ1FnSEJnVUVBd0lCQUE9PSIsInZhbHVlIjoiVTBBRy9GbzdWeDlXV1Z4SFp5cy9lR2E0S01RcUNCMHFDRElkZnB4YjQ2UUc4Yk5TLzFoTVNCNDdCT3NSS2hLSWs2M3NJKzZnMFpBeVVQbTRlUmJGbi93cWNER05EM1o2MjdmT0tsY1dDRExLbHAxZ0RZYXFyeXBtNERFK2oyZzUiLCJtYWMiOiJjMWI5YjUyNTljNDQxNWQ2MjkzMzM2NzllNWVhNmQyZWMyNTRhN2Q3Y2Y1ZGI4NWIzNTQzNmQ5NDM2N2Y4NDAwIiwidGFnIjoiIn0%3D"
+	laravelSessionID       = "Qf1vJ6pZKc2tBkXe6f9mYwqjH3sVd8Ru0nLiTgOa"
+)
+
+func TestNewLaravelEncrypter(t *testing.T) {
+	_, err := phpsessgo.NewLaravelEncrypter(laravelAppKey, "aes-256-cbc")
+	require.NoError(t, err)
+
+	_, err = phpsessgo.NewLaravelEncrypter(strings.Repeat("k", 32), phpsessgo.LaravelCipherAES256GCM)
+	require.NoError(t, err)
+
+	_, err = phpsessgo.NewLaravelEncrypter(laravelAppKey, "AES-128-CBC")
+	require.Error(t, err)
+
+	_, err = phpsessgo.NewLaravelEncrypter("short", phpsessgo.LaravelCipherAES256CBC)
+	require.Error(t, err)
+}
+
+func TestLaravelSessionEncoder(t *testing.T) {
+	encrypter, err := phpsessgo.NewLaravelEncrypter(laravelAppKey, phpsessgo.LaravelCipherAES256CBC)
+	require.NoError(t, err)
+
+	expected := phpencode.PhpSession{
+		"_token":    "abcdefgh",
+		"_previous": phptype.Array{"url": "http://example.com"},
+	}
+
+	t.Run("decode laravel payload", func(t *testing.T) {
+		encoder := &phpsessgo.LaravelSessionEncoder{Encrypter: encrypter}
+		session, err := encoder.Decode(laravelEncryptedSession)
+		require.NoError(t, err)
+		require.Equal(t, expected, session)
+	})
+
+	t.Run("decode laravel AES-256-GCM payload", func(t *testing.T) {
+		encrypter, err := phpsessgo.NewLaravelEncrypter(laravelAppKey, phpsessgo.LaravelCipherAES256GCM)
+		require.NoError(t, err)
+		encoder := &phpsessgo.LaravelSessionEncoder{Encrypter: encrypter}
+		session, err := encoder.Decode(laravelEncryptedSessionGCM)
+		require.NoError(t, err)
+		require.Equal(t, expected, session)
+
+		raw, _ := base64.StdEncoding.DecodeString(laravelEncryptedSessionGCM)
+		tampered := strings.Replace(string(raw), `"tag":"c`, `"tag":"d`, 1)
+		_, err = encoder.Decode(base64.StdEncoding.EncodeToString([]byte(tampered)))
+		require.True(t, errors.Is(err, phpsessgo.ErrInvalidLaravelMAC))
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		raw, _ := base64.StdEncoding.DecodeString(laravelEncryptedSession)
+		tampered := strings.Replace(string(raw), `"mac":"f`, `"mac":"0`, 1)
+
+		encoder := &phpsessgo.LaravelSessionEncoder{Encrypter: encrypter}
+		_, err := encoder.Decode(base64.StdEncoding.EncodeToString([]byte(tampered)))
+		require.True(t, errors.Is(err, phpsessgo.ErrInvalidLaravelMAC))
+
+		_, err = encoder.Decode("not-a-payload")
+		require.True(t, errors.Is(err, phpsessgo.ErrInvalidLaravelPayload))
+	})
+
+	t.Run("plain payload", func(t *testing.T) {
+		encoder := &phpsessgo.LaravelSessionEncoder{}
+		session, err := encoder.Decode(`a:2:{s:6:"_token";s:8:"abcdefgh";s:9:"_previous";a:1:{s:3:"url";s:18:"http://example.com";}}`)
+		require.NoError(t, err)
+		require.Equal(t, expected, session)
+
+		encoded, err := encoder.Encode(phpencode.PhpSession{"_token": "abcdefgh"})
+		require.NoError(t, err)
+		require.Equal(t, `a:1:{s:6:"_token";s:8:"abcdefgh";}`, encoded)
+	})
+
+	for _, cipherName := range []string{phpsessgo.LaravelCipherAES256CBC, phpsessgo.LaravelCipherAES256GCM} {
+		t.Run("round trip "+cipherName, func(t *testing.T) {
+			encrypter, err := phpsessgo.NewLaravelEncrypter(laravelAppKey, cipherName)
+			require.NoError(t, err)
+			encoder := &phpsessgo.LaravelSessionEncoder{Encrypter: encrypter}
+
+			encoded, err := encoder.Encode(expected)
+			require.NoError(t, err)
+
+			session, err := encoder.Decode(encoded)
+			require.NoError(t, err)
+			require.Equal(t, expected, session)
+		})
+	}
+}
+
+func TestLaravelEncrypter_Cookie(t *testing.T) {
+	encrypter, err := phpsessgo.NewLaravelEncrypter(laravelAppKey, phpsessgo.LaravelCipherAES256CBC)
+	require.NoError(t, err)
+
+	sessionID, err := encrypter.DecryptCookie("laravel_session", laravelEncryptedCookie)
+	require.NoError(t, err)
+	require.Equal(t, laravelSessionID, sessionID)
+
+	_, err = encrypter.DecryptCookie("other_cookie", laravelEncryptedCookie)
+	require.True(t, errors.Is(err, phpsessgo.ErrInvalidLaravelMAC))
+
+	encrypted, err := encrypter.EncryptCookie("laravel_session", laravelSessionID)
+	require.NoError(t, err)
+	sessionID, err = encrypter.DecryptCookie("laravel_session", encrypted)
+	require.NoError(t, err)
+	require.Equal(t, laravelSessionID, sessionID)
+
+	t.Run("extractor", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Cookie", "laravel_session="+laravelEncryptedCookie+"; laravel_session=garbage")
+
+		extractor := &phpsessgo.LaravelCookieIDExtractor{Encrypter: encrypter}
+		require.Equal(t, []string{laravelSessionID}, extractor.Extract(req, "laravel_session"))
+	})
+}
@@ -0,0 +1,162 @@
+package phpsessgo
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errLZ4Corrupted = errors.New("phpsessgo: corrupted lz4 data")
+
+const (
+	lz4MinMatch     = 4
+	lz4LastLiterals = 5
+	lz4MFLimit      = 12
+	lz4MaxOffset    = 1<<16 - 1
+	lz4HashLog      = 12
+)
+
+// lz4CompressBlock produce LZ4 block format readable by LZ4_decompress_safe()
+func lz4CompressBlock(in []byte) []byte {
+	out := make([]byte, 0, len(in)+len(in)/255+16)
+	var table [1 << lz4HashLog]int
+	for i := range table {
+		table[i] = -1
+	}
+
+	anchor, ip := 0, 0
+	for ip+lz4MFLimit <= len(in) {
+		sequence := binary.LittleEndian.Uint32(in[ip:])
+		h := sequence * 2654435761 >> (32 - lz4HashLog)
+		ref := table[h]
+		table[h] = ip
+
+		if ref < 0 || ip-ref > lz4MaxOffset || binary.LittleEndian.Uint32(in[ref:]) != sequence {
+			ip++
+			continue
+		}
+
+		length := lz4MinMatch
+		for ip+length < len(in)-lz4LastLiterals && in[ref+length] == in[ip+length] {
+			length++
+		}
+
+		out = lz4AppendSequence(out, in[anchor:ip], ip-ref, length)
+		ip += length
+		anchor = ip
+	}
+
+	return lz4AppendSequence(out, in[anchor:], 0, 0)
+}
+
+// lz4AppendSequence append literals followed by the match, match of zero length end the block
+func lz4AppendSequence(out, literals []byte, offset, matchLength int) []byte {
+	token := byte(0)
+	if len(literals) >= 15 {
+		token = 15 << 4
+	} else {
+		token = byte(len(literals)) << 4
+	}
+	if matchLength > 0 {
+		if matchLength-lz4MinMatch >= 15 {
+			token |= 15
+		} else {
+			token |= byte(matchLength - lz4MinMatch)
+		}
+	}
+
+	out = append(out, token)
+	if len(literals) >= 15 {
+		out = lz4AppendLength(out, len(literals)-15)
+	}
+	out = append(out, literals...)
+
+	if matchLength > 0 {
+		out = append(out, byte(offset), byte(offset>>8))
+		if matchLength-lz4MinMatch >= 15 {
+			out = lz4AppendLength(out, matchLength-lz4MinMatch-15)
+		}
+	}
+	return out
+}
+
+func lz4AppendLength(out []byte, n int) []byte {
+	for n >= 255 {
+		out = append(out, 255)
+		n -= 255
+	}
+	return append(out, byte(n))
+}
+
+// lz4MaxRatio is the best compression ratio of LZ4, a block can't expand to more than that
+const lz4MaxRatio = 255
+
+// lz4DecompressBlock is LZ4_decompress_safe() with known decompressed size.
+// The size come from untrusted header, so it is checked against the input
+// and the output grow as the block is decoded
+func lz4DecompressBlock(in []byte, size int) ([]byte, error) {
+	if size < 0 || size > lz4MaxRatio*len(in) {
+		return nil, errLZ4Corrupted
+	}
+	out := make([]byte, 0, len(in))
+	ip := 0
+
+	readLength := func(n int) (int, error) {
+		if n != 15 {
+			return n, nil
+		}
+		for {
+			if ip >= len(in) {
+				return 0, errLZ4Corrupted
+			}
+			b := in[ip]
+			ip++
+			n += int(b)
+			if b != 255 {
+				return n, nil
+			}
+		}
+	}
+
+	for ip < len(in) {
+		token := in[ip]
+		ip++
+
+		literals, err := readLength(int(token >> 4))
+		if err != nil {
+			return nil, err
+		}
+		if ip+literals > len(in) || len(out)+literals > size {
+			return nil, errLZ4Corrupted
+		}
+		out = append(out, in[ip:ip+literals]...)
+		ip += literals
+
+		if ip == len(in) {
+			break
+		}
+		if ip+2 > len(in) {
+			return nil, errLZ4Corrupted
+		}
+		offset := int(in[ip]) | int(in[ip+1])<<8
+		ip += 2
+
+		length, err := readLength(int(token & 15))
+		if err != nil {
+			return nil, err
+		}
+		length += lz4MinMatch
+
+		ref := len(out) - offset
+		if offset == 0 || ref < 0 || len(out)+length > size {
+			return nil, errLZ4Corrupted
+		}
+		for i := 0; i < length; i++ {
+			out = append(out, out[ref+i])
+		}
+	}
+
+	if len(out) != size {
+		return nil, errLZ4Corrupted
+	}
+	return out, nil
+}
@@ -0,0 +1,38 @@
+package phpsessgo
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func compressionInputs() [][]byte {
+	random := make([]byte, 5000)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	return [][]byte{
+		[]byte("a"),
+		[]byte("hello world"),
+		bytes.Repeat([]byte("a"), 1000),
+		bytes.Repeat([]byte(`s:5:"hello";`), 500),
+		random,
+	}
+}
+
+func TestLZ4Block(t *testing.T) {
+	for _, input := range compressionInputs() {
+		decompressed, err := lz4DecompressBlock(lz4CompressBlock(input), len(input))
+		require.NoError(t, err)
+		require.Equal(t, input, decompressed)
+	}
+
+	_, err := lz4DecompressBlock([]byte{0x10, 'a', 0x05, 0x00}, 10)
+	require.Equal(t, errLZ4Corrupted, err)
+}
+
+func TestLZ4Block_SizeLargerThanRatio(t *testing.T) {
+	_, err := lz4DecompressBlock([]byte(`s:3:"abc";`), 1937533804)
+	require.Equal(t, errLZ4Corrupted, err)
+}
@@ -0,0 +1,108 @@
+package phpsessgo
+
+import "errors"
+
+var errLZFCorrupted = errors.New("phpsessgo: corrupted lzf data")
+
+const (
+	lzfMaxLiteral = 1 << 5
+	lzfMaxOffset  = 1 << 13
+	lzfMaxRef     = (1 << 8) + (1 << 3)
+	lzfHashSize   = 1 << 14
+)
+
+// lzfCompress is compatible with lzf_compress() of liblzf used by phpredis
+func lzfCompress(in []byte) []byte {
+	out := make([]byte, 0, len(in)+len(in)/lzfMaxLiteral+1)
+	var table [lzfHashSize]int
+	for i := range table {
+		table[i] = -1
+	}
+
+	literalStart := 0
+	flushLiterals := func(end int) {
+		for literalStart < end {
+			n := end - literalStart
+			if n > lzfMaxLiteral {
+				n = lzfMaxLiteral
+			}
+			out = append(out, byte(n-1))
+			out = append(out, in[literalStart:literalStart+n]...)
+			literalStart += n
+		}
+	}
+
+	ip := 0
+	for ip+2 < len(in) {
+		h := (uint32(in[ip])<<16 | uint32(in[ip+1])<<8 | uint32(in[ip+2])) * 2654435761 >> 18
+		ref := table[h]
+		table[h] = ip
+
+		if ref < 0 || ip-ref > lzfMaxOffset || in[ref] != in[ip] || in[ref+1] != in[ip+1] || in[ref+2] != in[ip+2] {
+			ip++
+			continue
+		}
+
+		length := 3
+		for ip+length < len(in) && length < lzfMaxRef && in[ref+length] == in[ip+length] {
+			length++
+		}
+
+		flushLiterals(ip)
+		offset := ip - ref - 1
+		if length-2 < 7 {
+			out = append(out, byte((length-2)<<5|offset>>8))
+		} else {
+			out = append(out, byte(7<<5|offset>>8), byte(length-2-7))
+		}
+		out = append(out, byte(offset))
+
+		ip += length
+		literalStart = ip
+	}
+	flushLiterals(len(in))
+	return out
+}
+
+// lzfDecompress is compatible with lzf_decompress() of liblzf, output larger than maxSize is refused
+func lzfDecompress(in []byte, maxSize int) ([]byte, error) {
+	out := make([]byte, 0, len(in)*2)
+	for ip := 0; ip < len(in); {
+		ctrl := int(in[ip])
+		ip++
+
+		if ctrl < lzfMaxLiteral {
+			ctrl++
+			if ip+ctrl > len(in) || len(out)+ctrl > maxSize {
+				return nil, errLZFCorrupted
+			}
+			out = append(out, in[ip:ip+ctrl]...)
+			ip += ctrl
+			continue
+		}
+
+		length := ctrl >> 5
+		ref := len(out) - (ctrl&0x1f)<<8 - 1
+		if length == 7 {
+			if ip >= len(in) {
+				return nil, errLZFCorrupted
+			}
+			length += int(in[ip])
+			ip++
+		}
+		if ip >= len(in) {
+			return nil, errLZFCorrupted
+		}
+		ref -= int(in[ip])
+		ip++
+		length += 2
+
+		if ref < 0 || len(out)+length > maxSize {
+			return nil, errLZFCorrupted
+		}
+		for i := 0; i < length; i++ {
+			out = append(out, out[ref+i])
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,25 @@
+package phpsessgo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLZF(t *testing.T) {
+	for _, input := range compressionInputs() {
+		decompressed, err := lzfDecompress(lzfCompress(input), len(input))
+		require.NoError(t, err)
+		require.Equal(t, input, decompressed)
+	}
+
+	_, err := lzfDecompress([]byte(`hello|s:5:"world";`), 1024)
+	require.Equal(t, errLZFCorrupted, err)
+}
+
+func TestLZF_MaxSize(t *testing.T) {
+	input := bytes.Repeat([]byte("a"), 1000)
+	_, err := lzfDecompress(lzfCompress(input), 999)
+	require.Equal(t, errLZFCorrupted, err)
+}
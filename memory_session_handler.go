@@ -0,0 +1,272 @@
+package phpsessgo
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSessionTooLarge returned when session data exceed MaxSessionSize
+	ErrSessionTooLarge = errors.New("phpsessgo: session data too large")
+	// ErrTooManySessions returned when new session would exceed MaxSessions
+	ErrTooManySessions = errors.New("phpsessgo: too many sessions")
+)
+
+// MemorySessionHandlerConfig configure MemorySessionHandler
+type MemorySessionHandlerConfig struct {
+	// Expiration of session since last write, zero mean never expire
+	Expiration time.Duration
+	// GCInterval of background garbage collection, zero disable it
+	GCInterval time.Duration
+	// MaxSessions stored at the same time, zero mean unlimited
+	MaxSessions int
+	// MaxSessionSize in bytes of single session data, zero mean unlimited
+	MaxSessionSize int
+}
+
+// MemorySessionHandler session management in process memory.
+// Useful for tests and single instance deployment
+type MemorySessionHandler struct {
+	SessionHandler
+	config MemorySessionHandlerConfig
+
+	mu       sync.RWMutex
+	sessions map[string]memorySession
+
+	locksMu sync.Mutex
+	locks   map[string]*sessionLock
+
+	stop      chan struct{}
+	closeOnce sync.Once
+	now       func() time.Time
+}
+
+type memorySession struct {
+	data      string
+	lastWrite time.Time
+	expiresAt time.Time
+}
+
+type sessionLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewMemorySessionHandler create new instance of MemorySessionHandler and start
+// the garbage collection loop when GCInterval is set
+func NewMemorySessionHandler(config MemorySessionHandlerConfig) *MemorySessionHandler {
+	h := &MemorySessionHandler{
+		config:   config,
+		sessions: make(map[string]memorySession),
+		locks:    make(map[string]*sessionLock),
+		stop:     make(chan struct{}),
+		now:      time.Now,
+	}
+
+	if config.GCInterval > 0 {
+		go h.gcLoop(config.GCInterval)
+	}
+
+	return h
+}
+
+// Close stop the garbage collection loop
+func (h *MemorySessionHandler) Close() {
+	h.closeOnce.Do(func() {
+		close(h.stop)
+	})
+}
+
+func (h *MemorySessionHandler) Read(sessionID string) (string, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	session, ok := h.sessions[sessionID]
+	if !ok || h.isExpired(session) {
+		return "", nil
+	}
+	return session.data, nil
+}
+
+func (h *MemorySessionHandler) Write(sessionID string, sessionData string) error {
+	if h.config.MaxSessionSize > 0 && len(sessionData) > h.config.MaxSessionSize {
+		return ErrSessionTooLarge
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.sessions[sessionID]; !ok && h.config.MaxSessions > 0 && len(h.sessions) >= h.config.MaxSessions {
+		h.gc()
+		if len(h.sessions) >= h.config.MaxSessions {
+			return ErrTooManySessions
+		}
+	}
+
+	now := h.now()
+	session := memorySession{data: sessionData, lastWrite: now}
+	if h.config.Expiration > 0 {
+		session.expiresAt = now.Add(h.config.Expiration)
+	}
+	h.sessions[sessionID] = session
+
+	return nil
+}
+
+// UpdateTimestamp refresh last write time and expiration of existing session,
+// like PHP files handler touch the session file
+func (h *MemorySessionHandler) UpdateTimestamp(sessionID string, sessionData string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	session, ok := h.sessions[sessionID]
+	if !ok || h.isExpired(session) {
+		return nil
+	}
+	session.lastWrite = h.now()
+	if h.config.Expiration > 0 {
+		session.expiresAt = session.lastWrite.Add(h.config.Expiration)
+	}
+	h.sessions[sessionID] = session
+	return nil
+}
+
+// Destroy the session
+func (h *MemorySessionHandler) Destroy(sessionID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.sessions, sessionID)
+	return nil
+}
+
+// Gc remove expired sessions and return number of removed sessions
+func (h *MemorySessionHandler) Gc() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.gc()
+}
+
+// Lock the session until returned function is called, adoption of PHP files handler locking
+// to serialize concurrent requests of the same session
+func (h *MemorySessionHandler) Lock(sessionID string) (unlock func()) {
+	h.locksMu.Lock()
+	lock, ok := h.locks[sessionID]
+	if !ok {
+		lock = &sessionLock{}
+		h.locks[sessionID] = lock
+	}
+	lock.refs++
+	h.locksMu.Unlock()
+
+	lock.Lock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			lock.Unlock()
+
+			h.locksMu.Lock()
+			lock.refs--
+			if lock.refs == 0 {
+				delete(h.locks, sessionID)
+			}
+			h.locksMu.Unlock()
+		})
+	}
+}
+
+// List return sorted IDs of active sessions
+func (h *MemorySessionHandler) List() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ids := make([]string, 0, len(h.sessions))
+	for id, session := range h.sessions {
+		if !h.isExpired(session) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Count return number of active sessions
+func (h *MemorySessionHandler) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	count := 0
+	for _, session := range h.sessions {
+		if !h.isExpired(session) {
+			count++
+		}
+	}
+	return count
+}
+
+// Snapshot return copy of active sessions data keyed by session ID
+func (h *MemorySessionHandler) Snapshot() map[string]string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	snapshot := make(map[string]string, len(h.sessions))
+	for id, session := range h.sessions {
+		if !h.isExpired(session) {
+			snapshot[id] = session.data
+		}
+	}
+	return snapshot
+}
+
+// WalkSessions call fn for every active session in ID order
+func (h *MemorySessionHandler) WalkSessions(fn func(info SessionInfo) error) error {
+	h.mu.RLock()
+	infos := make([]SessionInfo, 0, len(h.sessions))
+	for id, session := range h.sessions {
+		if h.isExpired(session) {
+			continue
+		}
+		infos = append(infos, SessionInfo{ID: id, LastWrite: session.lastWrite})
+	}
+	h.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	for _, info := range infos {
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *MemorySessionHandler) gcLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.Gc()
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+func (h *MemorySessionHandler) gc() (removed int) {
+	for id, session := range h.sessions {
+		if h.isExpired(session) {
+			delete(h.sessions, id)
+			removed++
+		}
+	}
+	return
+}
+
+func (h *MemorySessionHandler) isExpired(session memorySession) bool {
+	return !session.expiresAt.IsZero() && !h.now().Before(session.expiresAt)
+}
@@ -0,0 +1,161 @@
+package phpsessgo
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySessionHandler(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler := NewMemorySessionHandler(MemorySessionHandlerConfig{
+		Expiration: time.Minute,
+	})
+	handler.now = func() time.Time { return now }
+	defer handler.Close()
+
+	t.Run("write and read data", func(t *testing.T) {
+		require.NoError(t, handler.Write("some-sessionID", "some-data"))
+
+		data, err := handler.Read("some-sessionID")
+		require.NoError(t, err)
+		require.Equal(t, "some-data", data)
+	})
+
+	t.Run("read not existing data", func(t *testing.T) {
+		data, err := handler.Read("not-exist")
+		require.NoError(t, err)
+		require.Equal(t, "", data)
+	})
+
+	t.Run("inspection", func(t *testing.T) {
+		require.NoError(t, handler.Write("another-sessionID", "another-data"))
+
+		require.Equal(t, []string{"another-sessionID", "some-sessionID"}, handler.List())
+		require.Equal(t, 2, handler.Count())
+		require.Equal(t, map[string]string{
+			"some-sessionID":    "some-data",
+			"another-sessionID": "another-data",
+		}, handler.Snapshot())
+	})
+
+	t.Run("update timestamp", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		require.NoError(t, handler.UpdateTimestamp("some-sessionID", "some-data"))
+		require.NoError(t, handler.UpdateTimestamp("not-exist", ""))
+		require.Equal(t, now.Add(time.Minute), handler.sessions["some-sessionID"].expiresAt)
+		require.NotContains(t, handler.sessions, "not-exist")
+	})
+
+	t.Run("destroy", func(t *testing.T) {
+		require.NoError(t, handler.Destroy("another-sessionID"))
+		require.Equal(t, []string{"some-sessionID"}, handler.List())
+	})
+
+	t.Run("expired data", func(t *testing.T) {
+		now = now.Add(time.Minute)
+
+		data, err := handler.Read("some-sessionID")
+		require.NoError(t, err)
+		require.Equal(t, "", data)
+		require.Equal(t, 0, handler.Count())
+		require.Equal(t, 1, handler.Gc())
+		require.Empty(t, handler.sessions)
+	})
+}
+
+func TestMemorySessionHandler_Limits(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler := NewMemorySessionHandler(MemorySessionHandlerConfig{
+		Expiration:     time.Minute,
+		MaxSessions:    1,
+		MaxSessionSize: 4,
+	})
+	handler.now = func() time.Time { return now }
+	defer handler.Close()
+
+	require.Equal(t, ErrSessionTooLarge, handler.Write("a", "12345"))
+	require.NoError(t, handler.Write("a", "1234"))
+	require.NoError(t, handler.Write("a", "4321"))
+	require.Equal(t, ErrTooManySessions, handler.Write("b", "1234"))
+
+	now = now.Add(time.Minute)
+	require.NoError(t, handler.Write("b", "1234"))
+	require.Equal(t, []string{"b"}, handler.List())
+}
+
+func TestMemorySessionHandler_GCLoop(t *testing.T) {
+	handler := NewMemorySessionHandler(MemorySessionHandlerConfig{
+		Expiration: time.Millisecond,
+		GCInterval: time.Millisecond,
+	})
+	defer handler.Close()
+
+	require.NoError(t, handler.Write("some-sessionID", "some-data"))
+	require.Eventually(t, func() bool {
+		handler.mu.RLock()
+		defer handler.mu.RUnlock()
+		return len(handler.sessions) == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestMemorySessionHandler_Lock(t *testing.T) {
+	handler := NewMemorySessionHandler(MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	var (
+		wg      sync.WaitGroup
+		counter int
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := handler.Lock("some-sessionID")
+			defer unlock()
+			counter++
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 50, counter)
+	require.Empty(t, handler.locks)
+}
+
+func TestMemorySessionHandler_WalkSessions(t *testing.T) {
+	handler := NewMemorySessionHandler(MemorySessionHandlerConfig{Expiration: time.Hour})
+	defer handler.Close()
+
+	require.NoError(t, handler.Write("b", "b|i:1;"))
+	require.NoError(t, handler.Write("a", "a|i:1;"))
+
+	var ids []string
+	require.NoError(t, handler.WalkSessions(func(info SessionInfo) error {
+		require.WithinDuration(t, time.Now(), info.LastWrite, time.Second)
+		ids = append(ids, info.ID)
+		return nil
+	}))
+	require.Equal(t, []string{"a", "b"}, ids)
+
+	t.Run("last write updated by UpdateTimestamp", func(t *testing.T) {
+		for _, expiration := range []time.Duration{0, time.Hour} {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			handler := NewMemorySessionHandler(MemorySessionHandlerConfig{Expiration: expiration})
+			handler.now = func() time.Time { return now }
+
+			require.NoError(t, handler.Write("a", "a|i:1;"))
+			now = now.Add(10 * time.Minute)
+			require.NoError(t, handler.UpdateTimestamp("a", "a|i:1;"))
+
+			var lastWrite time.Time
+			require.NoError(t, handler.WalkSessions(func(info SessionInfo) error {
+				lastWrite = info.LastWrite
+				return nil
+			}))
+			require.Equal(t, now, lastWrite, expiration)
+			handler.Close()
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package phpsessgo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phpserialize"
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+type sessionContextKey struct{}
+
+// MiddlewareConfig configure the session middleware
+type MiddlewareConfig struct {
+	// ErrorHandler called when the session failed to start or to save.
+	// Save happen before the first header write, so the handler still can change the response.
+	// Default respond with 500 Internal Server Error
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+	// LateSaveErrorHandler called when the session changed after the response headers were
+	// written failed to be saved again, e.g. HTTPSessionHandler can't set the cookies anymore.
+	// The response can't be changed at that point, default log the error
+	LateSaveErrorHandler func(r *http.Request, err error)
+}
+
+// NewMiddleware create net/http middleware which start the session, store it in the request context
+// and save it right before the response headers are written. The session changed after the headers
+// were written is saved again once the handler returns, which only store-backed handlers can do,
+// the failure is reported to LateSaveErrorHandler
+func NewMiddleware(manager SessionManager, config MiddlewareConfig) func(http.Handler) http.Handler {
+	errorHandler := config.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = defaultErrorHandler
+	}
+	lateSaveErrorHandler := config.LateSaveErrorHandler
+	if lateSaveErrorHandler == nil {
+		lateSaveErrorHandler = defaultLateSaveErrorHandler
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, err := manager.Start(w, r)
+			if err != nil {
+				errorHandler(w, r, err)
+				return
+			}
+
+			r = r.WithContext(ContextWithSession(r.Context(), session))
+			sw := &sessionResponseWriter{
+				ResponseWriter: w,
+				manager:        manager,
+				session:        session,
+				request:        r,
+				errorHandler:   errorHandler,
+			}
+			// HTTPSessionHandler can tell the session is saved too late
+			session.response = sw
+			next.ServeHTTP(sw, r)
+			if sw.saved {
+				sw.saveChanges(lateSaveErrorHandler)
+				return
+			}
+			sw.save()
+		})
+	}
+}
+
+// ContextWithSession return copy of the context holding the session
+func ContextWithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, session)
+}
+
+// SessionFromContext return the session started by the middleware or nil when there is none
+func SessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionContextKey{}).(*Session)
+	return session
+}
+
+func defaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+func defaultLateSaveErrorHandler(r *http.Request, err error) {
+	log.Printf("phpsessgo: session changed after the response headers were written is lost: %v", err)
+}
+
+// sessionResponseWriter save the session before the first header write
+type sessionResponseWriter struct {
+	http.ResponseWriter
+	manager      SessionManager
+	session      *Session
+	request      *http.Request
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error)
+	saved        bool
+	aborted      bool
+	wroteHeader  bool
+	// snapshot is the session value when it was saved, if it could be serialized
+	snapshot    string
+	snapshotted bool
+}
+
+func (w *sessionResponseWriter) WriteHeader(statusCode int) {
+	w.save()
+	w.wroteHeader = true
+	if w.aborted {
+		return
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *sessionResponseWriter) Write(b []byte) (int, error) {
+	w.save()
+	w.wroteHeader = true
+	if w.aborted {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionResponseWriter) Flush() {
+	w.save()
+	w.wroteHeader = true
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && !w.aborted {
+		flusher.Flush()
+	}
+}
+
+func (w *sessionResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.save()
+	w.wroteHeader = true
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("phpsessgo: response writer does not implement http.Hijacker")
+	}
+	return hijacker.Hijack()
+}
+
+// Written report whether the response headers were written
+func (w *sessionResponseWriter) Written() bool {
+	return w.wroteHeader
+}
+
+// Unwrap return the original response writer for http.ResponseController
+func (w *sessionResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *sessionResponseWriter) save() {
+	if w.saved {
+		return
+	}
+	w.saved = true
+
+	if err := w.manager.SaveContext(w.request.Context(), w.session); err != nil {
+		tracker := &headerTracker{ResponseWriter: w.ResponseWriter}
+		w.errorHandler(tracker, w.request, err)
+		// the error handler has taken over the response
+		w.aborted = tracker.wroteHeader
+		return
+	}
+	w.snapshot, w.snapshotted = snapshot(w.session.Value)
+}
+
+// saveChanges save the session again when it changed after it was saved
+func (w *sessionResponseWriter) saveChanges(errorHandler func(r *http.Request, err error)) {
+	if w.aborted {
+		return
+	}
+	if current, ok := snapshot(w.session.Value); ok && w.snapshotted && current == w.snapshot {
+		return
+	}
+	if err := w.manager.SaveContext(w.request.Context(), w.session); err != nil {
+		errorHandler(w.request, err)
+	}
+}
+
+// snapshot serialize the session value to tell whether it changed, it report whether the value
+// could be serialized
+func snapshot(value phpencode.PhpSession) (string, bool) {
+	attributes := make(phptype.Array, len(value))
+	for k, v := range value {
+		attributes[k] = v
+	}
+	serialized, err := phpserialize.Serialize(attributes)
+	return serialized, err == nil
+}
+
+type headerTracker struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *headerTracker) WriteHeader(statusCode int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *headerTracker) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
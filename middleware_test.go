@@ -0,0 +1,137 @@
+package phpsessgo_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	handler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	manager, err := phpsessgo.NewSessionManager(
+		phpsessgo.DefaultSessionName,
+		&phpsessgo.UUIDCreator{},
+		handler,
+		&phpsessgo.PHPSessionEncoder{},
+		phpsessgo.SessionManagerConfig{CookiePath: "/"},
+	)
+	require.NoError(t, err)
+	middleware := phpsessgo.NewMiddleware(manager, phpsessgo.MiddlewareConfig{})
+
+	t.Run("new session", func(t *testing.T) {
+		var sessionID string
+		h := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session := phpsessgo.SessionFromContext(r.Context())
+			require.NotNil(t, session)
+			sessionID = session.SessionID
+			session.Value["hello"] = "world"
+
+			w.WriteHeader(http.StatusCreated)
+
+			// saved before the first header write
+			data, _ := handler.Read(sessionID)
+			require.Equal(t, `hello|s:5:"world";`, data)
+		}))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.Equal(t, manager.SetCookieString(sessionID), rr.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("existing session saved without explicit write", func(t *testing.T) {
+		require.NoError(t, handler.Write("existing-session", `hello|s:5:"world";`))
+
+		h := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session := phpsessgo.SessionFromContext(r.Context())
+			require.Equal(t, "world", session.Value["hello"])
+			session.Value["hello"] = "gopher"
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: phpsessgo.DefaultSessionName, Value: "existing-session"})
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Empty(t, rr.Header().Get("Set-Cookie"))
+		data, _ := handler.Read("existing-session")
+		require.Equal(t, `hello|s:6:"gopher";`, data)
+	})
+
+	t.Run("changed after headers saved again", func(t *testing.T) {
+		require.NoError(t, handler.Write("late-session", `hello|s:5:"world";`))
+
+		h := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("some-body"))
+			session := phpsessgo.SessionFromContext(r.Context())
+			session.Value["hello"] = "gopher"
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: phpsessgo.DefaultSessionName, Value: "late-session"})
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		data, _ := handler.Read("late-session")
+		require.Equal(t, `hello|s:6:"gopher";`, data)
+	})
+}
+
+func TestMiddleware_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager := phpsessgo.NewMockSessionManager(ctrl)
+
+	t.Run("start failed", func(t *testing.T) {
+		manager.EXPECT().Start(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some-error"))
+
+		h := phpsessgo.NewMiddleware(manager, phpsessgo.MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("save failed", func(t *testing.T) {
+		session := phpsessgo.NewSession()
+		manager.EXPECT().Start(gomock.Any(), gomock.Any()).Return(session, nil)
+		manager.EXPECT().SaveContext(gomock.Any(), session).Return(fmt.Errorf("some-error"))
+
+		var reported error
+		h := phpsessgo.NewMiddleware(manager, phpsessgo.MiddlewareConfig{
+			ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				reported = err
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("some-body"))
+		}))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.EqualError(t, reported, "some-error")
+		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		require.Empty(t, rr.Body.String())
+	})
+}
+
+func TestSessionFromContext(t *testing.T) {
+	require.Nil(t, phpsessgo.SessionFromContext(context.Background()))
+
+	session := phpsessgo.NewSession()
+	ctx := phpsessgo.ContextWithSession(context.Background(), session)
+	require.Equal(t, session, phpsessgo.SessionFromContext(ctx))
+}
@@ -0,0 +1,223 @@
+package phpsessgo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// MigratingSessionHandlerConfig configure MigratingSessionHandler
+type MigratingSessionHandlerConfig struct {
+	// DualWrite write to the old store too, so the migration can be rolled back
+	DualWrite bool
+	// CopyOnRead write session found only in the old store to the new one right away,
+	// instead of waiting for the session to be saved
+	CopyOnRead bool
+}
+
+// MigratingSessionHandler move sessions from the old store to the new one as they are used.
+// It read the new store first and fallback to the old one, writes go to the new store.
+// Once a session read from the old store is written to the new one its old copy is destroyed,
+// so emptying or destroying the session in the new store doesn't bring the stale data back.
+// The sessions read from the old store are remembered by this handler until written, use
+// CopyOnRead when the session can be read and written by different processes.
+// With DualWrite the old copy is kept up to date instead, and sessions destroyed
+// outside of this handler must be destroyed in both stores
+type MigratingSessionHandler struct {
+	SessionHandler
+	Old    SessionHandler
+	config MigratingSessionHandlerConfig
+	// stats and fellBack are shared with the copies bound to the request
+	stats *MigrationStats
+	// fellBack hold the IDs of sessions read from the old store whose old copy is not destroyed yet
+	fellBack *sync.Map
+}
+
+// MigrationStats count progress of the migration
+type MigrationStats struct {
+	newHits         uint64
+	oldHits         uint64
+	misses          uint64
+	copied          uint64
+	dualWriteErrors uint64
+}
+
+// NewHits return number of reads served by the new store
+func (s *MigrationStats) NewHits() uint64 {
+	return atomic.LoadUint64(&s.newHits)
+}
+
+// OldHits return number of reads served by the old store, the sessions which were not migrated yet
+func (s *MigrationStats) OldHits() uint64 {
+	return atomic.LoadUint64(&s.oldHits)
+}
+
+// Misses return number of reads found in neither store
+func (s *MigrationStats) Misses() uint64 {
+	return atomic.LoadUint64(&s.misses)
+}
+
+// Copied return number of sessions copied to the new store on read
+func (s *MigrationStats) Copied() uint64 {
+	return atomic.LoadUint64(&s.copied)
+}
+
+// DualWriteErrors return number of failed writes to the old store, they don't fail the write
+func (s *MigrationStats) DualWriteErrors() uint64 {
+	return atomic.LoadUint64(&s.dualWriteErrors)
+}
+
+// NewMigratingSessionHandler create new instance of MigratingSessionHandler
+func NewMigratingSessionHandler(newHandler, oldHandler SessionHandler, config MigratingSessionHandlerConfig) *MigratingSessionHandler {
+	return &MigratingSessionHandler{
+		SessionHandler: newHandler,
+		Old:            oldHandler,
+		config:         config,
+		stats:          &MigrationStats{},
+		fellBack:       &sync.Map{},
+	}
+}
+
+// Stats return the migration counters
+func (h *MigratingSessionHandler) Stats() *MigrationStats {
+	return h.stats
+}
+
+// Unwrap return the new store
+func (h *MigratingSessionHandler) Unwrap() SessionHandler {
+	return h.SessionHandler
+}
+
+// bindRequest bind HTTPSessionHandler of either store to the request
+func (h *MigratingSessionHandler) bindRequest(w http.ResponseWriter, r *http.Request) (SessionHandler, bool, error) {
+	newHandler, newBound, err := bindRequest(h.SessionHandler, w, r)
+	if err != nil {
+		return h, false, err
+	}
+	oldHandler, oldBound, err := bindRequest(h.Old, w, r)
+	if err != nil {
+		return h, false, err
+	}
+	if !newBound && !oldBound {
+		return h, false, nil
+	}
+	return &MigratingSessionHandler{SessionHandler: newHandler, Old: oldHandler, config: h.config, stats: h.stats, fellBack: h.fellBack}, true, nil
+}
+
+// Close both stores
+func (h *MigratingSessionHandler) Close() {
+	h.SessionHandler.Close()
+	h.Old.Close()
+}
+
+func (h *MigratingSessionHandler) Read(sessionID string) (string, error) {
+	return h.ReadContext(context.Background(), sessionID)
+}
+
+func (h *MigratingSessionHandler) Write(sessionID string, sessionData string) error {
+	return h.WriteContext(context.Background(), sessionID, sessionData)
+}
+
+// ReadContext read the new store and fallback to the old one on miss
+func (h *MigratingSessionHandler) ReadContext(ctx context.Context, sessionID string) (string, error) {
+	newHandler := NewContextSessionHandler(h.SessionHandler)
+	data, err := newHandler.ReadContext(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	if data != "" {
+		atomic.AddUint64(&h.stats.newHits, 1)
+		return data, nil
+	}
+
+	if data, err = NewContextSessionHandler(h.Old).ReadContext(ctx, sessionID); err != nil {
+		return "", err
+	}
+	if data == "" {
+		atomic.AddUint64(&h.stats.misses, 1)
+		return "", nil
+	}
+
+	atomic.AddUint64(&h.stats.oldHits, 1)
+	if h.config.CopyOnRead {
+		if err := newHandler.WriteContext(ctx, sessionID, data); err != nil {
+			return "", err
+		}
+		if err := h.destroyOld(ctx, sessionID); err != nil {
+			return "", err
+		}
+		atomic.AddUint64(&h.stats.copied, 1)
+	} else if !h.config.DualWrite {
+		h.fellBack.Store(sessionID, struct{}{})
+	}
+	return data, nil
+}
+
+// WriteContext write the new store and the old one with DualWrite
+func (h *MigratingSessionHandler) WriteContext(ctx context.Context, sessionID string, sessionData string) error {
+	if err := NewContextSessionHandler(h.SessionHandler).WriteContext(ctx, sessionID, sessionData); err != nil {
+		return err
+	}
+	if h.config.DualWrite {
+		if err := NewContextSessionHandler(h.Old).WriteContext(ctx, sessionID, sessionData); err != nil {
+			atomic.AddUint64(&h.stats.dualWriteErrors, 1)
+		}
+		return nil
+	}
+	// only the session read from the old store may still have a copy there
+	if _, ok := h.fellBack.Load(sessionID); !ok {
+		return nil
+	}
+	if err := h.destroyOld(ctx, sessionID); err != nil {
+		return err
+	}
+	h.fellBack.Delete(sessionID)
+	return nil
+}
+
+// destroyOld remove the old copy of session migrated to the new store, the fallback
+// is then used only for sessions which were never migrated
+func (h *MigratingSessionHandler) destroyOld(ctx context.Context, sessionID string) error {
+	if h.config.DualWrite {
+		return nil
+	}
+	if _, ok := h.Old.(SessionDestroyHandler); ok {
+		return destroySession(ctx, h.Old, sessionID)
+	}
+	return NewContextSessionHandler(h.Old).WriteContext(ctx, sessionID, "")
+}
+
+// UpdateTimestamp of the new store, and of the old one with DualWrite
+func (h *MigratingSessionHandler) UpdateTimestamp(sessionID string, sessionData string) error {
+	return h.UpdateTimestampContext(context.Background(), sessionID, sessionData)
+}
+
+// UpdateTimestampContext is UpdateTimestamp with context of both stores
+func (h *MigratingSessionHandler) UpdateTimestampContext(ctx context.Context, sessionID string, sessionData string) error {
+	if err := updateTimestamp(ctx, h.SessionHandler, sessionID, sessionData); err != nil {
+		return err
+	}
+	if h.config.DualWrite {
+		if err := updateTimestamp(ctx, h.Old, sessionID, sessionData); err != nil {
+			atomic.AddUint64(&h.stats.dualWriteErrors, 1)
+		}
+	}
+	return nil
+}
+
+// Destroy the session in both stores, so it can't be resurrected from the old one
+func (h *MigratingSessionHandler) Destroy(sessionID string) error {
+	return h.DestroyContext(context.Background(), sessionID)
+}
+
+// DestroyContext is Destroy with context of both stores
+func (h *MigratingSessionHandler) DestroyContext(ctx context.Context, sessionID string) error {
+	for _, handler := range []SessionHandler{h.SessionHandler, h.Old} {
+		if err := destroySession(ctx, handler, sessionID); err != nil {
+			return err
+		}
+	}
+	h.fellBack.Delete(sessionID)
+	return nil
+}
@@ -0,0 +1,213 @@
+package phpsessgo_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSessionHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phpsessgo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	handler, err := phpsessgo.NewFileSessionHandler(dir)
+	require.NoError(t, err)
+
+	data, err := handler.Read("abc123")
+	require.NoError(t, err)
+	require.Equal(t, "", data)
+
+	require.NoError(t, handler.Write("abc123", "foo|s:3:\"bar\";"))
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "sess_abc123"))
+	require.NoError(t, err)
+	require.Equal(t, "foo|s:3:\"bar\";", string(raw))
+
+	data, err = handler.Read("abc123")
+	require.NoError(t, err)
+	require.Equal(t, "foo|s:3:\"bar\";", data)
+
+	_, err = handler.Read("../../etc/passwd")
+	require.Error(t, err)
+
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "sess_abc123"), old, old))
+	require.NoError(t, handler.Write("def456", "a|i:1;"))
+	removed, err := handler.Gc(time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "sess_def456"), old, old))
+	replaced, err := handler.RewriteSession("def456", "a|i:2;", "a:1:{s:1:\"a\";i:1;}")
+	require.NoError(t, err)
+	require.False(t, replaced)
+	replaced, err = handler.RewriteSession("def456", "a|i:1;", "a:1:{s:1:\"a\";i:1;}")
+	require.NoError(t, err)
+	require.True(t, replaced)
+	data, err = handler.Read("def456")
+	require.NoError(t, err)
+	require.Equal(t, "a:1:{s:1:\"a\";i:1;}", data)
+	info, err := os.Stat(filepath.Join(dir, "sess_def456"))
+	require.NoError(t, err)
+	require.True(t, info.ModTime().Equal(old))
+
+	require.NoError(t, handler.Destroy("def456"))
+	require.NoError(t, handler.Destroy("def456"))
+	data, err = handler.Read("def456")
+	require.NoError(t, err)
+	require.Equal(t, "", data)
+}
+
+func TestNewFileSessionHandler_SavePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phpsessgo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	handler, err := phpsessgo.NewFileSessionHandler("1;640;" + dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, handler.Depth)
+	require.Equal(t, os.FileMode(0640), handler.Mode)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "a"), 0700))
+	require.NoError(t, handler.Write("abc123", "a|i:1;"))
+	_, err = os.Stat(filepath.Join(dir, "a", "sess_abc123"))
+	require.NoError(t, err)
+
+	for _, savePath := range []string{"", "x;/tmp", "1;999;/tmp", "1;2;3;/tmp"} {
+		_, err := phpsessgo.NewFileSessionHandler(savePath)
+		require.Error(t, err, savePath)
+	}
+}
+
+func TestMigratingSessionHandler(t *testing.T) {
+	oldHandler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	newHandler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	handler := phpsessgo.NewMigratingSessionHandler(newHandler, oldHandler, phpsessgo.MigratingSessionHandlerConfig{})
+	defer handler.Close()
+
+	require.NoError(t, oldHandler.Write("old", "a|i:1;"))
+
+	data, err := handler.Read("old")
+	require.NoError(t, err)
+	require.Equal(t, "a|i:1;", data)
+
+	data, err = handler.Read("missing")
+	require.NoError(t, err)
+	require.Equal(t, "", data)
+
+	require.NoError(t, handler.Write("old", "a|i:2;"))
+	data, err = handler.Read("old")
+	require.NoError(t, err)
+	require.Equal(t, "a|i:2;", data)
+
+	data, err = oldHandler.Read("old")
+	require.NoError(t, err)
+	require.Equal(t, "", data)
+
+	stats := handler.Stats()
+	require.Equal(t, uint64(1), stats.NewHits())
+	require.Equal(t, uint64(1), stats.OldHits())
+	require.Equal(t, uint64(1), stats.Misses())
+	require.Equal(t, uint64(0), stats.Copied())
+
+	require.NoError(t, handler.Destroy("old"))
+	data, err = handler.Read("old")
+	require.NoError(t, err)
+	require.Equal(t, "", data)
+}
+
+func TestMigratingSessionHandler_EmptiedSession(t *testing.T) {
+	for _, config := range []phpsessgo.MigratingSessionHandlerConfig{{}, {CopyOnRead: true}} {
+		oldHandler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+		newHandler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+		handler := phpsessgo.NewMigratingSessionHandler(newHandler, oldHandler, config)
+
+		require.NoError(t, oldHandler.Write("abc", "user_id|i:42;"))
+		data, err := handler.Read("abc")
+		require.NoError(t, err)
+		require.Equal(t, "user_id|i:42;", data)
+
+		require.NoError(t, handler.Write("abc", ""))
+		data, err = handler.Read("abc")
+		require.NoError(t, err)
+		require.Equal(t, "", data)
+
+		// PHP destroying the session in the new store directly
+		require.NoError(t, handler.Write("abc", "user_id|i:42;"))
+		require.NoError(t, newHandler.Destroy("abc"))
+		data, err = handler.Read("abc")
+		require.NoError(t, err)
+		require.Equal(t, "", data)
+
+		handler.Close()
+	}
+}
+
+func TestMigratingSessionHandler_DestroyOnlyFallback(t *testing.T) {
+	oldHandler := &destroyCountingSessionHandler{MemorySessionHandler: phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})}
+	newHandler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	handler := phpsessgo.NewMigratingSessionHandler(newHandler, oldHandler, phpsessgo.MigratingSessionHandlerConfig{})
+	defer handler.Close()
+
+	// session served by the new store
+	require.NoError(t, newHandler.Write("new", "a|i:1;"))
+	_, err := handler.Read("new")
+	require.NoError(t, err)
+	require.NoError(t, handler.Write("new", "a|i:2;"))
+	require.NoError(t, handler.Write("created", "a|i:1;"))
+	require.Equal(t, 0, oldHandler.destroyed)
+
+	// session served by the old store is destroyed there once
+	require.NoError(t, oldHandler.Write("old", "a|i:1;"))
+	_, err = handler.Read("old")
+	require.NoError(t, err)
+	require.NoError(t, handler.Write("old", "a|i:2;"))
+	require.NoError(t, handler.Write("old", "a|i:3;"))
+	require.Equal(t, 1, oldHandler.destroyed)
+	data, err := oldHandler.Read("old")
+	require.NoError(t, err)
+	require.Equal(t, "", data)
+}
+
+func TestMigratingSessionHandler_DualWriteCopyOnRead(t *testing.T) {
+	oldHandler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	newHandler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	handler := phpsessgo.NewMigratingSessionHandler(newHandler, oldHandler, phpsessgo.MigratingSessionHandlerConfig{
+		DualWrite:  true,
+		CopyOnRead: true,
+	})
+	defer handler.Close()
+
+	require.NoError(t, oldHandler.Write("old", "a|i:1;"))
+
+	data, err := handler.Read("old")
+	require.NoError(t, err)
+	require.Equal(t, "a|i:1;", data)
+
+	data, err = newHandler.Read("old")
+	require.NoError(t, err)
+	require.Equal(t, "a|i:1;", data)
+	require.Equal(t, uint64(1), handler.Stats().Copied())
+
+	require.NoError(t, handler.Write("old", "a|i:2;"))
+	data, err = oldHandler.Read("old")
+	require.NoError(t, err)
+	require.Equal(t, "a|i:2;", data)
+	require.Equal(t, uint64(0), handler.Stats().DualWriteErrors())
+}
+
+// destroyCountingSessionHandler count the sessions destroyed
+type destroyCountingSessionHandler struct {
+	*phpsessgo.MemorySessionHandler
+	destroyed int
+}
+
+func (h *destroyCountingSessionHandler) Destroy(sessionID string) error {
+	h.destroyed++
+	return h.MemorySessionHandler.Destroy(sessionID)
+}
@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 )
@@ -72,3 +73,170 @@ func (mr *MockSessionHandlerMockRecorder) Write(sessionID, sessionData interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockSessionHandler)(nil).Write), sessionID, sessionData)
 }
+
+// MockSessionUpdateTimestampHandler is a mock of SessionUpdateTimestampHandler interface
+type MockSessionUpdateTimestampHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionUpdateTimestampHandlerMockRecorder
+}
+
+// MockSessionUpdateTimestampHandlerMockRecorder is the mock recorder for MockSessionUpdateTimestampHandler
+type MockSessionUpdateTimestampHandlerMockRecorder struct {
+	mock *MockSessionUpdateTimestampHandler
+}
+
+// NewMockSessionUpdateTimestampHandler creates a new mock instance
+func NewMockSessionUpdateTimestampHandler(ctrl *gomock.Controller) *MockSessionUpdateTimestampHandler {
+	mock := &MockSessionUpdateTimestampHandler{ctrl: ctrl}
+	mock.recorder = &MockSessionUpdateTimestampHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSessionUpdateTimestampHandler) EXPECT() *MockSessionUpdateTimestampHandlerMockRecorder {
+	return m.recorder
+}
+
+// UpdateTimestamp mocks base method
+func (m *MockSessionUpdateTimestampHandler) UpdateTimestamp(sessionID, sessionData string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTimestamp", sessionID, sessionData)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTimestamp indicates an expected call of UpdateTimestamp
+func (mr *MockSessionUpdateTimestampHandlerMockRecorder) UpdateTimestamp(sessionID, sessionData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTimestamp", reflect.TypeOf((*MockSessionUpdateTimestampHandler)(nil).UpdateTimestamp), sessionID, sessionData)
+}
+
+// MockSessionDestroyHandler is a mock of SessionDestroyHandler interface
+type MockSessionDestroyHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionDestroyHandlerMockRecorder
+}
+
+// MockSessionDestroyHandlerMockRecorder is the mock recorder for MockSessionDestroyHandler
+type MockSessionDestroyHandlerMockRecorder struct {
+	mock *MockSessionDestroyHandler
+}
+
+// NewMockSessionDestroyHandler creates a new mock instance
+func NewMockSessionDestroyHandler(ctrl *gomock.Controller) *MockSessionDestroyHandler {
+	mock := &MockSessionDestroyHandler{ctrl: ctrl}
+	mock.recorder = &MockSessionDestroyHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSessionDestroyHandler) EXPECT() *MockSessionDestroyHandlerMockRecorder {
+	return m.recorder
+}
+
+// Destroy mocks base method
+func (m *MockSessionDestroyHandler) Destroy(sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Destroy", sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Destroy indicates an expected call of Destroy
+func (mr *MockSessionDestroyHandlerMockRecorder) Destroy(sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Destroy", reflect.TypeOf((*MockSessionDestroyHandler)(nil).Destroy), sessionID)
+}
+
+// MockContextSessionHandler is a mock of ContextSessionHandler interface
+type MockContextSessionHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockContextSessionHandlerMockRecorder
+}
+
+// MockContextSessionHandlerMockRecorder is the mock recorder for MockContextSessionHandler
+type MockContextSessionHandlerMockRecorder struct {
+	mock *MockContextSessionHandler
+}
+
+// NewMockContextSessionHandler creates a new mock instance
+func NewMockContextSessionHandler(ctrl *gomock.Controller) *MockContextSessionHandler {
+	mock := &MockContextSessionHandler{ctrl: ctrl}
+	mock.recorder = &MockContextSessionHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockContextSessionHandler) EXPECT() *MockContextSessionHandlerMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method
+func (m *MockContextSessionHandler) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close
+func (mr *MockContextSessionHandlerMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockContextSessionHandler)(nil).Close))
+}
+
+// Read mocks base method
+func (m *MockContextSessionHandler) Read(sessionID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", sessionID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read
+func (mr *MockContextSessionHandlerMockRecorder) Read(sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockContextSessionHandler)(nil).Read), sessionID)
+}
+
+// Write mocks base method
+func (m *MockContextSessionHandler) Write(sessionID, sessionData string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", sessionID, sessionData)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write
+func (mr *MockContextSessionHandlerMockRecorder) Write(sessionID, sessionData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockContextSessionHandler)(nil).Write), sessionID, sessionData)
+}
+
+// ReadContext mocks base method
+func (m *MockContextSessionHandler) ReadContext(ctx context.Context, sessionID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadContext", ctx, sessionID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadContext indicates an expected call of ReadContext
+func (mr *MockContextSessionHandlerMockRecorder) ReadContext(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadContext", reflect.TypeOf((*MockContextSessionHandler)(nil).ReadContext), ctx, sessionID)
+}
+
+// WriteContext mocks base method
+func (m *MockContextSessionHandler) WriteContext(ctx context.Context, sessionID, sessionData string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteContext", ctx, sessionID, sessionData)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteContext indicates an expected call of WriteContext
+func (mr *MockContextSessionHandlerMockRecorder) WriteContext(ctx, sessionID, sessionData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteContext", reflect.TypeOf((*MockContextSessionHandler)(nil).WriteContext), ctx, sessionID, sessionData)
+}
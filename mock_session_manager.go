@@ -5,6 +5,7 @@
 package phpsessgo
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	http "net/http"
 	reflect "reflect"
@@ -48,6 +49,21 @@ func (mr *MockSessionManagerMockRecorder) Start(w, r interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockSessionManager)(nil).Start), w, r)
 }
 
+// StartContext mocks base method
+func (m *MockSessionManager) StartContext(ctx context.Context, w http.ResponseWriter, r *http.Request) (*Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartContext", ctx, w, r)
+	ret0, _ := ret[0].(*Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartContext indicates an expected call of StartContext
+func (mr *MockSessionManagerMockRecorder) StartContext(ctx, w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartContext", reflect.TypeOf((*MockSessionManager)(nil).StartContext), ctx, w, r)
+}
+
 // Save mocks base method
 func (m *MockSessionManager) Save(session *Session) error {
 	m.ctrl.T.Helper()
@@ -62,6 +78,20 @@ func (mr *MockSessionManagerMockRecorder) Save(session interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSessionManager)(nil).Save), session)
 }
 
+// SaveContext mocks base method
+func (m *MockSessionManager) SaveContext(ctx context.Context, session *Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveContext", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveContext indicates an expected call of SaveContext
+func (mr *MockSessionManagerMockRecorder) SaveContext(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveContext", reflect.TypeOf((*MockSessionManager)(nil).SaveContext), ctx, session)
+}
+
 // SessionName mocks base method
 func (m *MockSessionManager) SessionName() string {
 	m.ctrl.T.Helper()
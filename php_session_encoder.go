@@ -1,6 +1,9 @@
 package phpsessgo
 
-import "github.com/eligundry/phpsessgo/phpencode"
+import (
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phptype"
+)
 
 type PHPSessionEncoder struct {
 	SessionEncoder
@@ -16,3 +19,80 @@ func (e *PHPSessionEncoder) Decode(raw string) (phpencode.PhpSession, error) {
 	decoder := phpencode.NewPhpDecoder(raw)
 	return decoder.Decode()
 }
+
+func (e *PHPSessionEncoder) EncodeOrdered(session *phptype.OrderedArray) (string, error) {
+	variables, order, err := sessionVariables(session)
+	if err != nil {
+		return "", err
+	}
+	encoder := phpencode.NewPhpEncoder(variables)
+	encoder.SetOrder(order)
+	return encoder.Encode()
+}
+
+func (e *PHPSessionEncoder) DecodeOrdered(raw string) (*phptype.OrderedArray, error) {
+	decoder := phpencode.NewPhpDecoder(raw)
+	decoder.SetOrdered(true)
+	session, err := decoder.Decode()
+	return orderedSession(session, decoder.Order()), err
+}
+
+// PHPBinarySessionEncoder encode session like session.serialize_handler=php_binary
+type PHPBinarySessionEncoder struct {
+	SessionEncoder
+}
+
+func (e *PHPBinarySessionEncoder) Encode(session phpencode.PhpSession) (string, error) {
+	return phpencode.NewPhpBinaryEncoder(session).Encode()
+}
+
+func (e *PHPBinarySessionEncoder) Decode(raw string) (phpencode.PhpSession, error) {
+	return phpencode.NewPhpBinaryDecoder(raw).Decode()
+}
+
+func (e *PHPBinarySessionEncoder) EncodeOrdered(session *phptype.OrderedArray) (string, error) {
+	variables, order, err := sessionVariables(session)
+	if err != nil {
+		return "", err
+	}
+	encoder := phpencode.NewPhpBinaryEncoder(variables)
+	encoder.SetOrder(order)
+	return encoder.Encode()
+}
+
+func (e *PHPBinarySessionEncoder) DecodeOrdered(raw string) (*phptype.OrderedArray, error) {
+	decoder := phpencode.NewPhpBinaryDecoder(raw)
+	decoder.SetOrdered(true)
+	session, err := decoder.Decode()
+	return orderedSession(session, decoder.Order()), err
+}
+
+// PHPSerializeSessionEncoder encode session like session.serialize_handler=php_serialize
+type PHPSerializeSessionEncoder struct {
+	SessionEncoder
+}
+
+func (e *PHPSerializeSessionEncoder) Encode(session phpencode.PhpSession) (string, error) {
+	return phpencode.NewPhpSerializeEncoder(session).Encode()
+}
+
+func (e *PHPSerializeSessionEncoder) Decode(raw string) (phpencode.PhpSession, error) {
+	return phpencode.NewPhpSerializeDecoder(raw).Decode()
+}
+
+func (e *PHPSerializeSessionEncoder) EncodeOrdered(session *phptype.OrderedArray) (string, error) {
+	variables, order, err := sessionVariables(session)
+	if err != nil {
+		return "", err
+	}
+	encoder := phpencode.NewPhpSerializeEncoder(variables)
+	encoder.SetOrder(order)
+	return encoder.Encode()
+}
+
+func (e *PHPSerializeSessionEncoder) DecodeOrdered(raw string) (*phptype.OrderedArray, error) {
+	decoder := phpencode.NewPhpSerializeDecoder(raw)
+	decoder.SetOrdered(true)
+	session, err := decoder.Decode()
+	return orderedSession(session, decoder.Order()), err
+}
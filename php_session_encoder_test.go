@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phptype"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,3 +21,134 @@ func TestPHPSessionEncoder(t *testing.T) {
 	require.Equal(t, len(raw), len(encoded))
 
 }
+
+func TestNewSessionEncoder(t *testing.T) {
+	session := phpencode.PhpSession{"login_ok": true, "cart": phptype.Array{0: "apple"}}
+
+	for _, name := range []string{"php", "php_binary", "php_serialize", "json"} {
+		encoder, err := phpsessgo.NewSessionEncoder(name)
+		require.NoError(t, err)
+
+		raw, err := encoder.Encode(session)
+		require.NoError(t, err)
+		decoded, err := encoder.Decode(raw)
+		require.NoError(t, err)
+		require.Equal(t, session, decoded, name)
+	}
+
+	_, err := phpsessgo.NewSessionEncoder("wddx")
+	require.Error(t, err)
+}
+
+func TestJSONSessionEncoder(t *testing.T) {
+	encoder := &phpsessgo.JSONSessionEncoder{}
+
+	user := phptype.NewObject("User")
+	user.SetPublic("name", "bob")
+	raw, err := encoder.Encode(phpencode.PhpSession{
+		"user":  user,
+		"cart":  phptype.Array{0: "apple", 1: "pear"},
+		"flags": phptype.Array{"a": 1.5, 10: nil},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"user": {"__class": "User", "name": "bob"},
+		"cart": ["apple", "pear"],
+		"flags": {"a": 1.5, "10": null}
+	}`, raw)
+
+	decoded, err := encoder.Decode(raw)
+	require.NoError(t, err)
+	require.Equal(t, phpencode.PhpSession{
+		"user":  user,
+		"cart":  phptype.Array{0: "apple", 1: "pear"},
+		"flags": phptype.Array{"a": 1.5, 10: nil},
+	}, decoded)
+
+	_, err = encoder.Decode("[1, 2]")
+	require.Error(t, err)
+}
+
+func TestJSONSessionEncoder_Tagged(t *testing.T) {
+	encoder := &phpsessgo.JSONSessionEncoder{Tagged: true}
+
+	user := phptype.NewObject("User")
+	user.SetPrivate("id", 7)
+	session := phpencode.PhpSession{
+		"user":  user,
+		"price": 9.0,
+		"cart":  phptype.Array{"5": "apple", 5: "pear"},
+	}
+
+	raw, err := encoder.Encode(session)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"user": {"object": "User", "members": [{"name": "id", "value": 7, "visibility": "private"}]},
+		"price": 9.0,
+		"cart": {"array": [[5, "pear"], ["5", "apple"]]}
+	}`, raw)
+	require.Contains(t, raw, `"price":9.0`)
+
+	decoded, err := encoder.Decode(raw)
+	require.NoError(t, err)
+	require.Equal(t, session, decoded)
+
+	_, err = encoder.Decode(`{"cart": [1, 2]}`)
+	require.Error(t, err)
+}
+
+func TestOrderedSessionEncoder(t *testing.T) {
+	raws := map[string]string{
+		"php":           `z|i:1;cart|a:3:{i:9;s:1:"c";i:1;s:1:"a";s:1:"k";s:1:"b";}user|O:4:"User":2:{s:4:"name";s:3:"bob";s:5:"email";s:0:"";}`,
+		"php_binary":    "\x01zi:1;\x04carta:3:{i:9;s:1:\"c\";i:1;s:1:\"a\";s:1:\"k\";s:1:\"b\";}",
+		"php_serialize": `a:2:{s:1:"z";i:1;s:4:"cart";a:3:{i:9;s:1:"c";i:1;s:1:"a";s:1:"k";s:1:"b";}}`,
+	}
+
+	for name, raw := range raws {
+		encoder, err := phpsessgo.NewSessionEncoder(name)
+		require.NoError(t, err)
+		ordered, ok := encoder.(phpsessgo.OrderedSessionEncoder)
+		require.True(t, ok, name)
+
+		session, err := ordered.DecodeOrdered(raw)
+		require.NoError(t, err, name)
+		keys, err := session.Keys()
+		require.NoError(t, err)
+		require.Equal(t, "z", keys[0], name)
+		cart, ok := session.Array["cart"].(*phptype.OrderedArray)
+		require.True(t, ok, name)
+		keys, err = cart.Keys()
+		require.NoError(t, err)
+		require.Equal(t, []phptype.Value{9, 1, "k"}, keys, name)
+
+		encoded, err := ordered.EncodeOrdered(session)
+		require.NoError(t, err)
+		require.Equal(t, raw, encoded, name)
+
+		// through the tagged JSON and back
+		tagged := &phpsessgo.JSONSessionEncoder{Tagged: true}
+		data, err := tagged.EncodeOrdered(session)
+		require.NoError(t, err)
+		decoded, err := tagged.DecodeOrdered(data)
+		require.NoError(t, err)
+		require.Equal(t, session, decoded, name)
+		encoded, err = ordered.EncodeOrdered(decoded)
+		require.NoError(t, err)
+		require.Equal(t, raw, encoded, name)
+	}
+
+	untagged := &phpsessgo.JSONSessionEncoder{}
+	session, err := untagged.DecodeOrdered(`{"z": 1, "cart": {"b": 2, "a": 1}}`)
+	require.NoError(t, err)
+	keys, err := session.Keys()
+	require.NoError(t, err)
+	require.Equal(t, []phptype.Value{"z", "cart"}, keys)
+	raw, err := untagged.EncodeOrdered(session)
+	require.NoError(t, err)
+	require.Equal(t, `{"z":1,"cart":{"a":1,"b":2}}`, raw)
+
+	_, err = untagged.DecodeOrdered(`[1, 2]`)
+	require.Error(t, err)
+	_, err = untagged.EncodeOrdered(phptype.NewOrderedArray().Set(5, 1).Set("5", 2))
+	require.Error(t, err)
+}
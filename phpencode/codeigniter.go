@@ -0,0 +1,114 @@
+package phpencode
+
+import (
+	"sort"
+	"time"
+
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+// CodeIgniterVarsKey is session key where CodeIgniter 3 mark flashdata and tempdata
+const CodeIgniterVarsKey = "__ci_vars"
+
+const (
+	codeIgniterFlashNew = "new"
+	codeIgniterFlashOld = "old"
+)
+
+// CodeIgniterInitVars is adoption of CI_Session::_ci_init_vars(), call it once per request
+// after the session is read. New flashdata become old, old flashdata and expired tempdata are removed
+func CodeIgniterInitVars(session PhpSession, now time.Time) {
+	vars, ok := session[CodeIgniterVarsKey].(phptype.Array)
+	if !ok {
+		return
+	}
+
+	for key, mark := range vars {
+		name, _ := key.(string)
+		switch mark {
+		case codeIgniterFlashNew:
+			vars[key] = codeIgniterFlashOld
+		case codeIgniterFlashOld:
+			delete(session, name)
+			delete(vars, key)
+		default:
+			if expiresAt, ok := mark.(int); ok && int64(expiresAt) < now.Unix() {
+				delete(session, name)
+				delete(vars, key)
+			}
+		}
+	}
+
+	if len(vars) == 0 {
+		delete(session, CodeIgniterVarsKey)
+	}
+}
+
+// CodeIgniterMarkAsFlash is adoption of CI_Session::mark_as_flash(), the keys must exist in the session
+func CodeIgniterMarkAsFlash(session PhpSession, keys ...string) bool {
+	return codeIgniterMark(session, codeIgniterFlashNew, keys)
+}
+
+// CodeIgniterMarkAsTemp is adoption of CI_Session::mark_as_temp(), the keys must exist in the session
+func CodeIgniterMarkAsTemp(session PhpSession, ttl time.Duration, now time.Time, keys ...string) bool {
+	return codeIgniterMark(session, int(now.Add(ttl).Unix()), keys)
+}
+
+// CodeIgniterUnmark remove flashdata or tempdata mark of the keys, keeping their values
+func CodeIgniterUnmark(session PhpSession, keys ...string) {
+	vars, ok := session[CodeIgniterVarsKey].(phptype.Array)
+	if !ok {
+		return
+	}
+	for _, key := range keys {
+		delete(vars, key)
+	}
+	if len(vars) == 0 {
+		delete(session, CodeIgniterVarsKey)
+	}
+}
+
+// CodeIgniterFlashKeys is adoption of CI_Session::get_flash_keys()
+func CodeIgniterFlashKeys(session PhpSession) []string {
+	return codeIgniterKeys(session, func(mark phptype.Value) bool {
+		_, ok := mark.(string)
+		return ok
+	})
+}
+
+// CodeIgniterTempKeys is adoption of CI_Session::get_temp_keys()
+func CodeIgniterTempKeys(session PhpSession) []string {
+	return codeIgniterKeys(session, func(mark phptype.Value) bool {
+		_, ok := mark.(int)
+		return ok
+	})
+}
+
+func codeIgniterMark(session PhpSession, mark phptype.Value, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := session[key]; !ok {
+			return false
+		}
+	}
+
+	vars, ok := session[CodeIgniterVarsKey].(phptype.Array)
+	if !ok {
+		vars = phptype.Array{}
+		session[CodeIgniterVarsKey] = vars
+	}
+	for _, key := range keys {
+		vars[key] = mark
+	}
+	return true
+}
+
+func codeIgniterKeys(session PhpSession, match func(phptype.Value) bool) (keys []string) {
+	vars, _ := session[CodeIgniterVarsKey].(phptype.Array)
+	for key, mark := range vars {
+		if name, ok := key.(string); ok && match(mark) {
+			keys = append(keys, name)
+		}
+	}
+	sort.Strings(keys)
+	return
+}
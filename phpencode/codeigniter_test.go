@@ -0,0 +1,71 @@
+package phpencode
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+func TestCodeIgniterInitVars(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	session, err := NewPhpDecoder(`user|s:4:"john";notice|s:5:"Saved";` +
+		`error|s:4:"Oops";otp|s:6:"123456";token|s:3:"abc";` +
+		`__ci_vars|a:4:{s:6:"notice";s:3:"new";s:5:"error";s:3:"old";s:3:"otp";i:1599999999;s:5:"token";i:1600000300;}`).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	CodeIgniterInitVars(session, now)
+
+	expected := PhpSession{
+		"user":   "john",
+		"notice": "Saved",
+		"token":  "abc",
+		"__ci_vars": phptype.Array{
+			"notice": "old",
+			"token":  1600000300,
+		},
+	}
+	if !reflect.DeepEqual(expected, session) {
+		t.Errorf("Unexpected session after init %#v", session)
+	}
+
+	CodeIgniterInitVars(session, now.Add(time.Hour))
+	if !reflect.DeepEqual(PhpSession{"user": "john"}, session) {
+		t.Errorf("Flashdata and tempdata were not removed %#v", session)
+	}
+}
+
+func TestCodeIgniterMark(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	session := PhpSession{"notice": "Saved", "otp": "123456", "user": "john"}
+
+	if CodeIgniterMarkAsFlash(session, "notice", "missing") {
+		t.Errorf("Missing key was marked as flashdata")
+	}
+	if _, ok := session[CodeIgniterVarsKey]; ok {
+		t.Errorf("Vars were created for missing key")
+	}
+
+	if !CodeIgniterMarkAsFlash(session, "notice") || !CodeIgniterMarkAsTemp(session, 5*time.Minute, now, "otp", "user") {
+		t.Fatalf("Existing keys were not marked")
+	}
+	if keys := CodeIgniterFlashKeys(session); !reflect.DeepEqual([]string{"notice"}, keys) {
+		t.Errorf("Unexpected flash keys %v", keys)
+	}
+	if keys := CodeIgniterTempKeys(session); !reflect.DeepEqual([]string{"otp", "user"}, keys) {
+		t.Errorf("Unexpected temp keys %v", keys)
+	}
+
+	encoded, err := NewPhpEncoder(PhpSession{CodeIgniterVarsKey: phptype.Array{"otp": session[CodeIgniterVarsKey].(phptype.Array)["otp"]}}).Encode()
+	if err != nil || encoded != `__ci_vars|a:1:{s:3:"otp";i:1600000300;}` {
+		t.Errorf("Unexpected tempdata mark %q %v", encoded, err)
+	}
+
+	CodeIgniterUnmark(session, "notice", "otp", "user")
+	if _, ok := session[CodeIgniterVarsKey]; ok || session["notice"] != "Saved" {
+		t.Errorf("Unexpected session after unmark %#v", session)
+	}
+}
@@ -12,6 +12,8 @@ import (
 type PhpDecoder struct {
 	source  *strings.Reader
 	decoder *phpserialize.Unserializer
+	ordered bool
+	order   []string
 }
 
 func NewPhpDecoder(phpSession string) *PhpDecoder {
@@ -27,6 +29,17 @@ func (self *PhpDecoder) SetDecodeFunc(f phpserialize.DecodeFunc) {
 	self.decoder.SetDecodeFunc(f)
 }
 
+// SetOrdered decode arrays as *phptype.OrderedArray and record the order of the variables, see Order
+func (self *PhpDecoder) SetOrdered(ordered bool) {
+	self.ordered = ordered
+	self.decoder.SetOrdered(ordered)
+}
+
+// Order return the variable names in the order of the data decoded with SetOrdered
+func (self *PhpDecoder) Order() []string {
+	return self.order
+}
+
 func (self *PhpDecoder) Decode() (PhpSession, error) {
 	var (
 		name  string
@@ -42,6 +55,9 @@ func (self *PhpDecoder) Decode() (PhpSession, error) {
 		if value, err = self.decoder.Decode(); err != nil {
 			break
 		}
+		if _, ok := res[name]; !ok && self.ordered {
+			self.order = append(self.order, name)
+		}
 		res[name] = value
 	}
 
@@ -9,6 +9,7 @@ import (
 
 type PhpEncoder struct {
 	data    PhpSession
+	order   []string
 	encoder *phpserialize.Serializer
 }
 
@@ -23,6 +24,11 @@ func (self *PhpEncoder) SetEncodeFunc(f phpserialize.EncodeFunc) {
 	self.encoder.SetEncodeFunc(f)
 }
 
+// SetOrder of the variables, the variables missing from order are encoded after them in byte order
+func (self *PhpEncoder) SetOrder(order []string) {
+	self.order = order
+}
+
 func (self *PhpEncoder) Encode() (string, error) {
 	if self.data == nil {
 		return "", nil
@@ -33,10 +39,10 @@ func (self *PhpEncoder) Encode() (string, error) {
 	)
 	buf := bytes.NewBuffer([]byte{})
 
-	for k, v := range self.data {
+	for _, k := range sessionNames(self.data, self.order) {
 		buf.WriteString(k)
 		buf.WriteRune(SEPARATOR_VALUE_NAME)
-		if val, err = self.encoder.Encode(v); err != nil {
+		if val, err = self.encoder.Encode(self.data[k]); err != nil {
 			err = fmt.Errorf("php_session: error during encode value for %q: %v", k, err)
 			break
 		}
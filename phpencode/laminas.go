@@ -0,0 +1,232 @@
+package phpencode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/eligundry/phpsessgo/phpserialize"
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+// LaminasMetadataKey is session key of Zend Framework 2 and Laminas session metadata
+const LaminasMetadataKey = "__ZF"
+
+const (
+	laminasRequestAccessTime = "_REQUEST_ACCESS_TIME"
+	laminasExpire            = "EXPIRE"
+	laminasExpireKeys        = "EXPIRE_KEYS"
+	laminasExpireHops        = "EXPIRE_HOPS"
+	laminasExpireHopsKeys    = "EXPIRE_HOPS_KEYS"
+)
+
+// LaminasSession maintain container expiration metadata of Laminas\Session\Container.
+// Container data may be array, Laminas\Stdlib\ArrayObject serialized with __serialize()
+// or with Serializable, all of them are kept in their original form
+type LaminasSession struct {
+	Session PhpSession
+	// RequestTime is $_SERVER['REQUEST_TIME'] compared with expiration seconds
+	// and the request access time compared with hop timestamps
+	RequestTime time.Time
+}
+
+// NewLaminasSession create helper for the session read in request of the given time,
+// the request access time is recorded like SessionArrayStorage::setRequestAccessTime()
+func NewLaminasSession(session PhpSession, requestTime time.Time) *LaminasSession {
+	s := &LaminasSession{Session: session, RequestTime: requestTime}
+	s.metadata()[laminasRequestAccessTime] = s.accessTime()
+	return s
+}
+
+// SetExpirationSeconds is adoption of Container::setExpirationSeconds(),
+// whole namespace expire when no key is given
+func (s *LaminasSession) SetExpirationSeconds(namespace string, ttl time.Duration, keys ...string) {
+	metadata := s.namespaceMetadata(namespace)
+	expires := s.accessTime() + ttl.Seconds()
+
+	if len(keys) == 0 {
+		metadata[laminasExpire] = expires
+		return
+	}
+	expireKeys := childArray(metadata, laminasExpireKeys)
+	for _, key := range keys {
+		expireKeys[key] = expires
+	}
+}
+
+// SetExpirationHops is adoption of Container::setExpirationHops(),
+// whole namespace expire when no key is given
+func (s *LaminasSession) SetExpirationHops(namespace string, hops int, keys ...string) {
+	metadata := s.namespaceMetadata(namespace)
+
+	if len(keys) == 0 {
+		metadata[laminasExpireHops] = s.hops(hops)
+		return
+	}
+	expireHopsKeys := childArray(metadata, laminasExpireHopsKeys)
+	for _, key := range keys {
+		expireHopsKeys[key] = s.hops(hops)
+	}
+}
+
+// Expire is adoption of Container::expireKeys() for every namespace with metadata,
+// call it once per request. It return the namespaces cleared as a whole
+func (s *LaminasSession) Expire() (cleared []string, err error) {
+	for name := range s.metadata() {
+		namespace, ok := name.(string)
+		if !ok || namespace == laminasRequestAccessTime {
+			continue
+		}
+		expired, err := s.ExpireNamespace(namespace)
+		if err != nil {
+			return cleared, err
+		}
+		if expired {
+			cleared = append(cleared, namespace)
+		}
+	}
+	return
+}
+
+// ExpireNamespace expire the namespace or its keys, it return true when the whole namespace is cleared
+func (s *LaminasSession) ExpireNamespace(namespace string) (bool, error) {
+	metadata, ok := s.metadata()[namespace].(phptype.Array)
+	if !ok {
+		return false, nil
+	}
+
+	// expireByExpiryTime()
+	if expires, ok := toFloat(metadata[laminasExpire]); ok && float64(s.RequestTime.Unix()) > expires {
+		delete(metadata, laminasExpire)
+		s.clear(namespace)
+		return true, nil
+	}
+
+	var expiredKeys []phptype.Value
+	if expireKeys, ok := metadata[laminasExpireKeys].(phptype.Array); ok {
+		for key, value := range expireKeys {
+			if expires, ok := toFloat(value); ok && float64(s.RequestTime.Unix()) > expires {
+				expiredKeys = append(expiredKeys, key)
+				delete(expireKeys, key)
+			}
+		}
+	}
+
+	// expireByHops()
+	if hops, ok := metadata[laminasExpireHops].(phptype.Array); ok {
+		if expired := s.hop(hops); expired {
+			delete(metadata, laminasExpireHops)
+			s.clear(namespace)
+			return true, nil
+		}
+	}
+
+	if hopsKeys, ok := metadata[laminasExpireHopsKeys].(phptype.Array); ok {
+		for key, value := range hopsKeys {
+			if hops, ok := value.(phptype.Array); ok && s.hop(hops) {
+				expiredKeys = append(expiredKeys, key)
+				delete(hopsKeys, key)
+			}
+		}
+	}
+
+	if len(expiredKeys) == 0 {
+		return false, nil
+	}
+	return false, s.removeKeys(namespace, expiredKeys)
+}
+
+// hop decrement the hops once per request, it return true when the hops are exhausted
+func (s *LaminasSession) hop(hops phptype.Array) bool {
+	ts, _ := toFloat(hops["ts"])
+	if s.accessTime() <= ts {
+		return false
+	}
+	remaining, _ := hops["hops"].(int)
+	remaining--
+	if remaining == -1 {
+		return true
+	}
+	hops["hops"] = remaining
+	hops["ts"] = s.accessTime()
+	return false
+}
+
+func (s *LaminasSession) hops(hops int) phptype.Array {
+	return phptype.Array{"hops": hops, "ts": s.accessTime()}
+}
+
+func (s *LaminasSession) accessTime() float64 {
+	return float64(s.RequestTime.UnixNano()) / float64(time.Second)
+}
+
+func (s *LaminasSession) metadata() phptype.Array {
+	metadata, ok := s.Session[LaminasMetadataKey].(phptype.Array)
+	if !ok {
+		metadata = phptype.Array{}
+		s.Session[LaminasMetadataKey] = metadata
+	}
+	return metadata
+}
+
+func (s *LaminasSession) namespaceMetadata(namespace string) phptype.Array {
+	return childArray(s.metadata(), namespace)
+}
+
+// clear remove the container data, Laminas create empty container on next access
+func (s *LaminasSession) clear(namespace string) {
+	delete(s.Session, namespace)
+}
+
+// removeKeys remove the keys from the container data in any of its serialized forms
+func (s *LaminasSession) removeKeys(namespace string, keys []phptype.Value) error {
+	switch container := s.Session[namespace].(type) {
+	case nil:
+		return nil
+	case phptype.Array:
+		deleteKeys(container, keys)
+	case *phptype.Object:
+		storage, _ := container.Members["storage"].(phptype.Array)
+		deleteKeys(storage, keys)
+	case *phptype.ObjectSerialized:
+		value, err := phpserialize.UnSerialize(container.Data)
+		if err != nil {
+			return err
+		}
+		vars, _ := value.(phptype.Array)
+		storage, _ := vars["storage"].(phptype.Array)
+		deleteKeys(storage, keys)
+		if container.Data, err = phpserialize.Serialize(vars); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("phpencode: unsupported laminas container %T", container)
+	}
+	return nil
+}
+
+func deleteKeys(array phptype.Array, keys []phptype.Value) {
+	for _, key := range keys {
+		delete(array, key)
+	}
+}
+
+func childArray(parent phptype.Array, key string) phptype.Array {
+	child, ok := parent[key].(phptype.Array)
+	if !ok {
+		child = phptype.Array{}
+		parent[key] = child
+	}
+	return child
+}
+
+func toFloat(v phptype.Value) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package phpencode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+func TestLaminasSession_ExpirationSeconds(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	session := PhpSession{
+		"auth":  phptype.Array{"user": "john"},
+		"csrf":  phptype.Array{"token": "abc", "hash": "def"},
+		"other": "value",
+	}
+
+	laminas := NewLaminasSession(session, start)
+	laminas.SetExpirationSeconds("auth", time.Minute)
+	laminas.SetExpirationSeconds("csrf", 10*time.Second, "token")
+
+	laminas = NewLaminasSession(session, start.Add(30*time.Second))
+	cleared, err := laminas.Expire()
+	if err != nil || len(cleared) != 0 {
+		t.Fatalf("Unexpected expiration %v %v", cleared, err)
+	}
+	if !reflect.DeepEqual(phptype.Array{"hash": "def"}, session["csrf"]) {
+		t.Errorf("Expired key was not removed %#v", session["csrf"])
+	}
+
+	laminas = NewLaminasSession(session, start.Add(2*time.Minute))
+	if cleared, err = laminas.Expire(); err != nil || !reflect.DeepEqual([]string{"auth"}, cleared) {
+		t.Errorf("Namespace was not cleared %v %v", cleared, err)
+	}
+	if _, ok := session["auth"]; ok || session["other"] != "value" {
+		t.Errorf("Unexpected session %#v", session)
+	}
+}
+
+func TestLaminasSession_ExpirationHops(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	session, err := NewPhpDecoder(`wizard|a:1:{s:4:"step";i:2;}` +
+		`flash|O:26:"Laminas\Stdlib\ArrayObject":4:{s:7:"storage";a:2:{s:7:"message";s:5:"Saved";s:4:"keep";b:1;}s:4:"flag";i:2;s:13:"iteratorClass";s:13:"ArrayIterator";s:19:"protectedProperties";a:0:{}}` +
+		`legacy|C:26:"Laminas\Stdlib\ArrayObject":140:{a:4:{s:7:"storage";a:2:{s:1:"a";i:1;s:1:"b";i:2;}s:4:"flag";i:2;s:13:"iteratorClass";s:13:"ArrayIterator";s:19:"protectedProperties";a:0:{}}}`).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	laminas := NewLaminasSession(session, start)
+	laminas.SetExpirationHops("wizard", 1)
+	laminas.SetExpirationHops("flash", 0, "message")
+	laminas.SetExpirationHops("legacy", 0, "a")
+
+	// the hops are decremented once per request
+	for i := 0; i < 2; i++ {
+		if _, err := NewLaminasSession(session, start.Add(time.Second)).Expire(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok := session["wizard"]; !ok {
+		t.Errorf("Namespace expired before its hops")
+	}
+	flash := session["flash"].(*phptype.Object)
+	if !reflect.DeepEqual(phptype.Array{"keep": true}, flash.Members["storage"]) {
+		t.Errorf("Expired key was not removed %#v", flash.Members["storage"])
+	}
+	legacy := session["legacy"].(*phptype.ObjectSerialized)
+	if !strings.Contains(legacy.Data, `s:7:"storage";a:1:{s:1:"b";i:2;}`) {
+		t.Errorf("Expired key was not removed from serialized container %q", legacy.Data)
+	}
+
+	cleared, err := NewLaminasSession(session, start.Add(2*time.Second)).Expire()
+	if err != nil || !reflect.DeepEqual([]string{"wizard"}, cleared) {
+		t.Errorf("Namespace was not cleared %v %v", cleared, err)
+	}
+}
@@ -0,0 +1,128 @@
+package phpencode
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/eligundry/phpsessgo/phpserialize"
+)
+
+const (
+	// PHP_BINARY_MAX is the longest variable name php_binary can store
+	PHP_BINARY_MAX = 127
+	// PHP_BINARY_UNDEF flag the length byte of variable without value
+	PHP_BINARY_UNDEF byte = 128
+)
+
+// PhpBinaryDecoder decode session.serialize_handler=php_binary data,
+// each variable is a length byte, the name and the serialized value
+type PhpBinaryDecoder struct {
+	source  *strings.Reader
+	decoder *phpserialize.Unserializer
+	ordered bool
+	order   []string
+}
+
+func NewPhpBinaryDecoder(phpSession string) *PhpBinaryDecoder {
+	decoder := &PhpBinaryDecoder{
+		source:  strings.NewReader(phpSession),
+		decoder: phpserialize.NewUnserializer(""),
+	}
+	decoder.decoder.SetReader(decoder.source)
+	return decoder
+}
+
+func (self *PhpBinaryDecoder) SetDecodeFunc(f phpserialize.DecodeFunc) {
+	self.decoder.SetDecodeFunc(f)
+}
+
+// SetOrdered decode arrays as *phptype.OrderedArray and record the order of the variables, see Order
+func (self *PhpBinaryDecoder) SetOrdered(ordered bool) {
+	self.ordered = ordered
+	self.decoder.SetOrdered(ordered)
+}
+
+// Order return the variable names in the order of the data decoded with SetOrdered
+func (self *PhpBinaryDecoder) Order() []string {
+	return self.order
+}
+
+func (self *PhpBinaryDecoder) Decode() (PhpSession, error) {
+	res := make(PhpSession)
+
+	for {
+		length, err := self.source.ReadByte()
+		if err == io.EOF {
+			return res, nil
+		}
+
+		name := make([]byte, length&^PHP_BINARY_UNDEF)
+		if _, err = io.ReadFull(self.source, name); err != nil {
+			return res, fmt.Errorf("php_binary: truncated variable name: %v", err)
+		}
+
+		// undefined variable is skipped like PHP does
+		if length&PHP_BINARY_UNDEF != 0 {
+			continue
+		}
+
+		if self.source.Len() == 0 {
+			return res, fmt.Errorf("php_binary: missing value of %q", name)
+		}
+		value, err := self.decoder.Decode()
+		if err != nil {
+			return res, err
+		}
+		if _, ok := res[string(name)]; !ok && self.ordered {
+			self.order = append(self.order, string(name))
+		}
+		res[string(name)] = value
+	}
+}
+
+// PhpBinaryEncoder encode session.serialize_handler=php_binary data
+type PhpBinaryEncoder struct {
+	data    PhpSession
+	order   []string
+	encoder *phpserialize.Serializer
+}
+
+func NewPhpBinaryEncoder(data PhpSession) *PhpBinaryEncoder {
+	return &PhpBinaryEncoder{
+		data:    data,
+		encoder: phpserialize.NewSerializer(),
+	}
+}
+
+func (self *PhpBinaryEncoder) SetEncodeFunc(f phpserialize.EncodeFunc) {
+	self.encoder.SetEncodeFunc(f)
+}
+
+// SetOrder of the variables, the variables missing from order are encoded after them in byte order
+func (self *PhpBinaryEncoder) SetOrder(order []string) {
+	self.order = order
+}
+
+// Encode the session, PHP silently drop variables with name longer than PHP_BINARY_MAX,
+// here it is an error so the data is not lost
+func (self *PhpBinaryEncoder) Encode() (string, error) {
+	buf := bytes.NewBuffer([]byte{})
+
+	for _, k := range sessionNames(self.data, self.order) {
+		v := self.data[k]
+		if len(k) > PHP_BINARY_MAX {
+			return "", fmt.Errorf("php_binary: variable name %q longer than %d bytes", k, PHP_BINARY_MAX)
+		}
+		val, err := self.encoder.Encode(v)
+		if err != nil {
+			return "", fmt.Errorf("php_binary: error during encode value for %q: %v", k, err)
+		}
+		buf.WriteByte(byte(len(k)))
+		buf.WriteString(k)
+		buf.WriteString(val)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,61 @@
+package phpencode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+func TestDecodePhpBinary(t *testing.T) {
+	// php -d session.serialize_handler=php_binary: $_SESSION = ['login_ok' => true, 'cart' => [1, 2]]
+	raw := "\x08login_okb:1;\x04carta:2:{i:0;i:1;i:1;i:2;}"
+
+	result, err := NewPhpBinaryDecoder(raw).Decode()
+	if err != nil {
+		t.Fatalf("Can not decode php_binary session %v \n", err)
+	}
+
+	expected := PhpSession{
+		"login_ok": true,
+		"cart":     phptype.Array{0: 1, 1: 2},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("php_binary session was decoded incorrectly %#v \n", result)
+	}
+}
+
+func TestDecodePhpBinaryUndefined(t *testing.T) {
+	raw := "\x83foo\x03bari:1;"
+
+	result, err := NewPhpBinaryDecoder(raw).Decode()
+	if err != nil {
+		t.Fatalf("Can not decode php_binary session %v \n", err)
+	}
+	if !reflect.DeepEqual(result, PhpSession{"bar": 1}) {
+		t.Errorf("Undefined variable was not skipped %#v \n", result)
+	}
+}
+
+func TestDecodePhpBinaryTruncated(t *testing.T) {
+	for _, raw := range []string{"\x08login", "\x03foo"} {
+		if _, err := NewPhpBinaryDecoder(raw).Decode(); err == nil {
+			t.Errorf("Truncated php_binary session %q was decoded \n", raw)
+		}
+	}
+}
+
+func TestEncodePhpBinary(t *testing.T) {
+	result, err := NewPhpBinaryEncoder(PhpSession{"login_ok": true}).Encode()
+	if err != nil {
+		t.Fatalf("Can not encode php_binary session %v \n", err)
+	}
+	if result != "\x08login_okb:1;" {
+		t.Errorf("php_binary session was encoded incorrectly %q \n", result)
+	}
+
+	if _, err := NewPhpBinaryEncoder(PhpSession{strings.Repeat("a", 128): 1}).Encode(); err == nil {
+		t.Errorf("Too long variable name was encoded \n")
+	}
+}
@@ -0,0 +1,101 @@
+package phpencode
+
+import (
+	"fmt"
+
+	"github.com/eligundry/phpsessgo/phpserialize"
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+// PhpSerializeDecoder decode session.serialize_handler=php_serialize data,
+// which is serialize() of the whole $_SESSION array
+type PhpSerializeDecoder struct {
+	decoder *phpserialize.Unserializer
+	empty   bool
+	ordered bool
+	order   []string
+}
+
+func NewPhpSerializeDecoder(phpSession string) *PhpSerializeDecoder {
+	return &PhpSerializeDecoder{
+		decoder: phpserialize.NewUnserializer(phpSession),
+		empty:   phpSession == "",
+	}
+}
+
+func (self *PhpSerializeDecoder) SetDecodeFunc(f phpserialize.DecodeFunc) {
+	self.decoder.SetDecodeFunc(f)
+}
+
+// SetOrdered decode arrays as *phptype.OrderedArray and record the order of the variables, see Order
+func (self *PhpSerializeDecoder) SetOrdered(ordered bool) {
+	self.ordered = ordered
+	self.decoder.SetOrdered(ordered)
+}
+
+// Order return the variable names in the order of the data decoded with SetOrdered
+func (self *PhpSerializeDecoder) Order() []string {
+	return self.order
+}
+
+func (self *PhpSerializeDecoder) Decode() (PhpSession, error) {
+	res := make(PhpSession)
+	if self.empty {
+		return res, nil
+	}
+
+	value, err := self.decoder.Decode()
+	if err != nil {
+		return res, err
+	}
+
+	var keys []phptype.Value
+	array, ok := value.(phptype.Array)
+	if ordered, isOrdered := value.(*phptype.OrderedArray); isOrdered {
+		array, ok = ordered.Array, true
+		if keys, err = ordered.Keys(); err != nil {
+			return res, fmt.Errorf("php_serialize: %v", err)
+		}
+	}
+	if !ok {
+		return res, fmt.Errorf("php_serialize: session data is %T, not array", value)
+	}
+	for k, v := range array {
+		res[fmt.Sprint(k)] = v
+	}
+	for _, k := range keys {
+		self.order = append(self.order, fmt.Sprint(k))
+	}
+	return res, nil
+}
+
+// PhpSerializeEncoder encode session.serialize_handler=php_serialize data
+type PhpSerializeEncoder struct {
+	data    PhpSession
+	order   []string
+	encoder *phpserialize.Serializer
+}
+
+func NewPhpSerializeEncoder(data PhpSession) *PhpSerializeEncoder {
+	return &PhpSerializeEncoder{
+		data:    data,
+		encoder: phpserialize.NewSerializer(),
+	}
+}
+
+func (self *PhpSerializeEncoder) SetEncodeFunc(f phpserialize.EncodeFunc) {
+	self.encoder.SetEncodeFunc(f)
+}
+
+// SetOrder of the variables, the variables missing from order are encoded after them in byte order
+func (self *PhpSerializeEncoder) SetOrder(order []string) {
+	self.order = order
+}
+
+func (self *PhpSerializeEncoder) Encode() (string, error) {
+	array := &phptype.OrderedArray{Array: make(phptype.Array, len(self.data))}
+	for _, k := range sessionNames(self.data, self.order) {
+		array.Set(k, self.data[k])
+	}
+	return self.encoder.Encode(array)
+}
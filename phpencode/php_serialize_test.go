@@ -0,0 +1,39 @@
+package phpencode
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+func TestPhpSerializeRoundTrip(t *testing.T) {
+	raw := `a:2:{s:8:"login_ok";b:1;s:4:"cart";a:1:{i:0;s:5:"apple";}}`
+
+	result, err := NewPhpSerializeDecoder(raw).Decode()
+	if err != nil {
+		t.Fatalf("Can not decode php_serialize session %v \n", err)
+	}
+	expected := PhpSession{
+		"login_ok": true,
+		"cart":     phptype.Array{0: "apple"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("php_serialize session was decoded incorrectly %#v \n", result)
+	}
+
+	encoded, err := NewPhpSerializeEncoder(PhpSession{"login_ok": true}).Encode()
+	if err != nil {
+		t.Fatalf("Can not encode php_serialize session %v \n", err)
+	}
+	if encoded != `a:1:{s:8:"login_ok";b:1;}` {
+		t.Errorf("php_serialize session was encoded incorrectly %q \n", encoded)
+	}
+
+	if result, err = NewPhpSerializeDecoder("").Decode(); err != nil || len(result) != 0 {
+		t.Errorf("Empty php_serialize session was decoded incorrectly %#v %v \n", result, err)
+	}
+	if _, err = NewPhpSerializeDecoder(`s:3:"foo";`).Decode(); err == nil {
+		t.Errorf("Non array php_serialize session was decoded \n")
+	}
+}
@@ -1,5 +1,30 @@
 package phpencode
 
-import "github.com/eligundry/phpsessgo/phptype"
+import (
+	"sort"
+
+	"github.com/eligundry/phpsessgo/phptype"
+)
 
 type PhpSession map[string]phptype.Value
+
+// sessionNames return the variable names in order, followed by the names missing from order in byte order
+func sessionNames(session PhpSession, order []string) []string {
+	names := make([]string, 0, len(session))
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		if _, ok := session[name]; ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	var rest []string
+	for name := range session {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
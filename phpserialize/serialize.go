@@ -41,7 +41,7 @@ func (self *Serializer) Encode(v phptype.Value) (string, error) {
 		value = self.encodeNumber(v)
 	case string:
 		value = self.encodeString(v, DELIMITER_STRING_LEFT, DELIMITER_STRING_RIGHT, true)
-	case phptype.Array, map[phptype.Value]phptype.Value, phptype.Slice:
+	case phptype.Array, map[phptype.Value]phptype.Value, *phptype.OrderedArray, phptype.Slice:
 		value = self.encodeArray(v, true)
 	case *phptype.Object:
 		value = self.encodeObject(v)
@@ -193,6 +193,23 @@ func (self *Serializer) encodeArray(v phptype.Value, isFinal bool) (buffer bytes
 			s, _ = self.Encode(v)
 			buffer.WriteString(s)
 		}
+	case *phptype.OrderedArray:
+		arrVal, _ := v.(*phptype.OrderedArray)
+		keys, err := arrVal.Keys()
+		if err != nil {
+			self.saveError(fmt.Errorf("phpserialize: %v", err))
+		}
+		arrLen = len(keys)
+
+		buffer.WriteString(self.prepareLen(arrLen))
+		buffer.WriteRune(DELIMITER_OBJECT_LEFT)
+
+		for _, k := range keys {
+			s, _ = self.Encode(k)
+			buffer.WriteString(s)
+			s, _ = self.Encode(arrVal.Array[k])
+			buffer.WriteString(s)
+		}
 	case phptype.Slice:
 		arrVal, _ := v.(phptype.Slice)
 		arrLen = len(arrVal)
@@ -217,7 +234,7 @@ func (self *Serializer) encodeObject(v phptype.Value) (buffer bytes.Buffer) {
 	obj, _ := v.(*phptype.Object)
 	buffer.WriteRune(TOKEN_OBJECT)
 	buffer.WriteString(self.prepareClassName(obj.ClassName))
-	encoded := self.encodeArray(obj.Members, false)
+	encoded := self.encodeArray(&phptype.OrderedArray{Array: obj.Members, Order: obj.Order}, false)
 	buffer.WriteString(encoded.String())
 	return
 }
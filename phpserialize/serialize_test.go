@@ -431,3 +431,22 @@ func TestEncodeSplArray(t *testing.T) {
 		t.Errorf("SplArray decoded incorrectly, expected: %q, got: %q\n", expected, data)
 	}
 }
+
+func TestEncodeOrdered(t *testing.T) {
+	expected := `a:3:{s:1:"z";i:1;i:9;a:2:{s:1:"b";i:2;s:1:"a";i:3;}s:1:"m";O:4:"User":2:{s:4:"name";s:3:"bob";s:5:"email";s:0:"";}}`
+
+	decoder := NewUnserializer(expected)
+	decoder.SetOrdered(true)
+	val, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("Error while decoding ordered array: %v\n", err)
+	}
+
+	data, err := Serialize(val)
+	if err != nil {
+		t.Errorf("Error while encoding ordered array: %v\n", err)
+	}
+	if data != expected {
+		t.Errorf("Ordered array encoded incorrectly, expected: %q, got: %q\n", expected, data)
+	}
+}
@@ -21,6 +21,7 @@ type Unserializer struct {
 	source     string
 	r          *strings.Reader
 	lastErr    error
+	ordered    bool
 	DecodeFunc DecodeFunc
 }
 
@@ -38,6 +39,12 @@ func (self *Unserializer) SetDecodeFunc(f DecodeFunc) {
 	self.DecodeFunc = f
 }
 
+// SetOrdered decode arrays as *phptype.OrderedArray keeping the order of the keys,
+// object members are always decoded with their order
+func (self *Unserializer) SetOrdered(ordered bool) {
+	self.ordered = ordered
+}
+
 func (self *Unserializer) Decode() (phptype.Value, error) {
 	if self.r == nil {
 		self.r = strings.NewReader(self.source)
@@ -132,7 +139,9 @@ func (self *Unserializer) decodeString(left, right rune, isFinal bool) phptype.V
 	strLen = self.readLen()
 	self.expect(left)
 
-	if strLen > 0 {
+	if strLen == 0 {
+		val = ""
+	} else {
 		buf := make([]byte, strLen, strLen)
 		if readLen, err = self.r.Read(buf); err != nil {
 			self.saveError(fmt.Errorf("phpserialize: Error while reading string value: %v", err))
@@ -153,8 +162,16 @@ func (self *Unserializer) decodeString(left, right rune, isFinal bool) phptype.V
 }
 
 func (self *Unserializer) decodeArray() phptype.Value {
+	array := self.decodeOrderedArray()
+	if self.ordered {
+		return array
+	}
+	return array.Array
+}
+
+func (self *Unserializer) decodeOrderedArray() *phptype.OrderedArray {
 	var arrLen int
-	val := make(phptype.Array)
+	val := phptype.NewOrderedArray()
 
 	arrLen = self.readLen()
 	self.expect(DELIMITER_OBJECT_LEFT)
@@ -164,7 +181,7 @@ func (self *Unserializer) decodeArray() phptype.Value {
 		v, errVal := self.Decode()
 
 		if errKey == nil && errVal == nil {
-			val[k] = v
+			val.Set(k, v)
 			/*switch t := k.(type) {
 			default:
 				self.saveError(fmt.Errorf("phpserialize: Unexpected key type %T", t))
@@ -189,8 +206,8 @@ func (self *Unserializer) decodeObject() phptype.Value {
 		ClassName: self.readClassName(),
 	}
 
-	rawMembers := self.decodeArray()
-	val.Members, _ = rawMembers.(phptype.Array)
+	members := self.decodeOrderedArray()
+	val.Members, val.Order = members.Array, members.Order
 
 	return val
 }
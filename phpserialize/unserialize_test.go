@@ -2,6 +2,7 @@ package phpserialize
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/eligundry/phpsessgo/phptype"
@@ -515,3 +516,36 @@ func TestDecodeSplArraySerialized(t *testing.T) {
 		t.Errorf("SplArray.Properties expected: empty phptype.Array, got %v", array.Properties)
 	}
 }
+
+func TestDecodeOrdered(t *testing.T) {
+	decoder := NewUnserializer(`a:3:{s:1:"z";i:1;i:9;a:2:{s:1:"b";i:2;s:1:"a";i:3;}s:1:"m";O:4:"User":2:{s:4:"name";s:3:"bob";s:5:"email";s:0:"";}}`)
+	decoder.SetOrdered(true)
+	val, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("Error while decoding ordered array: %v\n", err)
+	}
+
+	array, ok := val.(*phptype.OrderedArray)
+	if !ok {
+		t.Fatalf("Unable to convert %v to *phptype.OrderedArray\n", val)
+	}
+	if keys, _ := array.Keys(); !reflect.DeepEqual(keys, []phptype.Value{"z", 9, "m"}) {
+		t.Errorf("Array keys decoded in wrong order: %v\n", keys)
+	}
+
+	nested, ok := array.Array[9].(*phptype.OrderedArray)
+	if !ok {
+		t.Fatalf("Unable to convert %v to *phptype.OrderedArray\n", array.Array[9])
+	}
+	if keys, _ := nested.Keys(); !reflect.DeepEqual(keys, []phptype.Value{"b", "a"}) {
+		t.Errorf("Nested array keys decoded in wrong order: %v\n", keys)
+	}
+
+	obj, ok := array.Array["m"].(*phptype.Object)
+	if !ok {
+		t.Fatalf("Unable to convert %v to *phptype.Object\n", array.Array["m"])
+	}
+	if !reflect.DeepEqual(obj.Order, []phptype.Value{"name", "email"}) {
+		t.Errorf("Object members decoded in wrong order: %v\n", obj.Order)
+	}
+}
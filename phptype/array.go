@@ -1,3 +1,105 @@
 package phptype
 
+import (
+	"fmt"
+	"sort"
+)
+
 type Array map[Value]Value
+
+// OrderedArray is Array keeping the insertion order of its keys like PHP array does.
+// Keys of Array missing from Order follow the ordered ones, see OrderedKeys
+type OrderedArray struct {
+	Array Array
+	Order []Value
+}
+
+func NewOrderedArray() *OrderedArray {
+	return &OrderedArray{
+		Array: Array{},
+	}
+}
+
+// Get return the value of the key
+func (self *OrderedArray) Get(key Value) (v Value, ok bool) {
+	v, ok = self.Array[key]
+	return
+}
+
+// Set the value of the key, new key is appended at the end like $array[$key] = $value
+func (self *OrderedArray) Set(key, value Value) *OrderedArray {
+	if _, ok := self.Array[key]; !ok {
+		self.Order = append(self.Order, key)
+	}
+	self.Array[key] = value
+	return self
+}
+
+// Delete the key like unset($array[$key])
+func (self *OrderedArray) Delete(key Value) {
+	if _, ok := self.Array[key]; !ok {
+		return
+	}
+	delete(self.Array, key)
+	for i, k := range self.Order {
+		if k == key {
+			self.Order = append(self.Order[:i:i], self.Order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Len return the number of elements like count($array)
+func (self *OrderedArray) Len() int {
+	return len(self.Array)
+}
+
+// Keys return the keys in order, see OrderedKeys
+func (self *OrderedArray) Keys() ([]Value, error) {
+	return OrderedKeys(self.Array, self.Order)
+}
+
+// OrderedKeys return the keys of array present in order, followed by the keys missing from order:
+// int keys in ascending order, then string keys in byte order. Keys which are not int or string
+// are an error
+func OrderedKeys(array map[Value]Value, order []Value) ([]Value, error) {
+	keys := make([]Value, 0, len(array))
+	seen := make(map[Value]bool, len(order))
+	for _, key := range order {
+		if _, ok := array[key]; ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == len(array) {
+		return keys, nil
+	}
+
+	var (
+		ints []int
+		strs []string
+	)
+	for key := range array {
+		if seen[key] {
+			continue
+		}
+		switch k := key.(type) {
+		case int:
+			ints = append(ints, k)
+		case string:
+			strs = append(strs, k)
+		default:
+			return nil, fmt.Errorf("phptype: unsupported array key type %T", key)
+		}
+	}
+
+	sort.Ints(ints)
+	sort.Strings(strs)
+	for _, k := range ints {
+		keys = append(keys, k)
+	}
+	for _, k := range strs {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
@@ -0,0 +1,496 @@
+package phptype
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// VarDump, PrintR and VarExport render values the way PHP 8 var_dump(), print_r() and var_export() do
+// with the default precision=14 and serialize_precision=-1 settings. The golden files of the tests
+// are generated by PHP with testdata/generate.sh.
+//
+// Order caveat: Array is a Go map and doesn't keep the insertion order of PHP arrays, its keys are
+// printed int keys first in ascending order, then string keys in byte order, so the output differ
+// from PHP unless the array was built in that order. Decode with Unserializer.SetOrdered or
+// UnmarshalOrderedJSON to get OrderedArray, which is printed in PHP order like Object members.
+//
+// Object handles (#1) are numbered in order of the first appearance. Values of unsupported Go types are printed as strings using fmt
+
+// VarDump return output of var_dump()
+func VarDump(v Value) string {
+	d := newDumper()
+	d.varDump(v, 1)
+	return d.buf.String()
+}
+
+// PrintR return output of print_r($value, true)
+func PrintR(v Value) string {
+	d := newDumper()
+	d.printR(v, 0)
+	return d.buf.String()
+}
+
+// VarExport return output of var_export($value, true)
+func VarExport(v Value) string {
+	d := newDumper()
+	d.varExport(v, 1)
+	return d.buf.String()
+}
+
+type dumper struct {
+	buf     bytes.Buffer
+	handles map[interface{}]int
+	visited map[interface{}]bool
+}
+
+func newDumper() *dumper {
+	return &dumper{
+		handles: make(map[interface{}]int),
+		visited: make(map[interface{}]bool),
+	}
+}
+
+// dumpEntry is key and value of array element or object property, key is int or mangled name
+type dumpEntry struct {
+	key   Value
+	value Value
+}
+
+func (d *dumper) varDump(v Value, level int) {
+	if level > 1 {
+		d.spaces(level - 1)
+	}
+
+	switch t := v.(type) {
+	case nil:
+		d.buf.WriteString("NULL\n")
+	case bool:
+		fmt.Fprintf(&d.buf, "bool(%t)\n", t)
+	case string:
+		fmt.Fprintf(&d.buf, "string(%d) \"%s\"\n", len(t), t)
+	case Array, map[Value]Value, *OrderedArray, Slice:
+		id := identity(v)
+		if d.visited[id] {
+			d.buf.WriteString("*RECURSION*\n")
+			return
+		}
+		d.enter(id)
+		defer d.leave(id)
+
+		entries := arrayEntries(v)
+		fmt.Fprintf(&d.buf, "array(%d) {\n", len(entries))
+		for _, entry := range entries {
+			d.spaces(level + 1)
+			if s, ok := entry.key.(string); ok {
+				fmt.Fprintf(&d.buf, "[\"%s\"]=>\n", s)
+			} else {
+				fmt.Fprintf(&d.buf, "[%v]=>\n", entry.key)
+			}
+			d.varDump(entry.value, level+2)
+		}
+		d.closing(level, "}\n")
+	case *Object, *ObjectSerialized, *PhpSplArray:
+		if d.visited[v] {
+			d.buf.WriteString("*RECURSION*\n")
+			return
+		}
+		d.enter(v)
+		defer d.leave(v)
+
+		className, entries := objectEntries(v)
+		fmt.Fprintf(&d.buf, "object(%s)#%d (%d) {\n", className, d.handle(v), len(entries))
+		for _, entry := range entries {
+			d.spaces(level + 1)
+			if s, ok := entry.key.(string); ok {
+				name, visibility, class := DemangleMemberName(s)
+				switch visibility {
+				case VisibilityProtected:
+					fmt.Fprintf(&d.buf, "[\"%s\":protected]=>\n", name)
+				case VisibilityPrivate:
+					fmt.Fprintf(&d.buf, "[\"%s\":\"%s\":private]=>\n", name, class)
+				default:
+					fmt.Fprintf(&d.buf, "[\"%s\"]=>\n", s)
+				}
+			} else {
+				fmt.Fprintf(&d.buf, "[%v]=>\n", entry.key)
+			}
+			d.varDump(entry.value, level+2)
+		}
+		d.closing(level, "}\n")
+	default:
+		if f, ok := toFloat(v); ok {
+			fmt.Fprintf(&d.buf, "float(%s)\n", formatFloat(f, 0, false))
+		} else if i, ok := toInt(v); ok {
+			fmt.Fprintf(&d.buf, "int(%s)\n", i)
+		} else {
+			d.varDump(fmt.Sprint(v), 1)
+		}
+	}
+}
+
+func (d *dumper) printR(v Value, indent int) {
+	switch t := v.(type) {
+	case nil:
+	case bool:
+		if t {
+			d.buf.WriteString("1")
+		}
+	case string:
+		d.buf.WriteString(t)
+	case Array, map[Value]Value, *OrderedArray, Slice:
+		d.buf.WriteString("Array\n")
+		id := identity(v)
+		if d.visited[id] {
+			d.buf.WriteString(" *RECURSION*")
+			return
+		}
+		d.enter(id)
+		defer d.leave(id)
+		d.printHash(arrayEntries(v), indent, false)
+	case *Object, *ObjectSerialized, *PhpSplArray:
+		className, entries := objectEntries(v)
+		d.buf.WriteString(className)
+		d.buf.WriteString(" Object\n")
+		if d.visited[v] {
+			d.buf.WriteString(" *RECURSION*")
+			return
+		}
+		d.enter(v)
+		defer d.leave(v)
+		d.printHash(entries, indent, true)
+	default:
+		if f, ok := toFloat(v); ok {
+			d.buf.WriteString(formatFloat(f, 14, false))
+		} else if i, ok := toInt(v); ok {
+			d.buf.WriteString(i)
+		} else {
+			d.buf.WriteString(fmt.Sprint(v))
+		}
+	}
+}
+
+func (d *dumper) printHash(entries []dumpEntry, indent int, isObject bool) {
+	d.spaces(indent)
+	d.buf.WriteString("(\n")
+	for _, entry := range entries {
+		d.spaces(indent + 4)
+		d.buf.WriteByte('[')
+		if s, ok := entry.key.(string); ok && isObject {
+			name, visibility, class := DemangleMemberName(s)
+			d.buf.WriteString(name)
+			switch visibility {
+			case VisibilityProtected:
+				d.buf.WriteString(":protected")
+			case VisibilityPrivate:
+				d.buf.WriteString(":" + class + ":private")
+			}
+		} else {
+			fmt.Fprint(&d.buf, entry.key)
+		}
+		d.buf.WriteString("] => ")
+		d.printR(entry.value, indent+8)
+		d.buf.WriteString("\n")
+	}
+	d.spaces(indent)
+	d.buf.WriteString(")\n")
+}
+
+func (d *dumper) varExport(v Value, level int) {
+	switch t := v.(type) {
+	case nil:
+		d.buf.WriteString("NULL")
+	case bool:
+		d.buf.WriteString(strconv.FormatBool(t))
+	case string:
+		d.buf.WriteString(exportString(t))
+	case Array, map[Value]Value, *OrderedArray, Slice:
+		id := identity(v)
+		if d.visited[id] {
+			// PHP emit warning "var_export does not handle circular references"
+			d.buf.WriteString("NULL")
+			return
+		}
+		d.enter(id)
+		defer d.leave(id)
+
+		if level > 1 {
+			d.buf.WriteByte('\n')
+			d.spaces(level - 1)
+		}
+		d.buf.WriteString("array (\n")
+		for _, entry := range arrayEntries(v) {
+			d.spaces(level + 1)
+			if s, ok := entry.key.(string); ok {
+				d.buf.WriteString(exportString(s))
+			} else {
+				fmt.Fprint(&d.buf, entry.key)
+			}
+			d.buf.WriteString(" => ")
+			d.varExport(entry.value, level+2)
+			d.buf.WriteString(",\n")
+		}
+		d.closing(level, ")")
+	case *Object, *ObjectSerialized, *PhpSplArray:
+		if d.visited[v] {
+			d.buf.WriteString("NULL")
+			return
+		}
+		d.enter(v)
+		defer d.leave(v)
+
+		if level > 1 {
+			d.buf.WriteByte('\n')
+			d.spaces(level - 1)
+		}
+		className, entries := objectEntries(v)
+		if spl, ok := v.(*PhpSplArray); ok {
+			entries = splExportEntries(spl)
+		}
+		if className == "stdClass" {
+			d.buf.WriteString("(object) array(\n")
+		} else {
+			d.buf.WriteString("\\" + className + "::__set_state(array(\n")
+		}
+		for _, entry := range entries {
+			d.spaces(level + 2)
+			if s, ok := entry.key.(string); ok {
+				name, _, _ := DemangleMemberName(s)
+				d.buf.WriteString(exportString(name))
+			} else {
+				fmt.Fprint(&d.buf, entry.key)
+			}
+			d.buf.WriteString(" => ")
+			d.varExport(entry.value, level+2)
+			d.buf.WriteString(",\n")
+		}
+		if className == "stdClass" {
+			d.closing(level, ")")
+		} else {
+			d.closing(level, "))")
+		}
+	default:
+		if f, ok := toFloat(v); ok {
+			d.buf.WriteString(formatFloat(f, 0, true))
+		} else if i, ok := toInt(v); ok {
+			// PHP_INT_MIN literal would be parsed as float
+			if i == strconv.FormatInt(math.MinInt64, 10) {
+				i = strconv.FormatInt(math.MinInt64+1, 10) + "-1"
+			}
+			d.buf.WriteString(i)
+		} else {
+			d.buf.WriteString(exportString(fmt.Sprint(v)))
+		}
+	}
+}
+
+func (d *dumper) handle(obj Value) int {
+	if h, ok := d.handles[obj]; ok {
+		return h
+	}
+	h := len(d.handles) + 1
+	d.handles[obj] = h
+	return h
+}
+
+func (d *dumper) enter(id interface{}) {
+	if id != nil {
+		d.visited[id] = true
+	}
+}
+
+func (d *dumper) leave(id interface{}) {
+	delete(d.visited, id)
+}
+
+func (d *dumper) spaces(n int) {
+	d.buf.WriteString(strings.Repeat(" ", n))
+}
+
+func (d *dumper) closing(level int, s string) {
+	if level > 1 {
+		d.spaces(level - 1)
+	}
+	d.buf.WriteString(s)
+}
+
+// identity of map used for the recursion detection, slices can't contain themselves
+func identity(v Value) interface{} {
+	if _, ok := v.(Slice); ok {
+		return nil
+	}
+	return reflect.ValueOf(v).Pointer()
+}
+
+func arrayEntries(v Value) []dumpEntry {
+	switch t := v.(type) {
+	case Slice:
+		entries := make([]dumpEntry, len(t))
+		for i, value := range t {
+			entries[i] = dumpEntry{i, value}
+		}
+		return entries
+	case Array:
+		return mapEntries(t, nil)
+	case map[Value]Value:
+		return mapEntries(t, nil)
+	case *OrderedArray:
+		return mapEntries(t.Array, t.Order)
+	}
+	return nil
+}
+
+func mapEntries(array map[Value]Value, order []Value) []dumpEntry {
+	keys, err := OrderedKeys(array, order)
+	if err != nil {
+		// keys of other types than int and string are printed in undefined order
+		keys = make([]Value, 0, len(array))
+		for key := range array {
+			keys = append(keys, key)
+		}
+	}
+
+	entries := make([]dumpEntry, len(keys))
+	for i, key := range keys {
+		entries[i] = dumpEntry{key, array[key]}
+	}
+	return entries
+}
+
+// objectEntries return class name and properties of the object.
+// Serializable object show the properties of decoded Value when it is array or object,
+// ArrayObject show its storage like PHP debug output
+func objectEntries(v Value) (string, []dumpEntry) {
+	switch t := v.(type) {
+	case *Object:
+		return t.ClassName, mapEntries(t.Members, t.Order)
+	case *ObjectSerialized:
+		switch value := t.Value.(type) {
+		case *Object:
+			return t.ClassName, mapEntries(value.Members, value.Order)
+		case Array, map[Value]Value, *OrderedArray, Slice:
+			return t.ClassName, arrayEntries(value)
+		}
+		return t.ClassName, nil
+	case *PhpSplArray:
+		entries := arrayEntries(t.Properties)
+		return "ArrayObject", append(entries, dumpEntry{"\x00ArrayObject\x00storage", t.Array})
+	}
+	return "", nil
+}
+
+// splExportEntries return what var_export show of ArrayObject: the storage,
+// or the properties with ArrayObject::STD_PROP_LIST flag
+func splExportEntries(spl *PhpSplArray) []dumpEntry {
+	const stdPropList = 1
+	if spl.Flags&stdPropList != 0 {
+		return arrayEntries(spl.Properties)
+	}
+	if _, entries := objectEntries(spl.Array); entries != nil {
+		return entries
+	}
+	return arrayEntries(spl.Array)
+}
+
+// exportString quote string like var_export, NUL bytes are concatenated as "\0"
+func exportString(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+	s = strings.Replace(s, "\x00", `' . "\0" . '`, -1)
+	return "'" + s + "'"
+}
+
+func toFloat(v Value) (float64, bool) {
+	switch t := v.(type) {
+	case float32:
+		return float64(t), true
+	case float64:
+		return t, true
+	}
+	return 0, false
+}
+
+func toInt(v Value) (string, bool) {
+	switch t := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(t), true
+	}
+	return "", false
+}
+
+// formatFloat is port of php_gcvt(), precision zero is the shortest representation
+// used with serialize_precision=-1, zeroFrac append ".0" to integral value like var_export
+func formatFloat(f float64, precision int, zeroFrac bool) string {
+	switch {
+	case math.IsNaN(f):
+		return "NAN"
+	case math.IsInf(f, 1):
+		return "INF"
+	case math.IsInf(f, -1):
+		return "-INF"
+	}
+
+	var s string
+	if precision <= 0 {
+		s = strconv.FormatFloat(math.Abs(f), 'e', -1, 64)
+		precision = 17
+	} else {
+		s = strconv.FormatFloat(math.Abs(f), 'e', precision-1, 64)
+	}
+
+	mantissa, exp := s[:strings.IndexByte(s, 'e')], s[strings.IndexByte(s, 'e')+1:]
+	digits := strings.TrimRight(strings.Replace(mantissa, ".", "", 1), "0")
+	if digits == "" {
+		digits = "0"
+	}
+	decpt, _ := strconv.Atoi(exp)
+	decpt++
+
+	var buf strings.Builder
+	if math.Signbit(f) {
+		buf.WriteByte('-')
+	}
+
+	switch {
+	case (decpt < 0 && decpt < -3) || (decpt >= 0 && decpt > precision):
+		// exponential format, e.g. 1.0E+25
+		buf.WriteByte(digits[0])
+		buf.WriteByte('.')
+		if len(digits) == 1 {
+			buf.WriteByte('0')
+		} else {
+			buf.WriteString(digits[1:])
+		}
+		buf.WriteByte('E')
+		if decpt-1 < 0 {
+			buf.WriteByte('-')
+			buf.WriteString(strconv.Itoa(1 - decpt))
+		} else {
+			buf.WriteByte('+')
+			buf.WriteString(strconv.Itoa(decpt - 1))
+		}
+		return buf.String()
+	case decpt < 0:
+		buf.WriteString("0.")
+		buf.WriteString(strings.Repeat("0", -decpt))
+		buf.WriteString(digits)
+	case decpt == 0:
+		buf.WriteString("0.")
+		buf.WriteString(digits)
+	default:
+		if len(digits) <= decpt {
+			buf.WriteString(digits)
+			buf.WriteString(strings.Repeat("0", decpt-len(digits)))
+			if zeroFrac {
+				buf.WriteString(".0")
+			}
+		} else {
+			buf.WriteString(digits[:decpt])
+			buf.WriteByte('.')
+			buf.WriteString(digits[decpt:])
+		}
+	}
+	return buf.String()
+}
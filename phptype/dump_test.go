@@ -0,0 +1,127 @@
+package phptype
+
+import (
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// dumpFixtures are the values printed by PHP into the golden files, keep them in sync
+// with testdata/generate.sh
+func dumpFixtures() map[string]Value {
+	// private property of parent class come first like in PHP 8.1
+	user := NewObject("User")
+	user.set("\x00Model\x00dirty", false)
+	user.SetPublic("name", "bob")
+	user.SetProtected("email", "bob@example.com")
+	user.SetPrivate("id", 7)
+
+	node := NewObject("Node")
+	node.SetPublic("name", "root")
+	node.SetPublic("self", node)
+
+	// computed at runtime, constant expression would be exact 0.3
+	tenth := 0.1
+
+	return map[string]Value{
+		"array": NewOrderedArray().
+			Set("null", nil).
+			Set("true", true).
+			Set("false", false).
+			Set("int", 42).
+			Set("neg", -7).
+			Set("float", 1.5).
+			Set("whole", 2.0).
+			Set("tiny", 0.00001).
+			Set("big", 1e25).
+			Set("str", `it's "q"`).
+			Set("utf8", "héllo").
+			Set("list", Slice{1, "a"}).
+			Set("empty", Array{}).
+			Set(7, "int key after string keys").
+			Set("user", user),
+		"recursion": node,
+		"special": Slice{
+			tenth + 0.2,
+			math.Copysign(0, -1),
+			math.NaN(),
+			math.Inf(-1),
+			math.MinInt64,
+			"a\x00b",
+			`back\slash`,
+		},
+	}
+}
+
+func TestDumpGolden(t *testing.T) {
+	printers := map[string]func(Value) string{
+		"var_dump":   VarDump,
+		"print_r":    PrintR,
+		"var_export": VarExport,
+	}
+
+	for name, value := range dumpFixtures() {
+		for printerName, printer := range printers {
+			golden := filepath.Join("testdata", name+"."+printerName+".golden")
+			actual := printer(value)
+
+			expected, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != string(expected) {
+				t.Errorf("%s of %s differ from %s\n%s", printerName, name, golden, actual)
+			}
+		}
+	}
+}
+
+func TestDumpScalars(t *testing.T) {
+	cases := []struct {
+		value                      Value
+		varDump, printR, varExport string
+	}{
+		{"", "string(0) \"\"\n", "", "''"},
+		{uint8(255), "int(255)\n", "255", "255"},
+		{float32(0.5), "float(0.5)\n", "0.5", "0.5"},
+		{0.0001, "float(0.0001)\n", "0.0001", "0.0001"},
+		{123456789012345678.0, "float(1.2345678901234568E+17)\n", "1.2345678901235E+17", "1.2345678901234568E+17"},
+		{&ObjectSerialized{ClassName: "Money", Data: "i:5;"}, "object(Money)#1 (0) {\n}\n", "Money Object\n(\n)\n", "\\Money::__set_state(array(\n))"},
+		{NewObject("stdClass"), "object(stdClass)#1 (0) {\n}\n", "stdClass Object\n(\n)\n", "(object) array(\n)"},
+	}
+
+	for _, c := range cases {
+		if actual := VarDump(c.value); actual != c.varDump {
+			t.Errorf("var_dump of %#v is %q \n", c.value, actual)
+		}
+		if actual := PrintR(c.value); actual != c.printR {
+			t.Errorf("print_r of %#v is %q \n", c.value, actual)
+		}
+		if actual := VarExport(c.value); actual != c.varExport {
+			t.Errorf("var_export of %#v is %q \n", c.value, actual)
+		}
+	}
+}
+
+func TestDumpSharedObjectAndArrayRecursion(t *testing.T) {
+	shared := NewObject("Item")
+	actual := VarDump(Slice{shared, shared, NewObject("Item")})
+	expected := "array(3) {\n" +
+		"  [0]=>\n  object(Item)#1 (0) {\n  }\n" +
+		"  [1]=>\n  object(Item)#1 (0) {\n  }\n" +
+		"  [2]=>\n  object(Item)#2 (0) {\n  }\n" +
+		"}\n"
+	if actual != expected {
+		t.Errorf("Shared object was dumped incorrectly \n%s", actual)
+	}
+
+	array := Array{}
+	array["me"] = array
+	if actual := VarDump(array); actual != "array(1) {\n  [\"me\"]=>\n  *RECURSION*\n}\n" {
+		t.Errorf("Recursive array was dumped incorrectly \n%s", actual)
+	}
+	if actual := VarExport(array); actual != "array (\n  'me' => NULL,\n)" {
+		t.Errorf("Recursive array was exported incorrectly \n%s", actual)
+	}
+}
@@ -0,0 +1,553 @@
+package phptype
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSON representation of PHP values, every value survive MarshalJSON and UnmarshalJSON unchanged:
+//
+//	null, true, false          null and bool
+//	"text"                     string, valid UTF-8 only
+//	{"bytes": "AAE="}          string which is not valid UTF-8, base64 encoded
+//	42                         int, never written with fraction or exponent
+//	1.0, 2.5e-7                float, always written with fraction or exponent
+//	{"float": "NAN"}           float NAN, INF and -INF
+//	{"array": [[0, "a"], ["k", "b"]]}
+//	                           Array as key/value pairs, int keys stay numbers and string keys stay strings
+//	{"list": ["a", "b"]}       Slice
+//	{"object": "User", "members": [{"name": "id", "value": 1, "visibility": "private"}]}
+//	                           Object, visibility is "protected" or "private" and absent for public members,
+//	                           "class" hold the declaring class of private member inherited from parent
+//	{"serialized": "Money", "data": "...", "value": ...}
+//	                           ObjectSerialized, "value" is absent when nil
+//	{"spl_array": 0, "array": ..., "properties": ...}
+//	                           PhpSplArray
+//
+// OrderedArray and Object members are written in their order, the keys missing from the order
+// and the keys of Array, which is a Go map, are written int keys first in ascending order,
+// then string keys in byte order. Mangled member names are split into name and visibility
+
+// MarshalJSON encode the PHP value in the tagged JSON representation
+func MarshalJSON(v Value) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decode the PHP value from the tagged JSON representation, arrays are decoded as Array
+func UnmarshalJSON(data []byte) (Value, error) {
+	return jsonDecoder{}.unmarshal(data)
+}
+
+// UnmarshalOrderedJSON decode the PHP value from the tagged JSON representation
+// like UnmarshalJSON, arrays are decoded as *OrderedArray keeping the order of the pairs
+func UnmarshalOrderedJSON(data []byte) (Value, error) {
+	return jsonDecoder{ordered: true}.unmarshal(data)
+}
+
+// jsonDecoder convert decoded JSON to PHP values, ordered decode arrays as *OrderedArray
+type jsonDecoder struct {
+	ordered bool
+}
+
+func (d jsonDecoder) unmarshal(data []byte) (Value, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("phptype: %v", err)
+	}
+	return d.fromJSON(raw)
+}
+
+func (self Array) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(self)
+}
+
+func (self *Array) UnmarshalJSON(data []byte) error {
+	v, err := UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	array, ok := v.(Array)
+	if !ok {
+		return fmt.Errorf("phptype: can't unmarshal %T into Array", v)
+	}
+	*self = array
+	return nil
+}
+
+func (self *OrderedArray) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(self)
+}
+
+func (self *OrderedArray) UnmarshalJSON(data []byte) error {
+	v, err := UnmarshalOrderedJSON(data)
+	if err != nil {
+		return err
+	}
+	array, ok := v.(*OrderedArray)
+	if !ok {
+		return fmt.Errorf("phptype: can't unmarshal %T into OrderedArray", v)
+	}
+	*self = *array
+	return nil
+}
+
+func (self Slice) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(self)
+}
+
+func (self *Slice) UnmarshalJSON(data []byte) error {
+	v, err := UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	slice, ok := v.(Slice)
+	if !ok {
+		return fmt.Errorf("phptype: can't unmarshal %T into Slice", v)
+	}
+	*self = slice
+	return nil
+}
+
+func (self *Object) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(self)
+}
+
+func (self *Object) UnmarshalJSON(data []byte) error {
+	v, err := UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	obj, ok := v.(*Object)
+	if !ok {
+		return fmt.Errorf("phptype: can't unmarshal %T into Object", v)
+	}
+	*self = *obj
+	return nil
+}
+
+func (self *ObjectSerialized) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(self)
+}
+
+func (self *ObjectSerialized) UnmarshalJSON(data []byte) error {
+	v, err := UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	obj, ok := v.(*ObjectSerialized)
+	if !ok {
+		return fmt.Errorf("phptype: can't unmarshal %T into ObjectSerialized", v)
+	}
+	*self = *obj
+	return nil
+}
+
+func (self *PhpSplArray) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(self)
+}
+
+func (self *PhpSplArray) UnmarshalJSON(data []byte) error {
+	v, err := UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	spl, ok := v.(*PhpSplArray)
+	if !ok {
+		return fmt.Errorf("phptype: can't unmarshal %T into PhpSplArray", v)
+	}
+	*self = *spl
+	return nil
+}
+
+func writeJSON(buf *bytes.Buffer, v Value) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(t))
+	case int:
+		buf.WriteString(strconv.FormatInt(int64(t), 10))
+	case int8:
+		buf.WriteString(strconv.FormatInt(int64(t), 10))
+	case int16:
+		buf.WriteString(strconv.FormatInt(int64(t), 10))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(t), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(t, 10))
+	case uint:
+		buf.WriteString(strconv.FormatUint(uint64(t), 10))
+	case uint8:
+		buf.WriteString(strconv.FormatUint(uint64(t), 10))
+	case uint16:
+		buf.WriteString(strconv.FormatUint(uint64(t), 10))
+	case uint32:
+		buf.WriteString(strconv.FormatUint(uint64(t), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(t, 10))
+	case float32:
+		writeJSONFloat(buf, float64(t), 32)
+	case float64:
+		writeJSONFloat(buf, t, 64)
+	case string:
+		writeJSONString(buf, t)
+	case Array:
+		return writeJSONArray(buf, t, nil)
+	case map[Value]Value:
+		return writeJSONArray(buf, t, nil)
+	case *OrderedArray:
+		return writeJSONArray(buf, t.Array, t.Order)
+	case Slice:
+		buf.WriteString(`{"list":[`)
+		for i, value := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, value); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("]}")
+	case *Object:
+		return writeJSONObject(buf, t)
+	case *ObjectSerialized:
+		buf.WriteString(`{"serialized":`)
+		writeJSONString(buf, t.ClassName)
+		buf.WriteString(`,"data":`)
+		writeJSONString(buf, t.Data)
+		if t.Value != nil {
+			buf.WriteString(`,"value":`)
+			if err := writeJSON(buf, t.Value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case *PhpSplArray:
+		buf.WriteString(`{"spl_array":`)
+		buf.WriteString(strconv.Itoa(t.Flags))
+		buf.WriteString(`,"array":`)
+		if err := writeJSON(buf, t.Array); err != nil {
+			return err
+		}
+		buf.WriteString(`,"properties":`)
+		if err := writeJSON(buf, t.Properties); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("phptype: unsupported type %T", v)
+	}
+	return nil
+}
+
+func writeJSONFloat(buf *bytes.Buffer, f float64, bitSize int) {
+	switch {
+	case math.IsNaN(f):
+		buf.WriteString(`{"float":"NAN"}`)
+	case math.IsInf(f, 1):
+		buf.WriteString(`{"float":"INF"}`)
+	case math.IsInf(f, -1):
+		buf.WriteString(`{"float":"-INF"}`)
+	default:
+		s := strconv.FormatFloat(f, 'g', -1, bitSize)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		buf.WriteString(s)
+	}
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	if !utf8.ValidString(s) {
+		buf.WriteString(`{"bytes":"`)
+		buf.WriteString(base64.StdEncoding.EncodeToString([]byte(s)))
+		buf.WriteString(`"}`)
+		return
+	}
+
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	// json.Encoder terminate every value with newline
+	buf.Truncate(buf.Len() - 1)
+}
+
+func writeJSONArray(buf *bytes.Buffer, array map[Value]Value, order []Value) error {
+	keys, err := OrderedKeys(array, order)
+	if err != nil {
+		return err
+	}
+
+	buf.WriteString(`{"array":[`)
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('[')
+		writeJSON(buf, key)
+		buf.WriteByte(',')
+		if err := writeJSON(buf, array[key]); err != nil {
+			return err
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteString("]}")
+	return nil
+}
+
+func writeJSONObject(buf *bytes.Buffer, obj *Object) error {
+	keys, err := obj.Keys()
+	if err != nil {
+		return err
+	}
+
+	buf.WriteString(`{"object":`)
+	writeJSONString(buf, obj.ClassName)
+	buf.WriteString(`,"members":[`)
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		var name Value = key
+		visibility, class := "", ""
+		if s, ok := key.(string); ok {
+			name, visibility, class = DemangleMemberName(s)
+		}
+
+		buf.WriteString(`{"name":`)
+		writeJSON(buf, name)
+		buf.WriteString(`,"value":`)
+		if err := writeJSON(buf, obj.Members[key]); err != nil {
+			return err
+		}
+		if visibility != VisibilityPublic {
+			buf.WriteString(`,"visibility":"`)
+			buf.WriteString(visibility)
+			buf.WriteByte('"')
+		}
+		if visibility == VisibilityPrivate && class != obj.ClassName {
+			buf.WriteString(`,"class":`)
+			writeJSONString(buf, class)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteString("]}")
+	return nil
+}
+
+func (d jsonDecoder) fromJSON(raw interface{}) (Value, error) {
+	switch t := raw.(type) {
+	case nil, bool, string:
+		return t, nil
+	case json.Number:
+		return numberFromJSON(t)
+	case map[string]interface{}:
+		return d.taggedFromJSON(t)
+	default:
+		return nil, fmt.Errorf("phptype: untagged JSON %T", raw)
+	}
+}
+
+func numberFromJSON(n json.Number) (Value, error) {
+	s := n.String()
+	if strings.ContainsAny(s, ".eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("phptype: invalid float %s", s)
+		}
+		return f, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("phptype: invalid int %s", s)
+	}
+	return i, nil
+}
+
+func (d jsonDecoder) taggedFromJSON(tagged map[string]interface{}) (Value, error) {
+	switch {
+	case tagged["bytes"] != nil:
+		s, _ := tagged["bytes"].(string)
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("phptype: invalid bytes %q", s)
+		}
+		return string(data), nil
+
+	case tagged["float"] != nil:
+		switch tagged["float"] {
+		case "NAN":
+			return math.NaN(), nil
+		case "INF":
+			return math.Inf(1), nil
+		case "-INF":
+			return math.Inf(-1), nil
+		}
+		return nil, fmt.Errorf("phptype: invalid float %v", tagged["float"])
+
+	// spl_array is checked before array, which is one of its fields
+	case tagged["spl_array"] != nil:
+		flags, err := d.fromJSON(tagged["spl_array"])
+		if err != nil {
+			return nil, err
+		}
+		spl := &PhpSplArray{}
+		var ok bool
+		if spl.Flags, ok = flags.(int); !ok {
+			return nil, fmt.Errorf("phptype: spl_array flags is %T", flags)
+		}
+		if spl.Array, err = d.fromJSON(tagged["array"]); err != nil {
+			return nil, err
+		}
+		if spl.Properties, err = d.fromJSON(tagged["properties"]); err != nil {
+			return nil, err
+		}
+		return spl, nil
+
+	case tagged["array"] != nil:
+		return d.arrayFromJSON(tagged["array"])
+
+	case tagged["list"] != nil:
+		items, ok := tagged["list"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("phptype: list is %T, not JSON array", tagged["list"])
+		}
+		slice := make(Slice, len(items))
+		for i, item := range items {
+			value, err := d.fromJSON(item)
+			if err != nil {
+				return nil, err
+			}
+			slice[i] = value
+		}
+		return slice, nil
+
+	case tagged["object"] != nil:
+		return d.objectFromJSON(tagged)
+
+	case tagged["serialized"] != nil:
+		obj := &ObjectSerialized{}
+		var ok bool
+		if obj.ClassName, ok = tagged["serialized"].(string); !ok {
+			return nil, fmt.Errorf("phptype: serialized class name is %T", tagged["serialized"])
+		}
+		data, err := d.fromJSON(tagged["data"])
+		if err != nil {
+			return nil, err
+		}
+		if obj.Data, ok = data.(string); !ok {
+			return nil, fmt.Errorf("phptype: serialized data is %T", data)
+		}
+		if obj.Value, err = d.fromJSON(tagged["value"]); err != nil {
+			return nil, err
+		}
+		return obj, nil
+
+	}
+
+	return nil, fmt.Errorf("phptype: JSON object without known tag")
+}
+
+func (d jsonDecoder) arrayFromJSON(raw interface{}) (Value, error) {
+	pairs, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("phptype: array is %T, not JSON array", raw)
+	}
+
+	array := &OrderedArray{Array: make(Array, len(pairs))}
+	for _, rawPair := range pairs {
+		pair, ok := rawPair.([]interface{})
+		if !ok || len(pair) != 2 {
+			return nil, fmt.Errorf("phptype: array item is not [key, value] pair")
+		}
+		key, err := d.keyFromJSON(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.fromJSON(pair[1])
+		if err != nil {
+			return nil, err
+		}
+		array.Set(key, value)
+	}
+
+	if d.ordered {
+		return array, nil
+	}
+	return array.Array, nil
+}
+
+func (d jsonDecoder) objectFromJSON(tagged map[string]interface{}) (*Object, error) {
+	className, ok := tagged["object"].(string)
+	if !ok {
+		return nil, fmt.Errorf("phptype: object class name is %T", tagged["object"])
+	}
+	members, ok := tagged["members"].([]interface{})
+	if !ok && tagged["members"] != nil {
+		return nil, fmt.Errorf("phptype: object members is %T, not JSON array", tagged["members"])
+	}
+
+	obj := NewObject(className)
+	for _, rawMember := range members {
+		member, ok := rawMember.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("phptype: object member is %T, not JSON object", rawMember)
+		}
+
+		key, err := d.keyFromJSON(member["name"])
+		if err != nil {
+			return nil, err
+		}
+		if name, ok := key.(string); ok {
+			visibility, _ := member["visibility"].(string)
+			class, ok := member["class"].(string)
+			if !ok {
+				class = className
+			}
+			if key, err = MangleMemberName(name, visibility, class); err != nil {
+				return nil, err
+			}
+		}
+		value, err := d.fromJSON(member["value"])
+		if err != nil {
+			return nil, err
+		}
+		obj.set(key, value)
+	}
+	return obj, nil
+}
+
+func (d jsonDecoder) keyFromJSON(raw interface{}) (Value, error) {
+	switch k := raw.(type) {
+	case string:
+		return k, nil
+	case json.Number:
+		i, err := strconv.Atoi(k.String())
+		if err != nil {
+			return nil, fmt.Errorf("phptype: invalid array key %s", k)
+		}
+		return i, nil
+	case map[string]interface{}:
+		key, err := d.taggedFromJSON(k)
+		if _, ok := key.(string); err == nil && !ok {
+			err = fmt.Errorf("phptype: invalid array key %T", key)
+		}
+		return key, err
+	default:
+		return nil, fmt.Errorf("phptype: invalid array key %T", raw)
+	}
+}
@@ -0,0 +1,176 @@
+package phptype
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	// private property of parent class come first like in PHP 8.1
+	user := NewObject("User")
+	user.set("\x00Model\x00dirty", false)
+	user.SetPublic("name", "bob")
+	user.SetProtected("email", "bob@example.com")
+	user.SetPrivate("id", 7)
+
+	cases := []struct {
+		value    Value
+		expected string
+	}{
+		{nil, `null`},
+		{true, `true`},
+		{42, `42`},
+		{-3, `-3`},
+		{1.0, `1.0`},
+		{2.5e-7, `2.5e-07`},
+		{math.Inf(-1), `{"float":"-INF"}`},
+		{"<a & b>", `"<a & b>"`},
+		{"\xff\x00", `{"bytes":"/wA="}`},
+		{Array{"b": 1, 10: 2, 2: 3, "a": 4}, `{"array":[[2,3],[10,2],["a",4],["b",1]]}`},
+		{NewOrderedArray().Set("b", 1).Set(10, 2).Set(2, 3).Set("a", 4), `{"array":[["b",1],[10,2],[2,3],["a",4]]}`},
+		{&OrderedArray{Array: Array{"b": 1, 2: 3, "a": 4}, Order: []Value{"b", 9}}, `{"array":[["b",1],[2,3],["a",4]]}`},
+		{Slice{"a", 1.5}, `{"list":["a",1.5]}`},
+		{user, `{"object":"User","members":[` +
+			`{"name":"dirty","value":false,"visibility":"private","class":"Model"},` +
+			`{"name":"name","value":"bob"},` +
+			`{"name":"email","value":"bob@example.com","visibility":"protected"},` +
+			`{"name":"id","value":7,"visibility":"private"}]}`},
+		{&ObjectSerialized{ClassName: "Money", Data: "i:5;"}, `{"serialized":"Money","data":"i:5;"}`},
+		{NewPhpSplArray(Array{0: "x"}, nil), `{"spl_array":0,"array":{"array":[[0,"x"]]},"properties":{"array":[]}}`},
+	}
+
+	for _, c := range cases {
+		data, err := MarshalJSON(c.value)
+		if err != nil {
+			t.Errorf("Can not marshal %#v: %v \n", c.value, err)
+			continue
+		}
+		if string(data) != c.expected {
+			t.Errorf("%#v was marshaled incorrectly %s \n", c.value, data)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	user := NewObject("User")
+	user.SetPrivate("roles", Slice{"admin", "editor"})
+	user.set(3, "numeric property")
+	user.set("\x00\x00odd", 1)
+
+	value := Array{
+		"user":   user,
+		"cart":   Array{0: Array{"sku": "A1", "qty": 2, "price": 9.0}},
+		"5":      "string key",
+		5:        "int key",
+		"\xfe":   "binary key",
+		"nan":    math.Inf(1),
+		"money":  &ObjectSerialized{ClassName: "Money", Data: "a:1:{i:0;i:5;}", Value: Array{0: 5}},
+		"spl":    NewPhpSplArray(Array{"x": 1}, Array{"y": 2.5}),
+		"nested": Slice{nil, true, Slice{}},
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Can not marshal %v \n", err)
+	}
+
+	var decoded Array
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Can not unmarshal %s: %v \n", data, err)
+	}
+	if !reflect.DeepEqual(value, decoded) {
+		t.Errorf("Value changed during JSON round trip \n%#v \n%#v \n", value, decoded)
+	}
+
+	var obj Object
+	if err := json.Unmarshal([]byte(`{"object":"User","members":[{"name":"a","value":1.5}]}`), &obj); err != nil {
+		t.Fatalf("Can not unmarshal object %v \n", err)
+	}
+	if obj.ClassName != "User" || !reflect.DeepEqual(obj.Members, Array{"a": 1.5}) {
+		t.Errorf("Object was unmarshaled incorrectly %#v \n", obj)
+	}
+}
+
+func TestJSONRoundTrip_Ordered(t *testing.T) {
+	user := NewObject("User")
+	user.SetPublic("name", "bob")
+	user.SetPrivate("id", 7)
+
+	value := NewOrderedArray().
+		Set("user", user).
+		Set(10, "ten").
+		Set("cart", NewOrderedArray().Set("sku", "A1").Set("qty", 2)).
+		Set(2, "two").
+		Set("spl", NewPhpSplArray(NewOrderedArray().Set("z", 1).Set("a", 2), NewOrderedArray()))
+
+	data, err := MarshalJSON(value)
+	if err != nil {
+		t.Fatalf("Can not marshal %v \n", err)
+	}
+	decoded, err := UnmarshalOrderedJSON(data)
+	if err != nil {
+		t.Fatalf("Can not unmarshal %s: %v \n", data, err)
+	}
+	if !reflect.DeepEqual(value, decoded) {
+		t.Errorf("Order changed during JSON round trip \n%#v \n%#v \n", value, decoded)
+	}
+
+	array, ok := decoded.(*OrderedArray)
+	if !ok {
+		t.Fatalf("Ordered array was unmarshaled into %T \n", decoded)
+	}
+	if keys, _ := array.Keys(); !reflect.DeepEqual(keys, []Value{"user", 10, "cart", 2, "spl"}) {
+		t.Errorf("Keys of ordered array are %v \n", keys)
+	}
+
+	plain, err := UnmarshalJSON(data)
+	if err != nil {
+		t.Fatalf("Can not unmarshal %s: %v \n", data, err)
+	}
+	if _, ok := plain.(Array); !ok {
+		t.Errorf("Array was unmarshaled into %T \n", plain)
+	}
+}
+
+func TestOrderedArray(t *testing.T) {
+	array := NewOrderedArray().Set("a", 1).Set("b", 2).Set("c", 3)
+	array.Set("a", 4)
+	array.Delete("b")
+	array.Delete("missing")
+	array.Set("b", 5)
+
+	if keys, _ := array.Keys(); !reflect.DeepEqual(keys, []Value{"a", "c", "b"}) {
+		t.Errorf("Keys of ordered array are %v \n", keys)
+	}
+	if v, ok := array.Get("a"); !ok || v != 4 || array.Len() != 3 {
+		t.Errorf("Ordered array was modified incorrectly %#v \n", array)
+	}
+
+	if _, err := OrderedKeys(map[Value]Value{1.5: "x"}, nil); err == nil {
+		t.Errorf("Float key was accepted \n")
+	}
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	for _, data := range []string{
+		`[1, 2]`,
+		`{"unknown": 1}`,
+		`{"array": [[1.5, "x"]]}`,
+		`{"array": [[1]]}`,
+		`{"float": "1.5"}`,
+		`{"bytes": "!!"}`,
+		`{"object": "User", "members": [{"name": "x", "value": 1, "visibility": "internal"}]}`,
+		`12345678901234567890123`,
+	} {
+		if v, err := UnmarshalJSON([]byte(data)); err == nil {
+			t.Errorf("Invalid JSON %s was unmarshaled into %#v \n", data, v)
+		}
+	}
+
+	var array Array
+	if err := json.Unmarshal([]byte(`{"list": []}`), &array); err == nil {
+		t.Errorf("List was unmarshaled into Array \n")
+	}
+}
@@ -1,8 +1,15 @@
 package phptype
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Object struct {
 	ClassName string
 	Members   Array
+	// Order of the members like PHP keeps them, see OrderedKeys
+	Order []Value
 }
 
 func NewObject(className string) *Object {
@@ -18,8 +25,7 @@ func (self *Object) GetPrivate(name string) (v Value, ok bool) {
 }
 
 func (self *Object) SetPrivate(name string, value Value) *Object {
-	self.Members["\x00"+self.ClassName+"\x00"+name] = value
-	return self
+	return self.set("\x00"+self.ClassName+"\x00"+name, value)
 }
 
 func (self *Object) GetProtected(name string) (v Value, ok bool) {
@@ -28,8 +34,7 @@ func (self *Object) GetProtected(name string) (v Value, ok bool) {
 }
 
 func (self *Object) SetProtected(name string, value Value) *Object {
-	self.Members["\x00*\x00"+name] = value
-	return self
+	return self.set("\x00*\x00"+name, value)
 }
 
 func (self *Object) GetPublic(name string) (v Value, ok bool) {
@@ -38,6 +43,56 @@ func (self *Object) GetPublic(name string) (v Value, ok bool) {
 }
 
 func (self *Object) SetPublic(name string, value Value) *Object {
-	self.Members[name] = value
+	return self.set(name, value)
+}
+
+// set the member appending new one to Order
+func (self *Object) set(key, value Value) *Object {
+	if _, ok := self.Members[key]; !ok {
+		self.Order = append(self.Order, key)
+	}
+	self.Members[key] = value
 	return self
 }
+
+// Keys return the member names in order, see OrderedKeys
+func (self *Object) Keys() ([]Value, error) {
+	return OrderedKeys(self.Members, self.Order)
+}
+
+const (
+	VisibilityPublic    = ""
+	VisibilityProtected = "protected"
+	VisibilityPrivate   = "private"
+)
+
+// DemangleMemberName split serialized member name into name, visibility and the declaring class of private member
+func DemangleMemberName(member string) (name, visibility, class string) {
+	if len(member) < 3 || member[0] != 0 {
+		return member, VisibilityPublic, ""
+	}
+	end := strings.IndexByte(member[1:], 0)
+	if end < 0 {
+		return member, VisibilityPublic, ""
+	}
+
+	class, name = member[1:end+1], member[end+2:]
+	if class == "*" {
+		return name, VisibilityProtected, ""
+	}
+	return name, VisibilityPrivate, class
+}
+
+// MangleMemberName return serialized member name, the reverse of DemangleMemberName
+func MangleMemberName(name, visibility, class string) (string, error) {
+	switch visibility {
+	case VisibilityPublic:
+		return name, nil
+	case VisibilityProtected:
+		return "\x00*\x00" + name, nil
+	case VisibilityPrivate:
+		return "\x00" + class + "\x00" + name, nil
+	default:
+		return "", fmt.Errorf("phptype: unknown visibility %q", visibility)
+	}
+}
@@ -0,0 +1,72 @@
+#!/bin/sh
+# Generate the golden files of dump_test.go with PHP 8.1 or newer, which print the private
+# properties of the parent class first. Every golden is printed by its own php process so
+# the object handles start at #1, and php.ini is ignored (-n) to get the default precision,
+# serialize_precision and no xdebug. The fixtures must match dumpFixtures() in dump_test.go
+#
+#	make dump-golden
+set -e
+
+cd "$(dirname "$0")"
+
+PHP_FIXTURES=$(cat <<'PHP'
+class Model
+{
+    private $dirty = false;
+}
+
+class User extends Model
+{
+    public $name = 'bob';
+    protected $email = 'bob@example.com';
+    private $id = 7;
+}
+
+class Node
+{
+    public $name = 'root';
+    public $self;
+}
+
+function fixture($name)
+{
+    switch ($name) {
+        case 'array':
+            return [
+                'null' => null,
+                'true' => true,
+                'false' => false,
+                'int' => 42,
+                'neg' => -7,
+                'float' => 1.5,
+                'whole' => 2.0,
+                'tiny' => 0.00001,
+                'big' => 1e25,
+                'str' => 'it\'s "q"',
+                'utf8' => 'héllo',
+                'list' => [1, 'a'],
+                'empty' => [],
+                7 => 'int key after string keys',
+                'user' => new User(),
+            ];
+        case 'recursion':
+            $node = new Node();
+            $node->self = $node;
+            return $node;
+        case 'special':
+            return [0.1 + 0.2, -0.0, NAN, -INF, PHP_INT_MIN, "a\0b", 'back\slash'];
+    }
+    throw new InvalidArgumentException("unknown fixture $name");
+}
+
+[, $name, $printer] = $argv;
+$printer(fixture($name));
+PHP
+)
+
+for name in array recursion special; do
+    for printer in var_dump print_r var_export; do
+        # var_export warn about the recursion on stderr
+        php -n -d display_errors=stderr -r "$PHP_FIXTURES" -- "$name" "$printer" > "$name.$printer.golden"
+    done
+done
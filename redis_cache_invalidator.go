@@ -0,0 +1,113 @@
+package phpsessgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisCacheInvalidatorConfig configure RedisCacheInvalidator
+type RedisCacheInvalidatorConfig struct {
+	// KeyPrefix of the session keys, default to DefaultRedisKeyPrefix
+	KeyPrefix string
+	// Keyspace listen to keyspace notifications of the session keys, so writes by PHP are observed.
+	// Redis must have notify-keyspace-events including "K" and the events of the session commands, e.g. "Kg$x"
+	Keyspace bool
+	// Channel is pub/sub channel announcing session IDs written by Go processes,
+	// for Redis without keyspace notifications. Disabled when empty
+	Channel string
+}
+
+// RedisCacheInvalidator invalidate CachingSessionHandler when the session is changed by other process
+type RedisCacheInvalidator struct {
+	client *redis.Client
+	config RedisCacheInvalidatorConfig
+	pubsub *redis.PubSub
+	done   chan struct{}
+}
+
+// NewRedisCacheInvalidator create new instance of RedisCacheInvalidator
+func NewRedisCacheInvalidator(client *redis.Client, config RedisCacheInvalidatorConfig) *RedisCacheInvalidator {
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = DefaultRedisKeyPrefix
+	}
+	return &RedisCacheInvalidator{client: client, config: config}
+}
+
+// Listen subscribe to the notifications and invalidate the cache until Close
+func (i *RedisCacheInvalidator) Listen(cache *CachingSessionHandler) error {
+	if !i.config.Keyspace && i.config.Channel == "" {
+		return fmt.Errorf("phpsessgo: neither keyspace notifications nor channel is configured")
+	}
+
+	var patterns, channels []string
+	if i.config.Keyspace {
+		patterns = append(patterns, i.keyspacePrefix()+"*")
+	}
+	if i.config.Channel != "" {
+		channels = append(channels, i.config.Channel)
+	}
+
+	ctx := context.Background()
+	pubsub := i.client.PSubscribe(ctx, patterns...)
+	if len(channels) > 0 {
+		if err := pubsub.Subscribe(ctx, channels...); err != nil {
+			pubsub.Close()
+			return err
+		}
+	}
+	// changes before the subscription is confirmed may be missed, so the cache start empty
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return err
+	}
+	cache.Purge()
+
+	i.pubsub = pubsub
+	i.done = make(chan struct{})
+	messages := pubsub.Channel()
+	go func() {
+		defer close(i.done)
+		for message := range messages {
+			if sessionID, ok := i.sessionID(message); ok {
+				cache.Invalidate(sessionID)
+			}
+		}
+	}()
+	return nil
+}
+
+// Publish announce the written session to other processes
+func (i *RedisCacheInvalidator) Publish(sessionID string) error {
+	if i.config.Channel == "" {
+		return nil
+	}
+	return i.client.Publish(context.Background(), i.config.Channel, sessionID).Err()
+}
+
+// Close stop listening
+func (i *RedisCacheInvalidator) Close() error {
+	if i.pubsub == nil {
+		return nil
+	}
+	err := i.pubsub.Close()
+	<-i.done
+	return err
+}
+
+func (i *RedisCacheInvalidator) keyspacePrefix() string {
+	return fmt.Sprintf("__keyspace@%d__:%s", i.client.Options().DB, i.config.KeyPrefix)
+}
+
+// sessionID return the session ID of keyspace notification or message of the channel
+func (i *RedisCacheInvalidator) sessionID(message *redis.Message) (string, bool) {
+	if i.config.Channel != "" && message.Channel == i.config.Channel {
+		return message.Payload, true
+	}
+	if i.config.Keyspace && strings.HasPrefix(message.Channel, i.keyspacePrefix()) {
+		return strings.TrimPrefix(message.Channel, i.keyspacePrefix()), true
+	}
+	return "", false
+}
@@ -1,10 +1,12 @@
 package phpsessgo
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/go-redis/redis"
+	"github.com/go-redis/redis/v8"
 )
 
 // RedisSessionHandler session management using redis
@@ -23,19 +25,135 @@ func (h *RedisSessionHandler) Close() {
 }
 
 func (h *RedisSessionHandler) Read(sessionID string) (data string, err error) {
-	data, err = h.Client.Get(h.sessionRedisKey(sessionID)).Result()
-	if err != nil && err.Error() == "redis: nil" {
-		data = ""
-		err = nil
-	}
-	return
+	return h.ReadContext(context.Background(), sessionID)
 }
 
 func (h *RedisSessionHandler) Write(sessionID string, sessionData string) error {
-	err := h.Client.Set(h.sessionRedisKey(sessionID), sessionData, h.Expiration).Err()
-	return err
+	return h.WriteContext(context.Background(), sessionID, sessionData)
+}
+
+// Destroy the session
+func (h *RedisSessionHandler) Destroy(sessionID string) error {
+	return h.DestroyContext(context.Background(), sessionID)
+}
+
+// UpdateTimestamp refresh expiration of the session key, same as phpredis
+func (h *RedisSessionHandler) UpdateTimestamp(sessionID string, sessionData string) error {
+	return h.UpdateTimestampContext(context.Background(), sessionID, sessionData)
+}
+
+// ReadContext read the session data. The deadline of ctx bound the command on the connection,
+// an expired or cancelled ctx return ctx.Err() instead of the network error
+func (h *RedisSessionHandler) ReadContext(ctx context.Context, sessionID string) (string, error) {
+	data, err := h.Client.Get(ctx, h.sessionRedisKey(sessionID)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return data, contextError(ctx, err)
+}
+
+// WriteContext write the session data like ReadContext. The write may still be applied
+// when the deadline expired after the command was sent
+func (h *RedisSessionHandler) WriteContext(ctx context.Context, sessionID string, sessionData string) error {
+	err := h.Client.Set(ctx, h.sessionRedisKey(sessionID), sessionData, h.Expiration).Err()
+	return contextError(ctx, err)
+}
+
+// DestroyContext remove the session like WriteContext
+func (h *RedisSessionHandler) DestroyContext(ctx context.Context, sessionID string) error {
+	return contextError(ctx, h.Client.Del(ctx, h.sessionRedisKey(sessionID)).Err())
+}
+
+// UpdateTimestampContext refresh expiration of the session key like WriteContext
+func (h *RedisSessionHandler) UpdateTimestampContext(ctx context.Context, sessionID string, sessionData string) error {
+	if h.Expiration <= 0 {
+		return nil
+	}
+	return contextError(ctx, h.Client.Expire(ctx, h.sessionRedisKey(sessionID), h.Expiration).Err())
+}
+
+// rewriteScript replace the session when it still hold ARGV[1], keeping the remaining TTL
+var rewriteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// RewriteSession replace the session data atomically if it was not changed, the TTL is kept
+func (h *RedisSessionHandler) RewriteSession(sessionID, oldData, newData string) (bool, error) {
+	replaced, err := rewriteScript.Run(context.Background(), h.Client, []string{h.sessionRedisKey(sessionID)}, oldData, newData).Int()
+	return replaced == 1, err
+}
+
+// ScanSessions return one batch of sessions using redis SCAN, iteration start and end with zero cursor.
+// LastWrite is derived from the remaining TTL when Expiration is set
+func (h *RedisSessionHandler) ScanSessions(cursor uint64, count int64) ([]SessionInfo, uint64, error) {
+	keys, next, err := h.Client.Scan(context.Background(), cursor, h.RedisKeyPrefix+"*", count).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	infos := make([]SessionInfo, 0, len(keys))
+	if len(keys) == 0 {
+		return infos, next, nil
+	}
+
+	ctx := context.Background()
+	pipe := h.Client.Pipeline()
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		ttls[i] = pipe.TTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	for i, key := range keys {
+		info := SessionInfo{ID: strings.TrimPrefix(key, h.RedisKeyPrefix)}
+		if ttl := ttls[i].Val(); h.Expiration > 0 && ttl > 0 {
+			info.LastWrite = now.Add(ttl - h.Expiration)
+		}
+		infos = append(infos, info)
+	}
+	return infos, next, nil
+}
+
+// WalkSessions call fn for every session key matching RedisKeyPrefix
+func (h *RedisSessionHandler) WalkSessions(fn func(info SessionInfo) error) error {
+	var cursor uint64
+	for {
+		infos, next, err := h.ScanSessions(cursor, 100)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if err := fn(info); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
 }
 
 func (h *RedisSessionHandler) sessionRedisKey(sessionID string) string {
 	return fmt.Sprintf("%s%s", h.RedisKeyPrefix, sessionID)
 }
+
+// contextError return the error of expired or cancelled ctx instead of the error it caused
+func contextError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
@@ -1,10 +1,12 @@
 package phpsessgo
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis"
-	"github.com/go-redis/redis"
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/require"
 )
 
@@ -45,4 +47,156 @@ func TestRedisSessionHandler(t *testing.T) {
 		require.Equal(t, "some-data-2", val)
 	})
 
+	t.Run("update timestamp", func(t *testing.T) {
+		handler := &RedisSessionHandler{
+			Client:         handler.Client,
+			RedisKeyPrefix: "PHPREDIS_SESSION:",
+			Expiration:     time.Hour,
+		}
+		s.Set("PHPREDIS_SESSION:some-sessionID-6", "some-data-6")
+
+		err := handler.UpdateTimestamp("some-sessionID-6", "some-data-6")
+		require.NoError(t, err)
+		require.Equal(t, time.Hour, s.TTL("PHPREDIS_SESSION:some-sessionID-6"))
+	})
+
+	t.Run("rewrite session", func(t *testing.T) {
+		s.Set("PHPREDIS_SESSION:some-sessionID-8", "some-data-8")
+		s.SetTTL("PHPREDIS_SESSION:some-sessionID-8", 10*time.Minute)
+
+		replaced, err := handler.RewriteSession("some-sessionID-8", "other-data", "new-data")
+		require.NoError(t, err)
+		require.False(t, replaced)
+
+		replaced, err = handler.RewriteSession("some-sessionID-8", "some-data-8", "new-data")
+		require.NoError(t, err)
+		require.True(t, replaced)
+		val, _ := s.Get("PHPREDIS_SESSION:some-sessionID-8")
+		require.Equal(t, "new-data", val)
+		require.Equal(t, 10*time.Minute, s.TTL("PHPREDIS_SESSION:some-sessionID-8"))
+
+		replaced, err = handler.RewriteSession("not-exist", "", "new-data")
+		require.NoError(t, err)
+		require.False(t, replaced)
+		require.False(t, s.Exists("PHPREDIS_SESSION:not-exist"))
+	})
+
+	t.Run("destroy", func(t *testing.T) {
+		s.Set("PHPREDIS_SESSION:some-sessionID-7", "some-data-7")
+
+		require.NoError(t, handler.Destroy("some-sessionID-7"))
+		require.False(t, s.Exists("PHPREDIS_SESSION:some-sessionID-7"))
+	})
+
+	t.Run("read data with context", func(t *testing.T) {
+		s.Set("PHPREDIS_SESSION:some-sessionID-3", "some-data-3")
+
+		data, err := handler.ReadContext(context.Background(), "some-sessionID-3")
+		require.NoError(t, err)
+		require.Equal(t, "some-data-3", data)
+
+		data, err = handler.ReadContext(context.Background(), "not-exist")
+		require.NoError(t, err)
+		require.Equal(t, "", data)
+	})
+
+	t.Run("write data with context", func(t *testing.T) {
+		err := handler.WriteContext(context.Background(), "some-sessionID-4", "some-data-4")
+		require.NoError(t, err)
+
+		val, _ := s.Get("PHPREDIS_SESSION:some-sessionID-4")
+		require.Equal(t, "some-data-4", val)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := handler.ReadContext(ctx, "some-sessionID")
+		require.Equal(t, context.Canceled, err)
+
+		err = handler.WriteContext(ctx, "some-sessionID-5", "some-data-5")
+		require.Equal(t, context.Canceled, err)
+		require.False(t, s.Exists("PHPREDIS_SESSION:some-sessionID-5"))
+
+		require.Equal(t, context.Canceled, handler.DestroyContext(ctx, "some-sessionID"))
+		require.True(t, s.Exists("PHPREDIS_SESSION:some-sessionID"))
+	})
+
+}
+
+func TestRedisSessionHandler_ContextDeadline(t *testing.T) {
+	calls := map[string]func(ctx context.Context, handler *RedisSessionHandler) error{
+		"read": func(ctx context.Context, handler *RedisSessionHandler) error {
+			_, err := handler.ReadContext(ctx, "some-sessionID")
+			return err
+		},
+		"write": func(ctx context.Context, handler *RedisSessionHandler) error {
+			return handler.WriteContext(ctx, "some-sessionID", "some-data")
+		},
+		"update timestamp": func(ctx context.Context, handler *RedisSessionHandler) error {
+			return handler.UpdateTimestampContext(ctx, "some-sessionID", "some-data")
+		},
+		"destroy": func(ctx context.Context, handler *RedisSessionHandler) error {
+			return handler.DestroyContext(ctx, "some-sessionID")
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			s, err := miniredis.Run()
+			require.NoError(t, err)
+			defer s.Close()
+
+			handler := &RedisSessionHandler{
+				Client:         redis.NewClient(&redis.Options{Addr: s.Addr()}),
+				RedisKeyPrefix: "PHPREDIS_SESSION:",
+				Expiration:     time.Hour,
+			}
+			require.NoError(t, handler.Client.Ping(context.Background()).Err())
+
+			// the locked server read the command but doesn't answer it until unlocked
+			s.Lock()
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			err = call(ctx, handler)
+			elapsed := time.Since(start)
+
+			s.Unlock()
+			handler.Close()
+			// the stalled command finish before the server can be closed
+			require.Eventually(t, func() bool { return s.CurrentConnectionCount() == 0 }, time.Second, 10*time.Millisecond)
+
+			require.Equal(t, context.DeadlineExceeded, err)
+			require.Less(t, int64(elapsed), int64(time.Second))
+		})
+	}
+}
+
+func TestRedisSessionHandler_WalkSessions(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	handler := &RedisSessionHandler{
+		Client:         redis.NewClient(&redis.Options{Addr: s.Addr()}),
+		RedisKeyPrefix: "PHPREDIS_SESSION:",
+		Expiration:     time.Hour,
+	}
+	defer handler.Close()
+
+	require.NoError(t, handler.Write("a", "a|i:1;"))
+	require.NoError(t, handler.Write("b", "b|i:1;"))
+	s.Set("other:c", "c|i:1;")
+	s.SetTTL("PHPREDIS_SESSION:b", 30*time.Minute)
+
+	infos := map[string]SessionInfo{}
+	require.NoError(t, handler.WalkSessions(func(info SessionInfo) error {
+		infos[info.ID] = info
+		return nil
+	}))
+	require.Len(t, infos, 2)
+	require.WithinDuration(t, time.Now(), infos["a"].LastWrite, 5*time.Second)
+	require.WithinDuration(t, time.Now().Add(-30*time.Minute), infos["b"].LastWrite, 5*time.Second)
 }
@@ -1,9 +1,13 @@
 package phpsessgo
 
-import "github.com/go-redis/redis"
+import "github.com/go-redis/redis/v8"
+
+// NewRedisSessionManager create new instance of SessionManager, invalid config is rejected
+func NewRedisSessionManager(client *redis.Client, config SessionManagerConfig) (SessionManager, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
-// NewRedisSessionManager create new instance of SessionManager
-func NewRedisSessionManager(client *redis.Client, config SessionManagerConfig) SessionManager {
 	sessionManager := &sessionManager{
 		sessionName: DefaultSessionName,
 		sidCreator:  &UUIDCreator{},
@@ -15,5 +19,5 @@ func NewRedisSessionManager(client *redis.Client, config SessionManagerConfig) S
 		encoder: &PHPSessionEncoder{},
 		config:  config,
 	}
-	return sessionManager
+	return sessionManager, nil
 }
@@ -5,7 +5,7 @@ import (
 	"testing"
 
 	"github.com/eligundry/phpsessgo"
-	"github.com/go-redis/redis"
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,7 +13,8 @@ func TestNewRedisSessionManager(t *testing.T) {
 	client := redis.NewClient(&redis.Options{
 		Addr: "localhost:6379",
 	})
-	manager := phpsessgo.NewRedisSessionManager(client, phpsessgo.SessionManagerConfig{})
+	manager, err := phpsessgo.NewRedisSessionManager(client, phpsessgo.SessionManagerConfig{})
+	require.NoError(t, err)
 	require.Equal(t, phpsessgo.DefaultSessionName, manager.SessionName())
 	require.Equal(t, "*phpsessgo.UUIDCreator", reflect.TypeOf(manager.SIDCreator()).String())
 	require.Equal(t, "*phpsessgo.PHPSessionEncoder", reflect.TypeOf(manager.Encoder()).String())
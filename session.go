@@ -1,11 +1,25 @@
 package phpsessgo
 
-import "github.com/eligundry/phpsessgo/phpencode"
+import (
+	"net/http"
+	"time"
+
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phptype"
+)
 
 // Session handle creation/modification of session parametr
 type Session struct {
 	SessionID string
 	Value     phpencode.PhpSession
+	// CreatedAt and LastAccessedAt are tracked when session timeouts are configured
+	CreatedAt      time.Time
+	LastAccessedAt time.Time
+
+	flashes FlashStorage
+	// request and response of Start, used by HTTPSessionHandler
+	request  *http.Request
+	response http.ResponseWriter
 }
 
 // NewSession create new instance of Session
@@ -15,3 +29,25 @@ func NewSession() *Session {
 		Value:     make(phpencode.PhpSession),
 	}
 }
+
+// AddFlash add one-shot message of the flash type, e.g. "notice" or "error"
+func (s *Session) AddFlash(flashType string, message phptype.Value) {
+	s.flashStorage().Add(s.Value, flashType, message)
+}
+
+// Flashes return messages of the flash type and remove them from the session
+func (s *Session) Flashes(flashType string) []phptype.Value {
+	return s.flashStorage().Take(s.Value, flashType)
+}
+
+// PeekFlashes return messages of the flash type and keep them in the session
+func (s *Session) PeekFlashes(flashType string) []phptype.Value {
+	return s.flashStorage().Peek(s.Value, flashType)
+}
+
+func (s *Session) flashStorage() FlashStorage {
+	if s.flashes == nil {
+		return &SymfonyFlashStorage{}
+	}
+	return s.flashes
+}
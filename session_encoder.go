@@ -1,8 +1,73 @@
 package phpsessgo
 
-import "github.com/eligundry/phpsessgo/phpencode"
+import (
+	"fmt"
+
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phptype"
+)
 
 type SessionEncoder interface {
 	Encode(session phpencode.PhpSession) (string, error)
 	Decode(raw string) (phpencode.PhpSession, error)
 }
+
+// OrderedSessionEncoder is SessionEncoder keeping the order of the session variables and of arrays
+// like PHP does. The session is *phptype.OrderedArray of the variable names, arrays inside are
+// decoded as *phptype.OrderedArray
+type OrderedSessionEncoder interface {
+	SessionEncoder
+	EncodeOrdered(session *phptype.OrderedArray) (string, error)
+	DecodeOrdered(raw string) (*phptype.OrderedArray, error)
+}
+
+// NewSessionEncoder return encoder of session.serialize_handler name: php, php_binary,
+// php_serialize, or json for JSONSessionEncoder
+func NewSessionEncoder(serializeHandler string) (SessionEncoder, error) {
+	switch serializeHandler {
+	case "php":
+		return &PHPSessionEncoder{}, nil
+	case "php_binary":
+		return &PHPBinarySessionEncoder{}, nil
+	case "php_serialize":
+		return &PHPSerializeSessionEncoder{}, nil
+	case "json":
+		return &JSONSessionEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("phpsessgo: unsupported serialize handler %q", serializeHandler)
+	}
+}
+
+// orderedSession return the session variables in order
+func orderedSession(session phpencode.PhpSession, order []string) *phptype.OrderedArray {
+	ordered := &phptype.OrderedArray{
+		Array: make(phptype.Array, len(session)),
+		Order: make([]phptype.Value, len(order)),
+	}
+	for name, value := range session {
+		ordered.Array[name] = value
+	}
+	for i, name := range order {
+		ordered.Order[i] = name
+	}
+	return ordered
+}
+
+// sessionVariables return the session variables and their order, keys are converted to names with fmt
+func sessionVariables(session *phptype.OrderedArray) (phpencode.PhpSession, []string, error) {
+	keys, err := session.Keys()
+	if err != nil {
+		return nil, nil, fmt.Errorf("phpsessgo: invalid session variable: %v", err)
+	}
+
+	variables := make(phpencode.PhpSession, len(keys))
+	order := make([]string, len(keys))
+	for i, key := range keys {
+		order[i] = fmt.Sprint(key)
+		if _, ok := variables[order[i]]; ok {
+			return nil, nil, fmt.Errorf("phpsessgo: duplicate session variable %q", order[i])
+		}
+		variables[order[i]] = session.Array[key]
+	}
+	return variables, order, nil
+}
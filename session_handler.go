@@ -1,5 +1,10 @@
 package phpsessgo
 
+import (
+	"context"
+	"time"
+)
+
 // SessionHandler is adoption of PHP SessionHandlerInterface
 // For more reference: https://www.php.net/manual/en/class.sessionhandlerinterface.php
 type SessionHandler interface {
@@ -8,3 +13,111 @@ type SessionHandler interface {
 	Read(sessionID string) (string, error)
 	Write(sessionID, sessionData string) error
 }
+
+// SessionUpdateTimestampHandler is adoption of PHP SessionUpdateTimestampHandlerInterface
+// to refresh the expiration of unchanged session.
+// For more reference: https://www.php.net/manual/en/class.sessionupdatetimestamphandlerinterface.php
+type SessionUpdateTimestampHandler interface {
+	UpdateTimestamp(sessionID, sessionData string) error
+}
+
+// SessionDestroyHandler is handler able to remove the session, adoption of SessionHandlerInterface::destroy()
+type SessionDestroyHandler interface {
+	Destroy(sessionID string) error
+}
+
+// ContextSessionHandler is SessionHandler which propagate request cancellation and deadline to the storage
+type ContextSessionHandler interface {
+	SessionHandler
+	ReadContext(ctx context.Context, sessionID string) (string, error)
+	WriteContext(ctx context.Context, sessionID, sessionData string) error
+}
+
+// ContextSessionUpdateTimestampHandler is SessionUpdateTimestampHandler which propagate request
+// cancellation and deadline to the storage
+type ContextSessionUpdateTimestampHandler interface {
+	UpdateTimestampContext(ctx context.Context, sessionID, sessionData string) error
+}
+
+// ContextSessionDestroyHandler is SessionDestroyHandler which propagate request cancellation
+// and deadline to the storage
+type ContextSessionDestroyHandler interface {
+	DestroyContext(ctx context.Context, sessionID string) error
+}
+
+// NewContextSessionHandler adapt SessionHandler to ContextSessionHandler.
+// The context of context-free handler is only checked before calling it
+func NewContextSessionHandler(handler SessionHandler) ContextSessionHandler {
+	if h, ok := handler.(ContextSessionHandler); ok {
+		return h
+	}
+	return &contextSessionHandler{SessionHandler: handler}
+}
+
+type contextSessionHandler struct {
+	SessionHandler
+}
+
+func (h *contextSessionHandler) ReadContext(ctx context.Context, sessionID string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.Read(sessionID)
+}
+
+func (h *contextSessionHandler) WriteContext(ctx context.Context, sessionID, sessionData string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.Write(sessionID, sessionData)
+}
+
+// SessionWalker is handler able to enumerate the stored sessions, used by the bulk tools.
+// Walk stop at the first error returned by fn
+type SessionWalker interface {
+	WalkSessions(fn func(info SessionInfo) error) error
+}
+
+// SessionInfo describe stored session found by SessionWalker
+type SessionInfo struct {
+	ID string
+	// LastWrite of the session, zero when the storage can't tell
+	LastWrite time.Time
+}
+
+// SessionRewriter is handler able to replace the session data in place, used by the bulk tools.
+// RewriteSession store newData only when the session still hold oldData and keep its expiration,
+// it report whether the session was replaced
+type SessionRewriter interface {
+	RewriteSession(sessionID, oldData, newData string) (bool, error)
+}
+
+// updateTimestamp refresh the session with context when the handler support it. The context of
+// context-free handler is only checked before calling it, handler without UpdateTimestamp is no-op
+func updateTimestamp(ctx context.Context, handler SessionHandler, sessionID, sessionData string) error {
+	switch h := handler.(type) {
+	case ContextSessionUpdateTimestampHandler:
+		return h.UpdateTimestampContext(ctx, sessionID, sessionData)
+	case SessionUpdateTimestampHandler:
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return h.UpdateTimestamp(sessionID, sessionData)
+	}
+	return nil
+}
+
+// destroySession remove the session with context when the handler support it. The context of
+// context-free handler is only checked before calling it, handler without Destroy is no-op
+func destroySession(ctx context.Context, handler SessionHandler, sessionID string) error {
+	switch h := handler.(type) {
+	case ContextSessionDestroyHandler:
+		return h.DestroyContext(ctx, sessionID)
+	case SessionDestroyHandler:
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return h.Destroy(sessionID)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package phpsessgo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContextSessionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("context-free handler", func(t *testing.T) {
+		handler := mock.NewMockSessionHandler(ctrl)
+		adapter := phpsessgo.NewContextSessionHandler(handler)
+
+		handler.EXPECT().Read("some-session-id").Return("some-data", nil)
+		data, err := adapter.ReadContext(context.Background(), "some-session-id")
+		require.NoError(t, err)
+		require.Equal(t, "some-data", data)
+
+		handler.EXPECT().Write("some-session-id", "some-data").Return(nil)
+		require.NoError(t, adapter.WriteContext(context.Background(), "some-session-id", "some-data"))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		adapter := phpsessgo.NewContextSessionHandler(mock.NewMockSessionHandler(ctrl))
+
+		_, err := adapter.ReadContext(ctx, "some-session-id")
+		require.Equal(t, context.Canceled, err)
+		require.Equal(t, context.Canceled, adapter.WriteContext(ctx, "some-session-id", "some-data"))
+	})
+
+	t.Run("context-aware handler", func(t *testing.T) {
+		handler := mock.NewMockContextSessionHandler(ctrl)
+		require.Equal(t, phpsessgo.ContextSessionHandler(handler), phpsessgo.NewContextSessionHandler(handler))
+	})
+}
@@ -0,0 +1,144 @@
+package phpsessgo
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// SessionIDExtractor find the session ID in the request
+type SessionIDExtractor interface {
+	// Extract return every value of the session name found in the request, in request order
+	Extract(r *http.Request, sessionName string) []string
+}
+
+// PHPSessionIDExtractors return extractors in the same order PHP look for the session ID
+// depending on session.use_cookies and session.use_only_cookies
+func PHPSessionIDExtractors(useCookies, useOnlyCookies bool) []SessionIDExtractor {
+	var extractors []SessionIDExtractor
+	if useCookies {
+		extractors = append(extractors, &CookieIDExtractor{})
+	}
+	if !useOnlyCookies {
+		extractors = append(extractors, &QueryIDExtractor{}, &FormIDExtractor{})
+	}
+	return extractors
+}
+
+// CookieIDExtractor find session ID in the cookies, adoption of $_COOKIE lookup
+type CookieIDExtractor struct{}
+
+func (e *CookieIDExtractor) Extract(r *http.Request, sessionName string) (values []string) {
+	for _, cookie := range r.Cookies() {
+		if cookie.Name == sessionName {
+			values = append(values, cookie.Value)
+		}
+	}
+	return
+}
+
+// QueryIDExtractor find session ID in the URL query, adoption of $_GET lookup
+type QueryIDExtractor struct{}
+
+func (e *QueryIDExtractor) Extract(r *http.Request, sessionName string) []string {
+	return r.URL.Query()[sessionName]
+}
+
+// FormIDExtractor find session ID in the request body form, adoption of $_POST lookup.
+// The body is parsed with http.Request.ParseForm
+type FormIDExtractor struct{}
+
+func (e *FormIDExtractor) Extract(r *http.Request, sessionName string) []string {
+	if r.Body == nil {
+		return nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return nil
+	}
+	return r.PostForm[sessionName]
+}
+
+// HeaderIDExtractor find session ID in the request header, useful for non-browser clients
+type HeaderIDExtractor struct {
+	// Header name, default to the session name
+	Header string
+}
+
+func (e *HeaderIDExtractor) Extract(r *http.Request, sessionName string) []string {
+	header := e.Header
+	if header == "" {
+		header = sessionName
+	}
+	return r.Header.Values(header)
+}
+
+// maxSessionIDLength is PS_MAX_SID_LENGTH of PHP
+const maxSessionIDLength = 256
+
+// ValidSessionID is adoption of php_session_valid_key(), PHP refuse session ID
+// which is empty, longer than 256 characters or contain characters other than a-z, A-Z, 0-9, "," and "-"
+func ValidSessionID(sessionID string) bool {
+	if len(sessionID) == 0 || len(sessionID) > maxSessionIDLength {
+		return false
+	}
+	for _, c := range sessionID {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == ',' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// DuplicateCookiePolicy choose the session ID when the request contain it more than once,
+// e.g. cookies set for both ".example.com" and "www.example.com"
+type DuplicateCookiePolicy int
+
+const (
+	// DuplicateCookieFirst use the first value, same as PHP $_COOKIE
+	DuplicateCookieFirst DuplicateCookiePolicy = iota
+	// DuplicateCookieLast use the last value
+	DuplicateCookieLast
+	// DuplicateCookiePreferExisting use the first value which exist in the storage,
+	// fallback to the first value
+	DuplicateCookiePreferExisting
+)
+
+// SessionIDStats count how often the session ID in the request is ambiguous, malformed or unverified
+type SessionIDStats struct {
+	duplicates uint64
+	rejected   uint64
+	unverified uint64
+}
+
+// Duplicates return number of requests which contain the session ID more than once
+func (s *SessionIDStats) Duplicates() uint64 {
+	return atomic.LoadUint64(&s.duplicates)
+}
+
+// Rejected return number of session IDs refused by ValidSessionID
+func (s *SessionIDStats) Rejected() uint64 {
+	return atomic.LoadUint64(&s.rejected)
+}
+
+// Unverified return number of session ID cookies dropped because their signature doesn't
+// verify, or they are unsigned without AcceptUnsignedCookies
+func (s *SessionIDStats) Unverified() uint64 {
+	return atomic.LoadUint64(&s.unverified)
+}
+
+func (s *SessionIDStats) addDuplicate() {
+	if s != nil {
+		atomic.AddUint64(&s.duplicates, 1)
+	}
+}
+
+func (s *SessionIDStats) addRejected() {
+	if s != nil {
+		atomic.AddUint64(&s.rejected, 1)
+	}
+}
+
+func (s *SessionIDStats) addUnverified() {
+	if s != nil {
+		atomic.AddUint64(&s.unverified, 1)
+	}
+}
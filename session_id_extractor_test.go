@@ -0,0 +1,214 @@
+package phpsessgo_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/mock"
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionIDExtractors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/?PHPSESSID=from-query", strings.NewReader(url.Values{"PHPSESSID": {"from-form"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Session-ID", "from-header")
+	req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "from-cookie-1"})
+	req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "from-cookie-2"})
+
+	require.Equal(t, []string{"from-cookie-1", "from-cookie-2"}, (&phpsessgo.CookieIDExtractor{}).Extract(req, "PHPSESSID"))
+	require.Equal(t, []string{"from-query"}, (&phpsessgo.QueryIDExtractor{}).Extract(req, "PHPSESSID"))
+	require.Equal(t, []string{"from-form"}, (&phpsessgo.FormIDExtractor{}).Extract(req, "PHPSESSID"))
+	require.Equal(t, []string{"from-header"}, (&phpsessgo.HeaderIDExtractor{Header: "X-Session-ID"}).Extract(req, "PHPSESSID"))
+	require.Empty(t, (&phpsessgo.HeaderIDExtractor{}).Extract(req, "PHPSESSID"))
+}
+
+func TestPHPSessionIDExtractors(t *testing.T) {
+	require.Equal(t, []phpsessgo.SessionIDExtractor{&phpsessgo.CookieIDExtractor{}}, phpsessgo.PHPSessionIDExtractors(true, true))
+	require.Equal(t, []phpsessgo.SessionIDExtractor{
+		&phpsessgo.CookieIDExtractor{},
+		&phpsessgo.QueryIDExtractor{},
+		&phpsessgo.FormIDExtractor{},
+	}, phpsessgo.PHPSessionIDExtractors(true, false))
+	require.Equal(t, []phpsessgo.SessionIDExtractor{
+		&phpsessgo.QueryIDExtractor{},
+		&phpsessgo.FormIDExtractor{},
+	}, phpsessgo.PHPSessionIDExtractors(false, false))
+}
+
+func TestSessionManager_Start_IDExtractors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := mock.NewMockSessionHandler(ctrl)
+	encoder := mock.NewMockSessionEncoder(ctrl)
+
+	t.Run("cookie first", func(t *testing.T) {
+		manager, err := phpsessgo.NewSessionManager("PHPSESSID", nil, handler, encoder, phpsessgo.SessionManagerConfig{
+			IDExtractors: phpsessgo.PHPSessionIDExtractors(true, false),
+		})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/?PHPSESSID=from-query", nil)
+		req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "from-cookie"})
+		handler.EXPECT().Read("from-cookie").Return("", nil)
+		encoder.EXPECT().Decode("").Return(phpencode.PhpSession{}, nil)
+
+		rr := httptest.NewRecorder()
+		session, err := manager.Start(rr, req)
+		require.NoError(t, err)
+		require.Equal(t, "from-cookie", session.SessionID)
+		require.Empty(t, rr.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("query send cookie", func(t *testing.T) {
+		manager, err := phpsessgo.NewSessionManager("PHPSESSID", nil, handler, encoder, phpsessgo.SessionManagerConfig{
+			IDExtractors: phpsessgo.PHPSessionIDExtractors(true, false),
+		})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/?PHPSESSID=from-query", nil)
+		handler.EXPECT().Read("from-query").Return("", nil)
+		encoder.EXPECT().Decode("").Return(phpencode.PhpSession{}, nil)
+
+		rr := httptest.NewRecorder()
+		session, err := manager.Start(rr, req)
+		require.NoError(t, err)
+		require.Equal(t, "from-query", session.SessionID)
+		require.Equal(t, "PHPSESSID=from-query", rr.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("without cookies", func(t *testing.T) {
+		sidCreator := mock.NewMockSessionIDCreator(ctrl)
+		sidCreator.EXPECT().CreateSID().Return("random-hash")
+
+		manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, encoder, phpsessgo.SessionManagerConfig{
+			IDExtractors: phpsessgo.PHPSessionIDExtractors(false, false),
+		})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "from-cookie"})
+
+		rr := httptest.NewRecorder()
+		session, err := manager.Start(rr, req)
+		require.NoError(t, err)
+		require.Equal(t, "random-hash", session.SessionID)
+		require.Empty(t, rr.Header().Get("Set-Cookie"))
+	})
+}
+
+func TestValidSessionID(t *testing.T) {
+	require.True(t, phpsessgo.ValidSessionID("abcXYZ019,-"))
+	require.True(t, phpsessgo.ValidSessionID(strings.Repeat("a", 256)))
+	require.False(t, phpsessgo.ValidSessionID(""))
+	require.False(t, phpsessgo.ValidSessionID(strings.Repeat("a", 257)))
+	require.False(t, phpsessgo.ValidSessionID("abc_def"))
+	require.False(t, phpsessgo.ValidSessionID("../../etc/passwd"))
+	require.False(t, phpsessgo.ValidSessionID("abc\x00"))
+}
+
+func TestSessionManager_Start_DuplicateCookies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := mock.NewMockSessionHandler(ctrl)
+	encoder := mock.NewMockSessionEncoder(ctrl)
+	encoder.EXPECT().Decode(gomock.Any()).Return(phpencode.PhpSession{}, nil).AnyTimes()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "invalid_id"})
+	req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "first-id"})
+	req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "second-id"})
+	req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "third-id"})
+
+	testcases := []struct {
+		name     string
+		policy   phpsessgo.DuplicateCookiePolicy
+		expected string
+		prepare  func()
+	}{
+		{
+			name:     "first",
+			policy:   phpsessgo.DuplicateCookieFirst,
+			expected: "first-id",
+			prepare: func() {
+				handler.EXPECT().Read("first-id").Return("", nil)
+			},
+		},
+		{
+			name:     "last",
+			policy:   phpsessgo.DuplicateCookieLast,
+			expected: "third-id",
+			prepare: func() {
+				handler.EXPECT().Read("third-id").Return("", nil)
+			},
+		},
+		{
+			name:     "prefer existing",
+			policy:   phpsessgo.DuplicateCookiePreferExisting,
+			expected: "second-id",
+			prepare: func() {
+				gomock.InOrder(
+					handler.EXPECT().Read("first-id").Return("", nil),
+					handler.EXPECT().Read("second-id").Return("some-data", nil),
+				)
+			},
+		},
+		{
+			name:     "prefer existing without existing",
+			policy:   phpsessgo.DuplicateCookiePreferExisting,
+			expected: "first-id",
+			prepare: func() {
+				gomock.InOrder(
+					handler.EXPECT().Read("first-id").Return("", nil),
+					handler.EXPECT().Read("second-id").Return("", nil),
+					handler.EXPECT().Read("third-id").Return("", nil),
+				)
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &phpsessgo.SessionIDStats{}
+			manager, err := phpsessgo.NewSessionManager("PHPSESSID", nil, handler, encoder, phpsessgo.SessionManagerConfig{
+				DuplicateCookiePolicy: tt.policy,
+				IDStats:               stats,
+			})
+			require.NoError(t, err)
+			tt.prepare()
+
+			session, err := manager.Start(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, session.SessionID)
+			require.Equal(t, uint64(1), stats.Duplicates())
+			require.Equal(t, uint64(1), stats.Rejected())
+		})
+	}
+}
+
+func TestSessionManager_Start_InvalidSessionID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sidCreator := mock.NewMockSessionIDCreator(ctrl)
+	sidCreator.EXPECT().CreateSID().Return("random-hash")
+
+	manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, mock.NewMockSessionHandler(ctrl), nil, phpsessgo.SessionManagerConfig{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "<script>"})
+
+	rr := httptest.NewRecorder()
+	session, err := manager.Start(rr, req)
+	require.NoError(t, err)
+	require.Equal(t, "random-hash", session.SessionID)
+	require.Equal(t, "PHPSESSID=random-hash", rr.Header().Get("Set-Cookie"))
+}
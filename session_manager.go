@@ -1,15 +1,21 @@
 package phpsessgo
 
 import (
+	"context"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/eligundry/phpsessgo/phpencode"
 )
 
 type SessionManager interface {
 	Start(w http.ResponseWriter, r *http.Request) (session *Session, err error)
+	StartContext(ctx context.Context, w http.ResponseWriter, r *http.Request) (session *Session, err error)
 	Save(session *Session) error
+	SaveContext(ctx context.Context, session *Session) error
 	SessionName() string
 	SIDCreator() SessionIDCreator
 	Handler() SessionHandler
@@ -17,13 +23,23 @@ type SessionManager interface {
 	SetCookieString(string) string
 }
 
+// NewSessionManager create new instance of SessionManager. Invalid config and HTTPSessionHandler
+// wrapped by handler which can't bind it to the request are rejected
 func NewSessionManager(
 	sessionName string,
 	sidCreator SessionIDCreator,
 	handler SessionHandler,
 	encoder SessionEncoder,
 	config SessionManagerConfig,
-) SessionManager {
+) (SessionManager, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	// HTTPSessionHandler is bound to every request, reject wrapping which can't be bound
+	// now instead of failing every request
+	if _, _, err := bindRequest(handler, nil, nil); err != nil {
+		return nil, err
+	}
 
 	return &sessionManager{
 		sessionName: sessionName,
@@ -31,7 +47,7 @@ func NewSessionManager(
 		handler:     handler,
 		encoder:     encoder,
 		config:      config,
-	}
+	}, nil
 }
 
 // SessionManager handle session creation/modification
@@ -41,20 +57,40 @@ type sessionManager struct {
 	handler     SessionHandler
 	encoder     SessionEncoder
 	config      SessionManagerConfig
+	refreshes   refreshTracker
 }
 
-// Start is adoption of PHP start_session() to return current active session
+// Start is adoption of PHP start_session() to return current active session.
+// The request context is propagated to the session handler
 func (m *sessionManager) Start(w http.ResponseWriter, r *http.Request) (session *Session, err error) {
-	session = NewSession()
+	return m.StartContext(r.Context(), w, r)
+}
+
+// StartContext is Start with explicit context for the session handler
+func (m *sessionManager) StartContext(ctx context.Context, w http.ResponseWriter, r *http.Request) (session *Session, err error) {
+	session = m.newSession(w, r)
 
 	var raw string
 	var phpSession phpencode.PhpSession
 
-	sessionID := m.getFromCookies(r.Cookies())
+	handler, err := m.contextHandler(w, r)
+	if err != nil {
+		return
+	}
+	candidate, err := m.extractSessionID(ctx, handler, r)
+	if err != nil {
+		return
+	}
+	sessionID := candidate.sessionID
 
 	if sessionID == "" {
 		sessionID = m.sidCreator.CreateSID()
 		session.SessionID = sessionID
+		if m.tracksTimestamps() {
+			now := time.Now()
+			session.CreatedAt, session.LastAccessedAt = now, now
+		}
+		m.recordFingerprint(session, r)
 		// http.SetCookie(w, &http.Cookie{
 		// 	Name:     m.sessionName,
 		// 	Value:    sessionID,
@@ -63,33 +99,138 @@ func (m *sessionManager) Start(w http.ResponseWriter, r *http.Request) (session
 		// 	Domain:   m.config.CookieDomain,
 		// })
 
-		w.Header().Add("Set-Cookie", m.SetCookieString(sessionID))
+		if m.useCookies() {
+			w.Header().Add("Set-Cookie", m.SetCookieString(sessionID))
+		}
 		return
 	}
 
+	refreshAt := time.Now()
+	refresh := m.config.SlidingExpiration && m.refreshes.due(sessionID, m.config.SlidingRefreshInterval, refreshAt)
+
+	// like PHP, the cookie is sent when the session ID came from other source.
+	// It is sent once the session is validated, replaced session send its own cookie
+	sendCookie := (!candidate.fromCookie || candidate.resign || refresh) && m.useCookies()
+
 	session.SessionID = sessionID
-	raw, err = m.handler.Read(sessionID)
-	if err != nil {
+	if candidate.loaded {
+		raw = candidate.data
+	} else if raw, err = handler.ReadContext(ctx, sessionID); err != nil {
 		return
 	}
 
+	// only refresh of existing session use up the interval
+	if refresh && raw != "" {
+		if err = updateTimestamp(ctx, m.handler, sessionID, raw); err != nil {
+			return
+		}
+		m.refreshes.record(sessionID, m.config.SlidingRefreshInterval, refreshAt)
+	}
+
 	phpSession, err = m.encoder.Decode(raw)
 	if err != nil {
 		return
 	}
 	session.Value = phpSession
 
+	if m.tracksTimestamps() {
+		now := time.Now()
+		m.loadTimestamps(session, now)
+		if reason, expired := m.expired(session, now); expired {
+			if m.config.OnExpire != nil {
+				m.config.OnExpire(session, reason)
+			}
+			return m.replaceSession(ctx, w, r, session)
+		}
+		session.LastAccessedAt = now
+	}
+
+	for _, validator := range m.config.Validators {
+		if validationErr := validator.Validate(session, r); validationErr != nil {
+			if m.config.OnInvalid != nil {
+				m.config.OnInvalid(session, validationErr)
+			}
+			return m.replaceSession(ctx, w, r, session)
+		}
+	}
+	// session started by PHP or before the validator was configured get its fingerprint now
+	m.recordFingerprint(session, r)
+
+	if sendCookie {
+		w.Header().Add("Set-Cookie", m.SetCookieString(sessionID))
+	}
+	return
+}
+
+// replaceSession destroy the session and start new one, like session_regenerate_id(true)
+func (m *sessionManager) replaceSession(ctx context.Context, w http.ResponseWriter, r *http.Request, old *Session) (session *Session, err error) {
+	if err = destroySession(ctx, m.handler, old.SessionID); err != nil {
+		return
+	}
+
+	now := time.Now()
+	session = m.newSession(w, r)
+	session.SessionID = m.sidCreator.CreateSID()
+	session.CreatedAt, session.LastAccessedAt = now, now
+	m.recordFingerprint(session, r)
+
+	if m.useCookies() {
+		w.Header().Add("Set-Cookie", m.SetCookieString(session.SessionID))
+	}
 	return
 }
 
+// newSession create empty session using the configured flash storage
+func (m *sessionManager) newSession(w http.ResponseWriter, r *http.Request) *Session {
+	session := NewSession()
+	session.flashes = m.config.FlashStorage
+	session.request, session.response = r, w
+	return session
+}
+
+// contextHandler return the handler bound to the request when it is or wrap HTTPSessionHandler
+func (m *sessionManager) contextHandler(w http.ResponseWriter, r *http.Request) (ContextSessionHandler, error) {
+	handler, _, err := bindRequest(m.handler, w, r)
+	if err != nil {
+		return nil, err
+	}
+	return NewContextSessionHandler(handler), nil
+}
+
+// recordFingerprint let every validator record the request in the session, validators keep
+// the fingerprint already recorded
+func (m *sessionManager) recordFingerprint(session *Session, r *http.Request) {
+	for _, validator := range m.config.Validators {
+		validator.Record(session, r)
+	}
+}
+
 // Save the session
 func (m *sessionManager) Save(session *Session) error {
-	sessionData, err := m.encoder.Encode(session.Value)
+	return m.SaveContext(context.Background(), session)
+}
+
+// SaveContext save the session with context for the session handler
+func (m *sessionManager) SaveContext(ctx context.Context, session *Session) error {
+	value := session.Value
+	if m.tracksTimestamps() {
+		value = m.withTimestamps(session)
+	}
+	value = session.flashStorage().Age(value)
+	if m.config.SymfonyMetadata {
+		value = m.withSymfonyMetadata(value, time.Now())
+	}
+
+	sessionData, err := m.encoder.Encode(value)
 	if err != nil {
 		return err
 	}
 
-	return m.handler.Write(session.SessionID, sessionData)
+	handler, err := m.contextHandler(session.response, session.request)
+	if err != nil {
+		return err
+	}
+	return handler.WriteContext(ctx, session.SessionID, sessionData)
 }
 
 func (m *sessionManager) SessionName() string {
@@ -108,43 +249,142 @@ func (m *sessionManager) Encoder() SessionEncoder {
 	return m.encoder
 }
 
-func (m *sessionManager) getFromCookies(cookies []*http.Cookie) string {
-	for _, cookie := range cookies {
-		if cookie.Name == m.sessionName {
-			return cookie.Value
+type sessionIDCandidate struct {
+	sessionID  string
+	fromCookie bool
+	resign     bool
+	data       string
+	loaded     bool
+}
+
+// extractSessionID return the session ID found by the first extractor with valid value,
+// resolving duplicates with the configured policy
+func (m *sessionManager) extractSessionID(ctx context.Context, handler ContextSessionHandler, r *http.Request) (candidate sessionIDCandidate, err error) {
+	for _, extractor := range m.idExtractors() {
+		_, fromCookie := extractor.(*CookieIDExtractor)
+
+		var values []string
+		resign := false
+		for _, value := range extractor.Extract(r, m.sessionName) {
+			if fromCookie && m.signsCookies() {
+				sessionID, resignValue, ok := m.verifyCookie(value)
+				if !ok {
+					m.config.IDStats.addUnverified()
+					continue
+				}
+				value, resign = sessionID, resign || resignValue
+			}
+			if !ValidSessionID(value) {
+				m.config.IDStats.addRejected()
+				continue
+			}
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		candidate.fromCookie, candidate.resign = fromCookie, resign
+		candidate.sessionID = values[0]
+		if len(values) == 1 {
+			return
+		}
+
+		m.config.IDStats.addDuplicate()
+		switch m.config.DuplicateCookiePolicy {
+		case DuplicateCookieLast:
+			candidate.sessionID = values[len(values)-1]
+		case DuplicateCookiePreferExisting:
+			// none of them exist when the loop complete, so the first one is known to be empty
+			candidate.loaded = true
+			for _, value := range values {
+				var data string
+				if data, err = handler.ReadContext(ctx, value); err != nil {
+					return
+				}
+				if data != "" {
+					candidate.sessionID, candidate.data = value, data
+					break
+				}
+			}
+		}
+		return
+	}
+	return
+}
+
+func (m *sessionManager) idExtractors() []SessionIDExtractor {
+	if m.config.IDExtractors == nil {
+		return []SessionIDExtractor{&CookieIDExtractor{}}
+	}
+	return m.config.IDExtractors
+}
+
+// useCookies is adoption of session.use_cookies
+func (m *sessionManager) useCookies() bool {
+	for _, extractor := range m.idExtractors() {
+		if _, ok := extractor.(*CookieIDExtractor); ok {
+			return true
 		}
 	}
-	return ""
+	return false
 }
 
-// SetCookieString naive approach to get lowercase Domain and Path attribute
+// SetCookieString return Set-Cookie header value with the same attribute order as PHP php_session_send_cookie()
 func (m *sessionManager) SetCookieString(sessionID string) string {
 	var builder strings.Builder
 
 	builder.WriteString(m.SessionName())
 	builder.WriteString("=")
-	builder.WriteString(sessionID)
-	builder.WriteString("; ")
+	if m.signsCookies() {
+		builder.WriteString(phpURLEncode(SignSessionID(sessionID, m.config.CookieSigningKeys[0])))
+	} else {
+		builder.WriteString(phpURLEncode(sessionID))
+	}
+
+	if m.config.CookieLifetime > 0 {
+		expires := time.Now().Add(m.config.CookieLifetime)
+		builder.WriteString("; expires=")
+		builder.WriteString(expires.UTC().Format(http.TimeFormat))
+		builder.WriteString("; Max-Age=")
+		builder.WriteString(strconv.FormatInt(int64(m.config.CookieLifetime/time.Second), 10))
+	}
 
 	if m.config.CookiePath != "" {
-		builder.WriteString("path=")
+		builder.WriteString("; path=")
 		builder.WriteString(m.config.CookiePath)
-		builder.WriteString("; ")
 	}
 
 	if m.config.CookieDomain != "" {
-		builder.WriteString("domain=")
+		builder.WriteString("; domain=")
 		builder.WriteString(m.config.CookieDomain)
-		builder.WriteString("; ")
 	}
 
 	if m.config.CookieSecure {
-		builder.WriteString("secure; ")
+		builder.WriteString("; secure")
 	}
 
 	if m.config.CookieHttpOnly {
-		builder.WriteString("httponly")
+		builder.WriteString("; HttpOnly")
+	}
+
+	switch m.config.CookieSameSite {
+	case http.SameSiteStrictMode:
+		builder.WriteString("; SameSite=Strict")
+	case http.SameSiteLaxMode:
+		builder.WriteString("; SameSite=Lax")
+	case http.SameSiteNoneMode:
+		builder.WriteString("; SameSite=None")
+	}
+
+	if m.config.CookiePartitioned {
+		builder.WriteString("; Partitioned")
 	}
 
 	return builder.String()
 }
+
+// phpURLEncode is adoption of PHP urlencode()
+func phpURLEncode(s string) string {
+	return strings.Replace(url.QueryEscape(s), "~", "%7E", -1)
+}
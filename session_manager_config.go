@@ -1,6 +1,9 @@
 package phpsessgo
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 )
 
@@ -10,4 +13,91 @@ type SessionManagerConfig struct {
 	CookieHttpOnly bool
 	CookieDomain   string
 	CookieSecure   bool
+	// CookieLifetime is adoption of session.cookie_lifetime, zero mean until the browser is closed
+	CookieLifetime time.Duration
+	// CookieSameSite is adoption of session.cookie_samesite, zero value omit the attribute
+	CookieSameSite http.SameSite
+	// CookiePartitioned is adoption of session.cookie_partitioned (CHIPS), require CookieSecure
+	CookiePartitioned bool
+	// IDExtractors are consulted in order to find the session ID, default to cookie only.
+	// See PHPSessionIDExtractors for session.use_cookies and session.use_only_cookies policy
+	IDExtractors []SessionIDExtractor
+	// DuplicateCookiePolicy choose the session ID when it is sent more than once
+	DuplicateCookiePolicy DuplicateCookiePolicy
+	// IDStats count duplicate, malformed and unverified session IDs when set
+	IDStats *SessionIDStats
+	// SlidingExpiration resend the cookie and refresh the storage expiration on every Start,
+	// like PHP does for existing session when session.cookie_lifetime is set
+	SlidingExpiration bool
+	// SlidingRefreshInterval is minimum interval between refreshes of the same session in this process
+	SlidingRefreshInterval time.Duration
+	// IdleTimeout expire the session when it is not accessed for the duration. The last access
+	// is recorded in the session metadata by this package only, requests served by PHP
+	// don't update it, so with sessions shared with PHP only the Go traffic counts as activity
+	IdleTimeout time.Duration
+	// AbsoluteTimeout expire the session after the duration since its creation regardless of activity
+	AbsoluteTimeout time.Duration
+	// MetadataKey of the session value holding the timestamps, default to DefaultMetadataKey
+	MetadataKey string
+	// OnExpire called with the expired session before it is destroyed and replaced with new one
+	OnExpire func(session *Session, reason ExpiryReason)
+	// Validators bind the session to the client, session failing any of them is destroyed and replaced
+	Validators []SessionValidator
+	// OnInvalid called with the session and the validation error before it is destroyed and replaced
+	OnInvalid func(session *Session, err error)
+	// FlashStorage used by Session flash methods, default to SymfonyFlashStorage
+	FlashStorage FlashStorage
+	// SymfonyMetadata keep Symfony MetadataBag "_sf2_meta" updated on Save
+	SymfonyMetadata bool
+	// SymfonyMetadataUpdateThreshold is session.metadata_update_threshold of Symfony,
+	// the updated timestamp is written only when older than the threshold
+	SymfonyMetadataUpdateThreshold time.Duration
+	// CookieSigningKeys sign the session cookie with HMAC as "<session ID>.<signature>".
+	// The first key sign new cookies, all of them verify, so keys can be rotated.
+	// Cookie verified with other key is sent again signed with the first one.
+	// Cookie with invalid signature start new session without reading the storage
+	CookieSigningKeys [][]byte
+	// AcceptUnsignedCookies accept session cookie without signature, e.g. set by PHP,
+	// and resend it signed. Use it while migrating to signed cookies
+	AcceptUnsignedCookies bool
 }
+
+// Validate the configuration for combinations refused by browsers or PHP
+func (c SessionManagerConfig) Validate() error {
+	if c.CookieLifetime < 0 {
+		return fmt.Errorf("phpsessgo: cookie lifetime must not be negative")
+	}
+	if c.CookieSameSite == http.SameSiteNoneMode && !c.CookieSecure {
+		return fmt.Errorf("phpsessgo: SameSite=None cookie must be secure")
+	}
+	if c.SlidingRefreshInterval < 0 {
+		return fmt.Errorf("phpsessgo: sliding refresh interval must not be negative")
+	}
+	if c.SymfonyMetadataUpdateThreshold < 0 {
+		return fmt.Errorf("phpsessgo: symfony metadata update threshold must not be negative")
+	}
+	if c.IdleTimeout < 0 || c.AbsoluteTimeout < 0 {
+		return fmt.Errorf("phpsessgo: session timeout must not be negative")
+	}
+	if c.AcceptUnsignedCookies && len(c.CookieSigningKeys) == 0 {
+		return fmt.Errorf("phpsessgo: accepting unsigned cookies require signing keys")
+	}
+	for _, key := range c.CookieSigningKeys {
+		if len(key) == 0 {
+			return fmt.Errorf("phpsessgo: cookie signing key must not be empty")
+		}
+	}
+	if c.CookiePartitioned && !c.CookieSecure {
+		return fmt.Errorf("phpsessgo: partitioned cookie must be secure")
+	}
+	if strings.ContainsAny(c.CookiePath, invalidCookieAttributeChars) {
+		return fmt.Errorf("phpsessgo: invalid cookie path %q", c.CookiePath)
+	}
+	if strings.ContainsAny(c.CookieDomain, invalidCookieAttributeChars) {
+		return fmt.Errorf("phpsessgo: invalid cookie domain %q", c.CookieDomain)
+	}
+	return nil
+}
+
+// invalidCookieAttributeChars are refused by PHP setcookie() in path and domain
+const invalidCookieAttributeChars = ",; \t\r\n\013\014"
@@ -1,10 +1,13 @@
 package phpsessgo_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/eligundry/phpsessgo"
 	"github.com/eligundry/phpsessgo/mock"
@@ -22,12 +25,13 @@ func TestSessionManager_Start_GenerateSessionID(t *testing.T) {
 
 	handler := mock.NewMockSessionHandler(ctrl)
 
-	manager := phpsessgo.NewSessionManager("some-session-name", sidCreator, handler, nil, phpsessgo.SessionManagerConfig{
+	manager, err := phpsessgo.NewSessionManager("some-session-name", sidCreator, handler, nil, phpsessgo.SessionManagerConfig{
 		CookieHttpOnly: true,
 		CookieDomain:   "some-domain.com",
 		CookiePath:     "/",
 		CookieSecure:   true,
 	})
+	require.NoError(t, err)
 
 	req, _ := http.NewRequest(http.MethodGet, "some-url", nil)
 	rr := httptest.NewRecorder()
@@ -35,7 +39,7 @@ func TestSessionManager_Start_GenerateSessionID(t *testing.T) {
 	session, err := manager.Start(rr, req)
 	require.NoError(t, err)
 	require.Equal(t, "random-hash", session.SessionID)
-	require.Equal(t, "some-session-name=random-hash; path=/; domain=some-domain.com; secure; httponly", rr.HeaderMap.Get("Set-Cookie"))
+	require.Equal(t, "some-session-name=random-hash; path=/; domain=some-domain.com; secure; HttpOnly", rr.HeaderMap.Get("Set-Cookie"))
 }
 
 func TestSessionManager_Start_ExistingSessionID(t *testing.T) {
@@ -46,7 +50,8 @@ func TestSessionManager_Start_ExistingSessionID(t *testing.T) {
 	handler := mock.NewMockSessionHandler(ctrl)
 	encoder := mock.NewMockSessionEncoder(ctrl)
 
-	manager := phpsessgo.NewSessionManager("some-session-name", sidCreator, handler, encoder, phpsessgo.SessionManagerConfig{})
+	manager, err := phpsessgo.NewSessionManager("some-session-name", sidCreator, handler, encoder, phpsessgo.SessionManagerConfig{})
+	require.NoError(t, err)
 
 	req, _ := http.NewRequest(http.MethodGet, "some-url", nil)
 	req.AddCookie(&http.Cookie{
@@ -79,6 +84,52 @@ func TestSessionManager_Start_ExistingSessionID(t *testing.T) {
 
 }
 
+func TestSessionManager_StartContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := mock.NewMockContextSessionHandler(ctrl)
+	encoder := mock.NewMockSessionEncoder(ctrl)
+
+	manager, err := phpsessgo.NewSessionManager("some-session-name", nil, handler, encoder, phpsessgo.SessionManagerConfig{})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), struct{}{}, "some-value")
+	req, _ := http.NewRequest(http.MethodGet, "some-url", nil)
+	req = req.WithContext(ctx)
+	req.AddCookie(&http.Cookie{
+		Name:  "some-session-name",
+		Value: "some-session-id",
+	})
+
+	t.Run("request context", func(t *testing.T) {
+		handler.EXPECT().ReadContext(ctx, "some-session-id").Return("some-data", nil)
+		encoder.EXPECT().Decode("some-data").Return(phpencode.PhpSession{}, nil)
+
+		_, err := manager.Start(nil, req)
+		require.NoError(t, err)
+	})
+
+	t.Run("explicit context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		handler.EXPECT().ReadContext(ctx, "some-session-id").Return("", ctx.Err())
+
+		_, err := manager.StartContext(ctx, nil, req)
+		require.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("save", func(t *testing.T) {
+		session := phpsessgo.NewSession()
+		session.SessionID = "some-session-id"
+
+		encoder.EXPECT().Encode(session.Value).Return("encoded-data", nil)
+		handler.EXPECT().WriteContext(ctx, "some-session-id", "encoded-data").Return(nil)
+
+		require.NoError(t, manager.SaveContext(ctx, session))
+	})
+}
+
 func TestSessionManager_Save(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -87,7 +138,8 @@ func TestSessionManager_Save(t *testing.T) {
 	handler := mock.NewMockSessionHandler(ctrl)
 	encoder := mock.NewMockSessionEncoder(ctrl)
 
-	manager := phpsessgo.NewSessionManager("some-session-name", sidCreator, handler, encoder, phpsessgo.SessionManagerConfig{})
+	manager, err := phpsessgo.NewSessionManager("some-session-name", sidCreator, handler, encoder, phpsessgo.SessionManagerConfig{})
+	require.NoError(t, err)
 
 	session := phpsessgo.NewSession()
 	session.SessionID = "some-session-id"
@@ -110,11 +162,94 @@ func TestSessionManager_Save(t *testing.T) {
 
 func TestSessionManager_SetCookieString(t *testing.T) {
 
-	manager := phpsessgo.NewSessionManager("XYX", nil, nil, nil, phpsessgo.SessionManagerConfig{
+	manager, err := phpsessgo.NewSessionManager("XYX", nil, nil, nil, phpsessgo.SessionManagerConfig{
 		CookiePath:     "/",
 		CookieHttpOnly: true,
 		CookieDomain:   "some-site.com",
 	})
+	require.NoError(t, err)
+
+	require.Equal(t, "XYX=abcdefgh; path=/; domain=some-site.com; HttpOnly", manager.SetCookieString("abcdefgh"))
+
+	t.Run("all attributes", func(t *testing.T) {
+		manager, err := phpsessgo.NewSessionManager("XYX", nil, nil, nil, phpsessgo.SessionManagerConfig{
+			CookiePath:        "/",
+			CookieHttpOnly:    true,
+			CookieDomain:      "some-site.com",
+			CookieSecure:      true,
+			CookieLifetime:    time.Hour,
+			CookieSameSite:    http.SameSiteNoneMode,
+			CookiePartitioned: true,
+		})
+		require.NoError(t, err)
+
+		cookie := manager.SetCookieString("abc def")
+		r := regexp.MustCompile(`^XYX=abc\+def; expires=(.+ GMT); Max-Age=3600; path=/; domain=some-site.com; secure; HttpOnly; SameSite=None; Partitioned$`)
+		require.Regexp(t, r, cookie)
+
+		expires, err := time.Parse(http.TimeFormat, r.FindStringSubmatch(cookie)[1])
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(time.Hour), expires, 2*time.Second)
+	})
+
+	t.Run("same site", func(t *testing.T) {
+		manager, err := phpsessgo.NewSessionManager("XYX", nil, nil, nil, phpsessgo.SessionManagerConfig{
+			CookieSameSite: http.SameSiteLaxMode,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "XYX=abcdefgh; SameSite=Lax", manager.SetCookieString("abcdefgh"))
+	})
+}
+
+func TestSessionManagerConfig_Validate(t *testing.T) {
+	testcases := []struct {
+		config phpsessgo.SessionManagerConfig
+		err    string
+	}{
+		{config: phpsessgo.SessionManagerConfig{}},
+		{
+			config: phpsessgo.SessionManagerConfig{CookieSameSite: http.SameSiteNoneMode, CookieSecure: true, CookiePartitioned: true},
+		},
+		{
+			config: phpsessgo.SessionManagerConfig{CookieSameSite: http.SameSiteNoneMode},
+			err:    "phpsessgo: SameSite=None cookie must be secure",
+		},
+		{
+			config: phpsessgo.SessionManagerConfig{CookiePartitioned: true},
+			err:    "phpsessgo: partitioned cookie must be secure",
+		},
+		{
+			config: phpsessgo.SessionManagerConfig{CookieLifetime: -time.Second},
+			err:    "phpsessgo: cookie lifetime must not be negative",
+		},
+		{
+			config: phpsessgo.SessionManagerConfig{CookiePath: "/; secure"},
+			err:    `phpsessgo: invalid cookie path "/; secure"`,
+		},
+		{
+			config: phpsessgo.SessionManagerConfig{CookieDomain: "a.com\r\nX-Injected: 1"},
+			err:    `phpsessgo: invalid cookie domain "a.com\r\nX-Injected: 1"`,
+		},
+	}
+
+	for _, tt := range testcases {
+		err := tt.config.Validate()
+		if tt.err == "" {
+			require.NoError(t, err)
+		} else {
+			require.EqualError(t, err, tt.err)
+		}
+	}
+}
+
+func TestNewSessionManager_InvalidConfig(t *testing.T) {
+	config := phpsessgo.SessionManagerConfig{
+		CookieSameSite: http.SameSiteNoneMode,
+	}
+
+	_, err := phpsessgo.NewSessionManager("XYX", nil, nil, nil, config)
+	require.EqualError(t, err, "phpsessgo: SameSite=None cookie must be secure")
 
-	require.Equal(t, "XYX=abcdefgh; path=/; domain=some-site.com; httponly", manager.SetCookieString("abcdefgh"))
+	_, err = phpsessgo.NewRedisSessionManager(nil, config)
+	require.EqualError(t, err, "phpsessgo: SameSite=None cookie must be secure")
 }
@@ -0,0 +1,108 @@
+package phpsessgo
+
+import (
+	"time"
+
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+// ExpiryReason tell which timeout expired the session
+type ExpiryReason int
+
+const (
+	// ExpiryIdle when the session was not accessed for SessionManagerConfig.IdleTimeout
+	ExpiryIdle ExpiryReason = iota + 1
+	// ExpiryAbsolute when the session is older than SessionManagerConfig.AbsoluteTimeout
+	ExpiryAbsolute
+)
+
+func (r ExpiryReason) String() string {
+	switch r {
+	case ExpiryIdle:
+		return "idle"
+	case ExpiryAbsolute:
+		return "absolute"
+	}
+	return "unknown"
+}
+
+const (
+	metadataCreated    = "created"
+	metadataLastAccess = "last_access"
+)
+
+// tracksTimestamps report whether the session metadata is stored with the payload
+func (m *sessionManager) tracksTimestamps() bool {
+	return m.config.IdleTimeout > 0 || m.config.AbsoluteTimeout > 0
+}
+
+func (m *sessionManager) metadataKey() string {
+	if m.config.MetadataKey == "" {
+		return DefaultMetadataKey
+	}
+	return m.config.MetadataKey
+}
+
+// loadTimestamps move the metadata from the session value to the session fields.
+// Session without metadata, e.g. created by PHP, start to be tracked now
+func (m *sessionManager) loadTimestamps(session *Session, now time.Time) {
+	session.CreatedAt, session.LastAccessedAt = now, now
+
+	meta, ok := session.Value[m.metadataKey()].(phptype.Array)
+	delete(session.Value, m.metadataKey())
+	if !ok {
+		return
+	}
+
+	if created, ok := unixTime(meta[metadataCreated]); ok {
+		session.CreatedAt = created
+	}
+	if lastAccess, ok := unixTime(meta[metadataLastAccess]); ok {
+		session.LastAccessedAt = lastAccess
+	}
+}
+
+// expired check the session timestamps against the configured timeouts
+func (m *sessionManager) expired(session *Session, now time.Time) (ExpiryReason, bool) {
+	if m.config.AbsoluteTimeout > 0 && now.Sub(session.CreatedAt) >= m.config.AbsoluteTimeout {
+		return ExpiryAbsolute, true
+	}
+	if m.config.IdleTimeout > 0 && now.Sub(session.LastAccessedAt) >= m.config.IdleTimeout {
+		return ExpiryIdle, true
+	}
+	return 0, false
+}
+
+// withTimestamps return copy of the session value including the metadata
+func (m *sessionManager) withTimestamps(session *Session) phpencode.PhpSession {
+	now := time.Now()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	if session.LastAccessedAt.IsZero() {
+		session.LastAccessedAt = now
+	}
+
+	value := make(phpencode.PhpSession, len(session.Value)+1)
+	for k, v := range session.Value {
+		value[k] = v
+	}
+	value[m.metadataKey()] = phptype.Array{
+		metadataCreated:    int(session.CreatedAt.Unix()),
+		metadataLastAccess: int(session.LastAccessedAt.Unix()),
+	}
+	return value
+}
+
+func unixTime(v phptype.Value) (time.Time, bool) {
+	switch t := v.(type) {
+	case int:
+		return time.Unix(int64(t), 0), true
+	case int64:
+		return time.Unix(t, 0), true
+	case float64:
+		return time.Unix(int64(t), 0), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,135 @@
+package phpsessgo_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/mock"
+	"github.com/eligundry/phpsessgo/phptype"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionManager_Timeouts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	sidCreator := mock.NewMockSessionIDCreator(ctrl)
+
+	type expiry struct {
+		sessionID string
+		reason    phpsessgo.ExpiryReason
+	}
+	var expired []expiry
+
+	manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{
+		IdleTimeout:     30 * time.Minute,
+		AbsoluteTimeout: 12 * time.Hour,
+		OnExpire: func(session *phpsessgo.Session, reason phpsessgo.ExpiryReason) {
+			expired = append(expired, expiry{session.SessionID, reason})
+		},
+	})
+	require.NoError(t, err)
+
+	start := func(created, lastAccess time.Time) (*phpsessgo.Session, *httptest.ResponseRecorder) {
+		raw := fmt.Sprintf(`hello|s:5:"world";__phpsessgo_meta|a:2:{s:7:"created";i:%d;s:11:"last_access";i:%d;}`, created.Unix(), lastAccess.Unix())
+		require.NoError(t, handler.Write("some-session-id", raw))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "some-session-id"})
+		rr := httptest.NewRecorder()
+
+		session, err := manager.Start(rr, req)
+		require.NoError(t, err)
+		return session, rr
+	}
+
+	t.Run("active session", func(t *testing.T) {
+		created := time.Now().Add(-time.Hour).Truncate(time.Second)
+		session, rr := start(created, time.Now().Add(-time.Minute))
+
+		require.Equal(t, "some-session-id", session.SessionID)
+		require.Equal(t, created, session.CreatedAt)
+		require.WithinDuration(t, time.Now(), session.LastAccessedAt, time.Second)
+		require.Equal(t, "world", session.Value["hello"])
+		require.NotContains(t, session.Value, phpsessgo.DefaultMetadataKey)
+		require.Empty(t, rr.Header().Get("Set-Cookie"))
+		require.Empty(t, expired)
+
+		require.NoError(t, manager.Save(session))
+		data, _ := handler.Read("some-session-id")
+		decoded, _ := (&phpsessgo.PHPSessionEncoder{}).Decode(data)
+		require.Equal(t, phptype.Array{
+			"created":     int(created.Unix()),
+			"last_access": int(session.LastAccessedAt.Unix()),
+		}, decoded[phpsessgo.DefaultMetadataKey])
+	})
+
+	t.Run("idle timeout", func(t *testing.T) {
+		expired = nil
+		sidCreator.EXPECT().CreateSID().Return("new-session-id")
+
+		session, rr := start(time.Now().Add(-time.Hour), time.Now().Add(-31*time.Minute))
+
+		require.Equal(t, "new-session-id", session.SessionID)
+		require.Empty(t, session.Value)
+		require.Equal(t, "PHPSESSID=new-session-id", rr.Header().Get("Set-Cookie"))
+		require.Equal(t, []expiry{{"some-session-id", phpsessgo.ExpiryIdle}}, expired)
+
+		data, _ := handler.Read("some-session-id")
+		require.Empty(t, data)
+	})
+
+	t.Run("idle timeout of session from query", func(t *testing.T) {
+		expired = nil
+		sidCreator.EXPECT().CreateSID().Return("new-session-id")
+
+		manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{
+			IdleTimeout:  30 * time.Minute,
+			IDExtractors: []phpsessgo.SessionIDExtractor{&phpsessgo.CookieIDExtractor{}, &phpsessgo.QueryIDExtractor{}},
+		})
+		require.NoError(t, err)
+		past := time.Now().Add(-time.Hour).Unix()
+		require.NoError(t, handler.Write("some-session-id", fmt.Sprintf(`__phpsessgo_meta|a:2:{s:7:"created";i:%d;s:11:"last_access";i:%d;}`, past, past)))
+
+		rr := httptest.NewRecorder()
+		session, err := manager.Start(rr, httptest.NewRequest(http.MethodGet, "/?PHPSESSID=some-session-id", nil))
+		require.NoError(t, err)
+		require.Equal(t, "new-session-id", session.SessionID)
+		require.Equal(t, []string{"PHPSESSID=new-session-id"}, rr.Header().Values("Set-Cookie"))
+	})
+
+	t.Run("absolute timeout", func(t *testing.T) {
+		expired = nil
+		sidCreator.EXPECT().CreateSID().Return("new-session-id")
+
+		session, _ := start(time.Now().Add(-13*time.Hour), time.Now())
+
+		require.Equal(t, "new-session-id", session.SessionID)
+		require.Equal(t, []expiry{{"some-session-id", phpsessgo.ExpiryAbsolute}}, expired)
+	})
+
+	t.Run("session without metadata", func(t *testing.T) {
+		require.NoError(t, handler.Write("php-session-id", `hello|s:5:"world";`))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "php-session-id"})
+
+		session, err := manager.Start(httptest.NewRecorder(), req)
+		require.NoError(t, err)
+		require.Equal(t, "php-session-id", session.SessionID)
+		require.WithinDuration(t, time.Now(), session.CreatedAt, time.Second)
+	})
+}
+
+func TestExpiryReason_String(t *testing.T) {
+	require.Equal(t, "idle", phpsessgo.ExpiryIdle.String())
+	require.Equal(t, "absolute", phpsessgo.ExpiryAbsolute.String())
+}
@@ -0,0 +1,254 @@
+package phpsessgo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+// ErrFingerprintMismatch is returned when the request doesn't match the fingerprint recorded in the session
+var ErrFingerprintMismatch = errors.New("phpsessgo: session fingerprint mismatch")
+
+// SessionValidator bind the session to the client which created it
+type SessionValidator interface {
+	// Validate return error when the request doesn't match the session,
+	// session without recorded data is valid
+	Validate(session *Session, r *http.Request) error
+	// Record store the request data in the session for later validation. It is called
+	// for every started session and must keep the data recorded before, so the binding
+	// doesn't move along with the client
+	Record(session *Session, r *http.Request)
+}
+
+// Fingerprint of the client, the fields follow Magento _session_validator_data
+type Fingerprint struct {
+	RemoteAddr        string
+	HTTPVia           string
+	HTTPXForwardedFor string
+	HTTPUserAgent     string
+}
+
+// FingerprintStore read and write Fingerprint in the session value
+type FingerprintStore interface {
+	Load(session *Session) (fingerprint Fingerprint, ok bool)
+	Save(session *Session, fingerprint Fingerprint)
+}
+
+// FingerprintValidator is SessionValidator comparing the request Fingerprint,
+// adoption of Mage_Core_Model_Session_Abstract_Varien::validate()
+type FingerprintValidator struct {
+	// CheckRemoteAddr compare the client IP, masked with IPv4Mask and IPv6Mask
+	CheckRemoteAddr bool
+	// IPv4Mask and IPv6Mask are prefix length compared, default to the whole address
+	IPv4Mask int
+	IPv6Mask int
+	// TrustedProxies are skipped from the end of X-Forwarded-For to find the client IP
+	TrustedProxies []*net.IPNet
+	// CheckHTTPVia compare Via header
+	CheckHTTPVia bool
+	// CheckHTTPXForwardedFor compare X-Forwarded-For header
+	CheckHTTPXForwardedFor bool
+	// CheckHTTPUserAgent compare User-Agent header
+	CheckHTTPUserAgent bool
+	// Store of the fingerprint, default to KeyFingerprintStore with DefaultFingerprintKey
+	Store FingerprintStore
+}
+
+// Validate the request against the recorded fingerprint
+func (v *FingerprintValidator) Validate(session *Session, r *http.Request) error {
+	recorded, ok := v.store().Load(session)
+	if !ok {
+		return nil
+	}
+	current := v.Fingerprint(r)
+
+	if v.CheckRemoteAddr && !v.sameNetwork(recorded.RemoteAddr, current.RemoteAddr) {
+		return fmt.Errorf("%w: remote_addr", ErrFingerprintMismatch)
+	}
+	if v.CheckHTTPVia && recorded.HTTPVia != current.HTTPVia {
+		return fmt.Errorf("%w: http_via", ErrFingerprintMismatch)
+	}
+	if v.CheckHTTPXForwardedFor && recorded.HTTPXForwardedFor != current.HTTPXForwardedFor {
+		return fmt.Errorf("%w: http_x_forwarded_for", ErrFingerprintMismatch)
+	}
+	if v.CheckHTTPUserAgent && recorded.HTTPUserAgent != current.HTTPUserAgent {
+		return fmt.Errorf("%w: http_user_agent", ErrFingerprintMismatch)
+	}
+	return nil
+}
+
+// Record the request fingerprint when the session has none yet, like Magento
+func (v *FingerprintValidator) Record(session *Session, r *http.Request) {
+	if _, ok := v.store().Load(session); ok {
+		return
+	}
+	v.store().Save(session, v.Fingerprint(r))
+}
+
+// Fingerprint of the request
+func (v *FingerprintValidator) Fingerprint(r *http.Request) Fingerprint {
+	return Fingerprint{
+		RemoteAddr:        v.clientIP(r),
+		HTTPVia:           r.Header.Get("Via"),
+		HTTPXForwardedFor: r.Header.Get("X-Forwarded-For"),
+		HTTPUserAgent:     r.UserAgent(),
+	}
+}
+
+func (v *FingerprintValidator) store() FingerprintStore {
+	if v.Store == nil {
+		return &KeyFingerprintStore{}
+	}
+	return v.Store
+}
+
+// clientIP return the remote address, or the last untrusted address of X-Forwarded-For
+// when the request came through trusted proxies
+func (v *FingerprintValidator) clientIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if !v.trusted(ip) {
+		return ip
+	}
+
+	var forwarded []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(value, ",")...)
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip = strings.TrimSpace(forwarded[i])
+		if !v.trusted(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+func (v *FingerprintValidator) trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range v.TrustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *FingerprintValidator) sameNetwork(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a == b
+	}
+
+	if ipA.To4() != nil && ipB.To4() != nil {
+		if v.IPv4Mask <= 0 {
+			return ipA.Equal(ipB)
+		}
+		mask := net.CIDRMask(v.IPv4Mask, 32)
+		return ipA.To4().Mask(mask).Equal(ipB.To4().Mask(mask))
+	}
+
+	if v.IPv6Mask <= 0 {
+		return ipA.Equal(ipB)
+	}
+	mask := net.CIDRMask(v.IPv6Mask, 128)
+	return ipA.To16().Mask(mask).Equal(ipB.To16().Mask(mask))
+}
+
+// KeyFingerprintStore keep the fingerprint in top-level key of the session value
+type KeyFingerprintStore struct {
+	// Key of the session value, default to DefaultFingerprintKey
+	Key string
+}
+
+func (s *KeyFingerprintStore) Load(session *Session) (Fingerprint, bool) {
+	data, ok := session.Value[s.key()].(phptype.Array)
+	if !ok {
+		return Fingerprint{}, false
+	}
+	return fingerprintFromArray(data), true
+}
+
+func (s *KeyFingerprintStore) Save(session *Session, fingerprint Fingerprint) {
+	session.Value[s.key()] = fingerprint.array()
+}
+
+func (s *KeyFingerprintStore) key() string {
+	if s.Key == "" {
+		return DefaultFingerprintKey
+	}
+	return s.Key
+}
+
+// MagentoValidatorDataKey is the key Magento 1 keep the fingerprint under in every session namespace
+const MagentoValidatorDataKey = "_session_validator_data"
+
+// MagentoFingerprintStore read and write Magento 1 _session_validator_data,
+// so both Go and Magento enforce the same binding on shared sessions
+type MagentoFingerprintStore struct {
+	// Namespaces holding the validator data, default to "core".
+	// Load use the first namespace with the data, Save write all of them
+	Namespaces []string
+}
+
+func (s *MagentoFingerprintStore) Load(session *Session) (Fingerprint, bool) {
+	for _, namespace := range s.namespaces() {
+		values, ok := session.Value[namespace].(phptype.Array)
+		if !ok {
+			continue
+		}
+		if data, ok := values[MagentoValidatorDataKey].(phptype.Array); ok {
+			return fingerprintFromArray(data), true
+		}
+	}
+	return Fingerprint{}, false
+}
+
+func (s *MagentoFingerprintStore) Save(session *Session, fingerprint Fingerprint) {
+	for _, namespace := range s.namespaces() {
+		values, ok := session.Value[namespace].(phptype.Array)
+		if !ok {
+			values = phptype.Array{}
+			session.Value[namespace] = values
+		}
+		values[MagentoValidatorDataKey] = fingerprint.array()
+	}
+}
+
+func (s *MagentoFingerprintStore) namespaces() []string {
+	if len(s.Namespaces) == 0 {
+		return []string{"core"}
+	}
+	return s.Namespaces
+}
+
+func (f Fingerprint) array() phptype.Array {
+	return phptype.Array{
+		"remote_addr":          f.RemoteAddr,
+		"http_via":             f.HTTPVia,
+		"http_x_forwarded_for": f.HTTPXForwardedFor,
+		"http_user_agent":      f.HTTPUserAgent,
+	}
+}
+
+func fingerprintFromArray(data phptype.Array) Fingerprint {
+	field := func(key string) string {
+		s, _ := data[key].(string)
+		return s
+	}
+	return Fingerprint{
+		RemoteAddr:        field("remote_addr"),
+		HTTPVia:           field("http_via"),
+		HTTPXForwardedFor: field("http_x_forwarded_for"),
+		HTTPUserAgent:     field("http_user_agent"),
+	}
+}
@@ -0,0 +1,213 @@
+package phpsessgo_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/mock"
+	"github.com/eligundry/phpsessgo/phptype"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const chromeUserAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.11 (KHTML, like Gecko) Chrome/23.0.1271.64 Safari/537.11"
+
+func newFingerprintRequest(remoteAddr, userAgent string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("User-Agent", userAgent)
+	return req
+}
+
+func TestFingerprintValidator(t *testing.T) {
+	validator := &phpsessgo.FingerprintValidator{
+		CheckRemoteAddr:    true,
+		IPv4Mask:           24,
+		IPv6Mask:           64,
+		CheckHTTPUserAgent: true,
+	}
+
+	testcases := []struct {
+		testName   string
+		remoteAddr string
+		userAgent  string
+		valid      bool
+	}{
+		{"same client", "195.91.253.98:1234", chromeUserAgent, true},
+		{"same IPv4 subnet", "195.91.253.12:1234", chromeUserAgent, true},
+		{"other IPv4 subnet", "195.91.254.98:1234", chromeUserAgent, false},
+		{"other user agent", "195.91.253.98:1234", "curl/7.68.0", false},
+		{"IPv6 client", "[2001:db8::1]:1234", chromeUserAgent, false},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.testName, func(t *testing.T) {
+			session := phpsessgo.NewSession()
+			require.NoError(t, validator.Validate(session, newFingerprintRequest(tt.remoteAddr, tt.userAgent)))
+
+			validator.Record(session, newFingerprintRequest("195.91.253.98:4321", chromeUserAgent))
+			err := validator.Validate(session, newFingerprintRequest(tt.remoteAddr, tt.userAgent))
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.True(t, errors.Is(err, phpsessgo.ErrFingerprintMismatch))
+			}
+		})
+	}
+
+	t.Run("IPv6 subnet", func(t *testing.T) {
+		session := phpsessgo.NewSession()
+		validator.Record(session, newFingerprintRequest("[2001:db8::1]:1234", chromeUserAgent))
+
+		require.NoError(t, validator.Validate(session, newFingerprintRequest("[2001:db8::ffff]:1234", chromeUserAgent)))
+		require.Error(t, validator.Validate(session, newFingerprintRequest("[2001:db8:0:1::1]:1234", chromeUserAgent)))
+	})
+}
+
+func TestFingerprintValidator_TrustedProxies(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	validator := &phpsessgo.FingerprintValidator{
+		TrustedProxies: []*net.IPNet{proxies},
+	}
+
+	req := newFingerprintRequest("10.0.0.1:1234", chromeUserAgent)
+	req.Header.Add("X-Forwarded-For", "1.1.1.1, 195.91.253.98")
+	req.Header.Add("X-Forwarded-For", "10.0.0.2")
+	require.Equal(t, "195.91.253.98", validator.Fingerprint(req).RemoteAddr)
+
+	req = newFingerprintRequest("195.91.253.98:1234", chromeUserAgent)
+	req.Header.Set("X-Forwarded-For", "1.1.1.1")
+	require.Equal(t, "195.91.253.98", validator.Fingerprint(req).RemoteAddr)
+}
+
+func TestMagentoFingerprintStore(t *testing.T) {
+	raw, err := ioutil.ReadFile("./phpencode/data/test.session")
+	require.NoError(t, err)
+
+	session := phpsessgo.NewSession()
+	session.Value, err = (&phpsessgo.PHPSessionEncoder{}).Decode(string(raw))
+	require.NoError(t, err)
+
+	store := &phpsessgo.MagentoFingerprintStore{Namespaces: []string{"customer", "checkout"}}
+	fingerprint, ok := store.Load(session)
+	require.True(t, ok)
+	require.Equal(t, phpsessgo.Fingerprint{
+		RemoteAddr:    "195.91.253.98",
+		HTTPUserAgent: chromeUserAgent,
+	}, fingerprint)
+
+	validator := &phpsessgo.FingerprintValidator{
+		CheckRemoteAddr:    true,
+		CheckHTTPUserAgent: true,
+		Store:              store,
+	}
+	require.NoError(t, validator.Validate(session, newFingerprintRequest("195.91.253.98:1234", chromeUserAgent)))
+	require.Error(t, validator.Validate(session, newFingerprintRequest("195.91.253.99:1234", chromeUserAgent)))
+
+	// recorded fingerprint is kept
+	validator.Record(session, newFingerprintRequest("195.91.253.99:1234", "curl/7.68.0"))
+	fingerprint, _ = store.Load(session)
+	require.Equal(t, chromeUserAgent, fingerprint.HTTPUserAgent)
+
+	store.Save(session, validator.Fingerprint(newFingerprintRequest("195.91.253.99:1234", "curl/7.68.0")))
+	require.Equal(t, "curl/7.68.0", session.Value["checkout"].(phptype.Array)["_session_validator_data"].(phptype.Array)["http_user_agent"])
+	require.Equal(t, "195.91.253.98", session.Value["core"].(phptype.Array)["_session_validator_data"].(phptype.Array)["remote_addr"])
+}
+
+func TestSessionManager_Validators(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	sidCreator := mock.NewMockSessionIDCreator(ctrl)
+
+	var invalid []error
+	manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{
+		Validators: []phpsessgo.SessionValidator{
+			&phpsessgo.FingerprintValidator{CheckHTTPUserAgent: true},
+		},
+		OnInvalid: func(session *phpsessgo.Session, err error) {
+			invalid = append(invalid, err)
+		},
+	})
+	require.NoError(t, err)
+
+	start := func(sessionID, userAgent string) (*phpsessgo.Session, *httptest.ResponseRecorder) {
+		req := newFingerprintRequest("195.91.253.98:1234", userAgent)
+		if sessionID != "" {
+			req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: sessionID})
+		}
+		rr := httptest.NewRecorder()
+		session, err := manager.Start(rr, req)
+		require.NoError(t, err)
+		return session, rr
+	}
+
+	sidCreator.EXPECT().CreateSID().Return("some-session-id")
+	session, _ := start("", chromeUserAgent)
+	session.Value["hello"] = "world"
+	require.NoError(t, manager.Save(session))
+
+	session, _ = start("some-session-id", chromeUserAgent)
+	require.Equal(t, "some-session-id", session.SessionID)
+	require.Equal(t, "world", session.Value["hello"])
+	require.Empty(t, invalid)
+
+	sidCreator.EXPECT().CreateSID().Return("new-session-id")
+	session, rr := start("some-session-id", "curl/7.68.0")
+	require.Equal(t, "new-session-id", session.SessionID)
+	require.NotContains(t, session.Value, "hello")
+	require.Equal(t, "PHPSESSID=new-session-id", rr.Header().Get("Set-Cookie"))
+	require.Len(t, invalid, 1)
+	require.True(t, errors.Is(invalid[0], phpsessgo.ErrFingerprintMismatch))
+
+	data, _ := handler.Read("some-session-id")
+	require.Empty(t, data)
+}
+
+func TestSessionManager_ValidatorsSubnetDrift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	sidCreator := mock.NewMockSessionIDCreator(ctrl)
+	validator := &phpsessgo.FingerprintValidator{CheckRemoteAddr: true, IPv4Mask: 24, Store: &phpsessgo.KeyFingerprintStore{}}
+	manager, err := phpsessgo.NewSessionManager("PHPSESSID", sidCreator, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{
+		Validators: []phpsessgo.SessionValidator{validator},
+	})
+	require.NoError(t, err)
+
+	start := func(sessionID, remoteAddr string) *phpsessgo.Session {
+		req := newFingerprintRequest(remoteAddr, chromeUserAgent)
+		if sessionID != "" {
+			req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: sessionID})
+		}
+		session, err := manager.Start(httptest.NewRecorder(), req)
+		require.NoError(t, err)
+		require.NoError(t, manager.Save(session))
+		return session
+	}
+
+	sidCreator.EXPECT().CreateSID().Return("some-session-id")
+	start("", "10.0.1.5:1234")
+
+	// every step stay in the /24 of the previous one, but only the first is in the recorded network
+	session := start("some-session-id", "10.0.1.200:1234")
+	require.Equal(t, "some-session-id", session.SessionID)
+	recorded, ok := validator.Store.Load(session)
+	require.True(t, ok)
+	require.Equal(t, "10.0.1.5", recorded.RemoteAddr)
+
+	sidCreator.EXPECT().CreateSID().Return("new-session-id")
+	session = start("some-session-id", "10.0.2.1:1234")
+	require.Equal(t, "new-session-id", session.SessionID)
+}
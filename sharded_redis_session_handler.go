@@ -0,0 +1,273 @@
+package phpsessgo
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ShardedRedisSessionHandler distribute sessions across several independent redis nodes
+// the same way phpredis does when session.save_path contain more than one server,
+// so a session ID resolve to the same node from Go and PHP
+type ShardedRedisSessionHandler struct {
+	SessionHandler
+	// Nodes in the same order as they appear in session.save_path
+	Nodes []RedisNode
+}
+
+// RedisNode is single server of the sharded pool
+type RedisNode struct {
+	Handler *RedisSessionHandler
+	Weight  int
+}
+
+// NewShardedRedisSessionHandler create new instance of ShardedRedisSessionHandler from
+// phpredis session.save_path, e.g. "tcp://host1:6379?weight=1, tcp://host2:6379?weight=2"
+func NewShardedRedisSessionHandler(savePath string, expiration time.Duration) (*ShardedRedisSessionHandler, error) {
+	nodes, err := ParseRedisSavePath(savePath, expiration)
+	if err != nil {
+		return nil, err
+	}
+	return &ShardedRedisSessionHandler{Nodes: nodes}, nil
+}
+
+// Close the resource of every node
+func (h *ShardedRedisSessionHandler) Close() {
+	for _, node := range h.Nodes {
+		node.Handler.Close()
+	}
+}
+
+func (h *ShardedRedisSessionHandler) Read(sessionID string) (string, error) {
+	node := h.Node(sessionID)
+	if node == nil {
+		return "", fmt.Errorf("phpsessgo: no redis node available")
+	}
+	return node.Read(sessionID)
+}
+
+func (h *ShardedRedisSessionHandler) Write(sessionID string, sessionData string) error {
+	node := h.Node(sessionID)
+	if node == nil {
+		return fmt.Errorf("phpsessgo: no redis node available")
+	}
+	return node.Write(sessionID, sessionData)
+}
+
+// Destroy the session on selected node
+func (h *ShardedRedisSessionHandler) Destroy(sessionID string) error {
+	node := h.Node(sessionID)
+	if node == nil {
+		return fmt.Errorf("phpsessgo: no redis node available")
+	}
+	return node.Destroy(sessionID)
+}
+
+// UpdateTimestamp refresh expiration of the session on selected node
+func (h *ShardedRedisSessionHandler) UpdateTimestamp(sessionID string, sessionData string) error {
+	node := h.Node(sessionID)
+	if node == nil {
+		return fmt.Errorf("phpsessgo: no redis node available")
+	}
+	return node.UpdateTimestamp(sessionID, sessionData)
+}
+
+// UpdateTimestampContext refresh expiration of the session on selected node with context
+func (h *ShardedRedisSessionHandler) UpdateTimestampContext(ctx context.Context, sessionID string, sessionData string) error {
+	node := h.Node(sessionID)
+	if node == nil {
+		return fmt.Errorf("phpsessgo: no redis node available")
+	}
+	return node.UpdateTimestampContext(ctx, sessionID, sessionData)
+}
+
+// DestroyContext destroy the session on selected node with context
+func (h *ShardedRedisSessionHandler) DestroyContext(ctx context.Context, sessionID string) error {
+	node := h.Node(sessionID)
+	if node == nil {
+		return fmt.Errorf("phpsessgo: no redis node available")
+	}
+	return node.DestroyContext(ctx, sessionID)
+}
+
+// ReadContext read the session data from selected node with context
+func (h *ShardedRedisSessionHandler) ReadContext(ctx context.Context, sessionID string) (string, error) {
+	node := h.Node(sessionID)
+	if node == nil {
+		return "", fmt.Errorf("phpsessgo: no redis node available")
+	}
+	return node.ReadContext(ctx, sessionID)
+}
+
+// WriteContext write the session data to selected node with context
+func (h *ShardedRedisSessionHandler) WriteContext(ctx context.Context, sessionID string, sessionData string) error {
+	node := h.Node(sessionID)
+	if node == nil {
+		return fmt.Errorf("phpsessgo: no redis node available")
+	}
+	return node.WriteContext(ctx, sessionID, sessionData)
+}
+
+// RewriteSession replace the session data on selected node if it was not changed
+func (h *ShardedRedisSessionHandler) RewriteSession(sessionID, oldData, newData string) (bool, error) {
+	node := h.Node(sessionID)
+	if node == nil {
+		return false, fmt.Errorf("phpsessgo: no redis node available")
+	}
+	return node.RewriteSession(sessionID, oldData, newData)
+}
+
+// WalkSessions call fn for every session of every node
+func (h *ShardedRedisSessionHandler) WalkSessions(fn func(info SessionInfo) error) error {
+	for _, node := range h.Nodes {
+		if err := node.Handler.WalkSessions(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Node return the handler responsible for the session ID.
+// It is port of redis_pool_get_sock() from phpredis redis_session.c
+func (h *ShardedRedisSessionHandler) Node(sessionID string) *RedisSessionHandler {
+	totalWeight := 0
+	for _, node := range h.Nodes {
+		totalWeight += nodeWeight(node)
+	}
+	if totalWeight == 0 {
+		return nil
+	}
+
+	// phpredis memcpy the first 4 bytes of the session ID into an unsigned int,
+	// which is little endian on every platform PHP is commonly deployed
+	var buf [4]byte
+	copy(buf[:], sessionID)
+	pos := int(binary.LittleEndian.Uint32(buf[:]) % uint32(totalWeight))
+
+	// redis_pool_add() prepend to the pool, so phpredis walk the nodes from the last one
+	i := 0
+	for n := len(h.Nodes) - 1; n >= 0; n-- {
+		weight := nodeWeight(h.Nodes[n])
+		if pos >= i && pos < i+weight {
+			return h.Nodes[n].Handler
+		}
+		i += weight
+	}
+
+	return nil
+}
+
+func nodeWeight(node RedisNode) int {
+	if node.Weight <= 0 {
+		return 1
+	}
+	return node.Weight
+}
+
+// ParseRedisSavePath create redis nodes from phpredis session.save_path.
+// Supported parameters are weight, timeout, read_timeout, prefix, auth and database
+func ParseRedisSavePath(savePath string, expiration time.Duration) (nodes []RedisNode, err error) {
+	isSeparator := func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}
+
+	for _, rawURL := range strings.FieldsFunc(savePath, isSeparator) {
+		var node RedisNode
+		if node, err = parseRedisNode(rawURL, expiration); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 0 {
+		err = fmt.Errorf("phpsessgo: failed to parse session.save_path %q", savePath)
+	}
+	return
+}
+
+func parseRedisNode(rawURL string, expiration time.Duration) (node RedisNode, err error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "tcp://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return node, fmt.Errorf("phpsessgo: failed to parse session.save_path: %v", err)
+	}
+
+	query := u.Query()
+	options := &redis.Options{}
+	node.Weight = 1
+	prefix := DefaultRedisKeyPrefix
+
+	if v := query.Get("weight"); v != "" {
+		if node.Weight, err = strconv.Atoi(v); err != nil || node.Weight <= 0 {
+			return node, fmt.Errorf("phpsessgo: invalid weight %q in session.save_path", v)
+		}
+	}
+	if v := query.Get("timeout"); v != "" {
+		if options.DialTimeout, err = parseSeconds(v); err != nil || options.DialTimeout <= 0 {
+			return node, fmt.Errorf("phpsessgo: invalid timeout %q in session.save_path", v)
+		}
+	}
+	if v := query.Get("read_timeout"); v != "" {
+		if options.ReadTimeout, err = parseSeconds(v); err != nil {
+			return node, fmt.Errorf("phpsessgo: invalid read_timeout %q in session.save_path", v)
+		}
+	}
+	if v := query.Get("database"); v != "" {
+		if options.DB, err = strconv.Atoi(v); err != nil {
+			return node, fmt.Errorf("phpsessgo: invalid database %q in session.save_path", v)
+		}
+		if options.DB < 0 {
+			options.DB = 0
+		}
+	}
+	if _, ok := query["prefix"]; ok {
+		prefix = query.Get("prefix")
+	}
+	options.Password = query.Get("auth")
+
+	switch u.Scheme {
+	case "unix", "file":
+		options.Network = "unix"
+		options.Addr = u.Path
+	case "tcp":
+		if u.Hostname() != "" {
+			port := u.Port()
+			if port == "" {
+				port = "6379"
+			}
+			options.Addr = net.JoinHostPort(u.Hostname(), port)
+		}
+	default:
+		return node, fmt.Errorf("phpsessgo: unsupported scheme %q in session.save_path", u.Scheme)
+	}
+
+	if options.Addr == "" {
+		return node, fmt.Errorf("phpsessgo: failed to parse session.save_path %q", rawURL)
+	}
+
+	node.Handler = &RedisSessionHandler{
+		Client:         redis.NewClient(options),
+		RedisKeyPrefix: prefix,
+		Expiration:     expiration,
+	}
+	return node, nil
+}
+
+func parseSeconds(s string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
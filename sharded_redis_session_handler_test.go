@@ -0,0 +1,82 @@
+package phpsessgo_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/eligundry/phpsessgo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedRedisSessionHandler(t *testing.T) {
+	s1, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s1.Close()
+
+	s2, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s2.Close()
+
+	handler, err := phpsessgo.NewShardedRedisSessionHandler(
+		fmt.Sprintf("tcp://%s?weight=1, tcp://%s?weight=2&prefix=CUSTOM:", s1.Addr(), s2.Addr()),
+		time.Hour,
+	)
+	require.NoError(t, err)
+	defer handler.Close()
+
+	t.Run("node selection", func(t *testing.T) {
+		// "aaaa" => 0x61616161 % 3 = 1, "bbbb" => 0x62626262 % 3 = 2
+		require.Equal(t, handler.Nodes[1].Handler, handler.Node("aaaa-session"))
+		require.Equal(t, handler.Nodes[0].Handler, handler.Node("bbbb-session"))
+		require.Equal(t, handler.Nodes[1].Handler, handler.Node("a"))
+	})
+
+	t.Run("write to selected node", func(t *testing.T) {
+		require.NoError(t, handler.Write("aaaa-session", "data-a"))
+		require.NoError(t, handler.Write("bbbb-session", "data-b"))
+
+		val, _ := s2.Get("CUSTOM:aaaa-session")
+		require.Equal(t, "data-a", val)
+		val, _ = s1.Get("PHPREDIS_SESSION:bbbb-session")
+		require.Equal(t, "data-b", val)
+		require.Equal(t, time.Hour, s1.TTL("PHPREDIS_SESSION:bbbb-session"))
+	})
+
+	t.Run("read from selected node", func(t *testing.T) {
+		s1.Set("PHPREDIS_SESSION:bbbb-other", "php-data")
+
+		data, err := handler.Read("bbbb-other")
+		require.NoError(t, err)
+		require.Equal(t, "php-data", data)
+	})
+}
+
+func TestParseRedisSavePath(t *testing.T) {
+	t.Run("parameters", func(t *testing.T) {
+		nodes, err := phpsessgo.ParseRedisSavePath("tcp://10.0.0.1?weight=3&database=2&timeout=2.5,unix:///tmp/redis.sock", 0)
+		require.NoError(t, err)
+		require.Len(t, nodes, 2)
+
+		require.Equal(t, 3, nodes[0].Weight)
+		require.Equal(t, "10.0.0.1:6379", nodes[0].Handler.Client.Options().Addr)
+		require.Equal(t, 2, nodes[0].Handler.Client.Options().DB)
+		require.Equal(t, 2500*time.Millisecond, nodes[0].Handler.Client.Options().DialTimeout)
+		require.Equal(t, phpsessgo.DefaultRedisKeyPrefix, nodes[0].Handler.RedisKeyPrefix)
+
+		require.Equal(t, 1, nodes[1].Weight)
+		require.Equal(t, "unix", nodes[1].Handler.Client.Options().Network)
+		require.Equal(t, "/tmp/redis.sock", nodes[1].Handler.Client.Options().Addr)
+	})
+
+	t.Run("invalid weight", func(t *testing.T) {
+		_, err := phpsessgo.ParseRedisSavePath("tcp://10.0.0.1?weight=0", 0)
+		require.EqualError(t, err, `phpsessgo: invalid weight "0" in session.save_path`)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := phpsessgo.ParseRedisSavePath(" , ", 0)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,55 @@
+package phpsessgo
+
+import (
+	"sync"
+	"time"
+)
+
+// refreshTracker remember when the sessions were last refreshed by this process
+type refreshTracker struct {
+	mu        sync.Mutex
+	refreshed map[string]time.Time
+	lastPrune time.Time
+}
+
+// due report whether the session should be refreshed, the refresh is recorded by record
+func (t *refreshTracker) due(sessionID string, interval time.Duration, now time.Time) bool {
+	if interval <= 0 {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.refreshed == nil {
+		t.refreshed = make(map[string]time.Time)
+	}
+
+	// forget stale entries at most once per interval to keep the map bounded
+	if now.Sub(t.lastPrune) >= interval {
+		for id, refreshedAt := range t.refreshed {
+			if now.Sub(refreshedAt) >= interval {
+				delete(t.refreshed, id)
+			}
+		}
+		t.lastPrune = now
+	}
+
+	refreshedAt, ok := t.refreshed[sessionID]
+	return !ok || now.Sub(refreshedAt) >= interval
+}
+
+// record the refresh of the session
+func (t *refreshTracker) record(sessionID string, interval time.Duration, now time.Time) {
+	if interval <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.refreshed == nil {
+		t.refreshed = make(map[string]time.Time)
+	}
+	t.refreshed[sessionID] = now
+}
@@ -0,0 +1,95 @@
+package phpsessgo_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/eligundry/phpsessgo"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionManager_Start_SlidingExpiration(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+
+	newManager := func(interval time.Duration) phpsessgo.SessionManager {
+		manager, err := phpsessgo.NewSessionManager(
+			phpsessgo.DefaultSessionName,
+			&phpsessgo.UUIDCreator{},
+			&phpsessgo.RedisSessionHandler{
+				Client:         redis.NewClient(&redis.Options{Addr: s.Addr()}),
+				RedisKeyPrefix: phpsessgo.DefaultRedisKeyPrefix,
+				Expiration:     time.Hour,
+			},
+			&phpsessgo.PHPSessionEncoder{},
+			phpsessgo.SessionManagerConfig{
+				CookieLifetime:         time.Hour,
+				SlidingExpiration:      true,
+				SlidingRefreshInterval: interval,
+			},
+		)
+		require.NoError(t, err)
+		return manager
+	}
+
+	start := func(manager phpsessgo.SessionManager) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: phpsessgo.DefaultSessionName, Value: "some-session-id"})
+		rr := httptest.NewRecorder()
+		_, err := manager.Start(rr, req)
+		require.NoError(t, err)
+		return rr
+	}
+
+	t.Run("refresh on every start", func(t *testing.T) {
+		manager := newManager(0)
+		s.Set("PHPREDIS_SESSION:some-session-id", `hello|s:5:"world";`)
+		s.SetTTL("PHPREDIS_SESSION:some-session-id", time.Minute)
+
+		rr := start(manager)
+		require.True(t, strings.HasPrefix(rr.Header().Get("Set-Cookie"), "PHPSESSID=some-session-id; expires="))
+		require.Equal(t, time.Hour, s.TTL("PHPREDIS_SESSION:some-session-id"))
+
+		rr = start(manager)
+		require.NotEmpty(t, rr.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("minimum interval", func(t *testing.T) {
+		manager := newManager(time.Hour)
+		s.SetTTL("PHPREDIS_SESSION:some-session-id", time.Minute)
+
+		rr := start(manager)
+		require.NotEmpty(t, rr.Header().Get("Set-Cookie"))
+		require.Equal(t, time.Hour, s.TTL("PHPREDIS_SESSION:some-session-id"))
+
+		s.SetTTL("PHPREDIS_SESSION:some-session-id", time.Minute)
+		rr = start(manager)
+		require.Empty(t, rr.Header().Get("Set-Cookie"))
+		require.Equal(t, time.Minute, s.TTL("PHPREDIS_SESSION:some-session-id"))
+	})
+
+	t.Run("not existing session", func(t *testing.T) {
+		manager := newManager(0)
+		s.Del("PHPREDIS_SESSION:some-session-id")
+
+		start(manager)
+		require.False(t, s.Exists("PHPREDIS_SESSION:some-session-id"))
+	})
+
+	t.Run("minimum interval after not existing session", func(t *testing.T) {
+		manager := newManager(time.Hour)
+		s.Del("PHPREDIS_SESSION:some-session-id")
+		start(manager)
+
+		s.Set("PHPREDIS_SESSION:some-session-id", `hello|s:5:"world";`)
+		s.SetTTL("PHPREDIS_SESSION:some-session-id", time.Minute)
+		start(manager)
+		require.Equal(t, time.Hour, s.TTL("PHPREDIS_SESSION:some-session-id"))
+	})
+}
@@ -0,0 +1,244 @@
+package phpsessgo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SQLDialect select the placeholder and upsert syntax of SQLSessionHandler
+type SQLDialect string
+
+const (
+	SQLDialectMySQL    SQLDialect = "mysql"
+	SQLDialectPostgres SQLDialect = "postgres"
+	SQLDialectSQLite   SQLDialect = "sqlite"
+)
+
+// SQLSessionHandlerConfig configure SQLSessionHandler. The default column names are the ones
+// of Symfony PdoSessionHandler, but the lifetime is stored as duration while recent Symfony
+// versions store the expiry time in it, so the table can't be shared with PdoSessionHandler
+type SQLSessionHandlerConfig struct {
+	Dialect SQLDialect
+	// Table name, default to "sessions"
+	Table string
+	// IDColumn default to "sess_id"
+	IDColumn string
+	// DataColumn default to "sess_data"
+	DataColumn string
+	// LifetimeColumn hold the expiration in seconds, default to "sess_lifetime"
+	LifetimeColumn string
+	// TimeColumn hold unix time of the last write, default to "sess_time"
+	TimeColumn string
+	// Expiration of session since last write
+	Expiration time.Duration
+}
+
+// SQLSessionHandler session management using database/sql,
+// the driver of the dialect have to be registered by the application
+type SQLSessionHandler struct {
+	SessionHandler
+	DB     *sql.DB
+	config SQLSessionHandlerConfig
+	now    func() time.Time
+}
+
+// NewSQLSessionHandler create new instance of SQLSessionHandler
+func NewSQLSessionHandler(db *sql.DB, config SQLSessionHandlerConfig) (*SQLSessionHandler, error) {
+	switch config.Dialect {
+	case SQLDialectMySQL, SQLDialectPostgres, SQLDialectSQLite:
+	default:
+		return nil, fmt.Errorf("phpsessgo: unsupported SQL dialect %q", config.Dialect)
+	}
+
+	if config.Table == "" {
+		config.Table = "sessions"
+	}
+	if config.IDColumn == "" {
+		config.IDColumn = "sess_id"
+	}
+	if config.DataColumn == "" {
+		config.DataColumn = "sess_data"
+	}
+	if config.LifetimeColumn == "" {
+		config.LifetimeColumn = "sess_lifetime"
+	}
+	if config.TimeColumn == "" {
+		config.TimeColumn = "sess_time"
+	}
+
+	return &SQLSessionHandler{DB: db, config: config, now: time.Now}, nil
+}
+
+// Close the database
+func (h *SQLSessionHandler) Close() {
+	if h.DB != nil {
+		h.DB.Close()
+	}
+}
+
+func (h *SQLSessionHandler) Read(sessionID string) (string, error) {
+	return h.ReadContext(context.Background(), sessionID)
+}
+
+func (h *SQLSessionHandler) Write(sessionID string, sessionData string) error {
+	return h.WriteContext(context.Background(), sessionID, sessionData)
+}
+
+// ReadContext read the session data, expired session is read as empty
+func (h *SQLSessionHandler) ReadContext(ctx context.Context, sessionID string) (string, error) {
+	c := h.config
+	query := fmt.Sprintf("SELECT %s, %s, %s FROM %s WHERE %s = %s",
+		c.DataColumn, c.LifetimeColumn, c.TimeColumn, c.Table, c.IDColumn, h.placeholder(1))
+
+	var (
+		data     []byte
+		lifetime int64
+		written  int64
+	)
+	err := h.DB.QueryRowContext(ctx, query, sessionID).Scan(&data, &lifetime, &written)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if lifetime > 0 && written+lifetime < h.now().Unix() {
+		return "", nil
+	}
+	return string(data), nil
+}
+
+// WriteContext insert or replace the session row
+func (h *SQLSessionHandler) WriteContext(ctx context.Context, sessionID string, sessionData string) error {
+	c := h.config
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s) VALUES (%s, %s, %s, %s) ",
+		c.Table, c.IDColumn, c.DataColumn, c.LifetimeColumn, c.TimeColumn,
+		h.placeholder(1), h.placeholder(2), h.placeholder(3), h.placeholder(4))
+
+	if c.Dialect == SQLDialectMySQL {
+		query += fmt.Sprintf("ON DUPLICATE KEY UPDATE %[1]s = VALUES(%[1]s), %[2]s = VALUES(%[2]s), %[3]s = VALUES(%[3]s)",
+			c.DataColumn, c.LifetimeColumn, c.TimeColumn)
+	} else {
+		query += fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %[2]s = EXCLUDED.%[2]s, %[3]s = EXCLUDED.%[3]s, %[4]s = EXCLUDED.%[4]s",
+			c.IDColumn, c.DataColumn, c.LifetimeColumn, c.TimeColumn)
+	}
+
+	_, err := h.DB.ExecContext(ctx, query, sessionID, []byte(sessionData), h.lifetime(), h.now().Unix())
+	return err
+}
+
+// Destroy delete the session row
+func (h *SQLSessionHandler) Destroy(sessionID string) error {
+	return h.DestroyContext(context.Background(), sessionID)
+}
+
+// DestroyContext delete the session row with context
+func (h *SQLSessionHandler) DestroyContext(ctx context.Context, sessionID string) error {
+	c := h.config
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = %s", c.Table, c.IDColumn, h.placeholder(1))
+	_, err := h.DB.ExecContext(ctx, query, sessionID)
+	return err
+}
+
+// UpdateTimestamp refresh the time of the session row
+func (h *SQLSessionHandler) UpdateTimestamp(sessionID string, sessionData string) error {
+	return h.UpdateTimestampContext(context.Background(), sessionID, sessionData)
+}
+
+// UpdateTimestampContext refresh the time of the session row with context
+func (h *SQLSessionHandler) UpdateTimestampContext(ctx context.Context, sessionID string, sessionData string) error {
+	c := h.config
+	query := fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s WHERE %s = %s",
+		c.Table, c.LifetimeColumn, h.placeholder(1), c.TimeColumn, h.placeholder(2), c.IDColumn, h.placeholder(3))
+	_, err := h.DB.ExecContext(ctx, query, h.lifetime(), h.now().Unix(), sessionID)
+	return err
+}
+
+// RewriteSession replace the data of the session row if it was not changed, the time is kept
+func (h *SQLSessionHandler) RewriteSession(sessionID, oldData, newData string) (bool, error) {
+	c := h.config
+	query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s AND %s = %s",
+		c.Table, c.DataColumn, h.placeholder(1), c.IDColumn, h.placeholder(2), c.DataColumn, h.placeholder(3))
+	result, err := h.DB.Exec(query, []byte(newData), sessionID, []byte(oldData))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// Gc delete expired session rows, it return number of removed rows
+func (h *SQLSessionHandler) Gc() (int64, error) {
+	c := h.config
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s > 0 AND %s + %s < %s",
+		c.Table, c.LifetimeColumn, c.LifetimeColumn, c.TimeColumn, h.placeholder(1))
+	result, err := h.DB.Exec(query, h.now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// sqlWalkBatch is number of rows WalkSessions read at once
+const sqlWalkBatch = 1000
+
+// WalkSessions call fn for every session row in ID order. The rows are read in batches and
+// not held open while fn run, so fn can write the sessions also with SQLite
+func (h *SQLSessionHandler) WalkSessions(fn func(info SessionInfo) error) error {
+	c := h.config
+	query := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s > %s ORDER BY %s LIMIT %d",
+		c.IDColumn, c.TimeColumn, c.Table, c.IDColumn, h.placeholder(1), c.IDColumn, sqlWalkBatch)
+
+	last := ""
+	for {
+		infos, err := h.walkBatch(query, last)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if err := fn(info); err != nil {
+				return err
+			}
+		}
+		if len(infos) < sqlWalkBatch {
+			return nil
+		}
+		last = infos[len(infos)-1].ID
+	}
+}
+
+func (h *SQLSessionHandler) walkBatch(query, after string) ([]SessionInfo, error) {
+	rows, err := h.DB.Query(query, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var infos []SessionInfo
+	for rows.Next() {
+		var (
+			id      string
+			written int64
+		)
+		if err := rows.Scan(&id, &written); err != nil {
+			return nil, err
+		}
+		infos = append(infos, SessionInfo{ID: id, LastWrite: time.Unix(written, 0)})
+	}
+	return infos, rows.Err()
+}
+
+func (h *SQLSessionHandler) lifetime() int64 {
+	return int64(h.config.Expiration / time.Second)
+}
+
+func (h *SQLSessionHandler) placeholder(n int) string {
+	if h.config.Dialect == SQLDialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
@@ -0,0 +1,172 @@
+package phpsessgo
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSQLDriver record executed statements and answer queries with the configured rows
+type fakeSQLDriver struct {
+	queries []string
+	args    [][]driver.Value
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *fakeSQLDriver) Open(name string) (driver.Conn, error) { return &fakeSQLConn{d}, nil }
+
+type fakeSQLConn struct{ driver *fakeSQLDriver }
+
+func (c *fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSQLStmt{c.driver, query}, nil
+}
+func (c *fakeSQLConn) Close() error              { return nil }
+func (c *fakeSQLConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakeSQLStmt struct {
+	driver *fakeSQLDriver
+	query  string
+}
+
+func (s *fakeSQLStmt) Close() error  { return nil }
+func (s *fakeSQLStmt) NumInput() int { return -1 }
+func (s *fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.queries = append(s.driver.queries, s.query)
+	s.driver.args = append(s.driver.args, args)
+	return driver.RowsAffected(1), nil
+}
+func (s *fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.queries = append(s.driver.queries, s.query)
+	s.driver.args = append(s.driver.args, args)
+	return &fakeSQLRows{columns: s.driver.columns, rows: s.driver.rows}, nil
+}
+
+type fakeSQLRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeSQLRows) Columns() []string { return r.columns }
+func (r *fakeSQLRows) Close() error      { return nil }
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var fakeSQL = &fakeSQLDriver{}
+
+func init() {
+	sql.Register("phpsessgo-fake", fakeSQL)
+}
+
+func TestSQLSessionHandler(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	newHandler := func(t *testing.T, dialect SQLDialect) *SQLSessionHandler {
+		*fakeSQL = fakeSQLDriver{}
+		db, err := sql.Open("phpsessgo-fake", "")
+		require.NoError(t, err)
+		handler, err := NewSQLSessionHandler(db, SQLSessionHandlerConfig{Dialect: dialect, Expiration: 24 * time.Minute})
+		require.NoError(t, err)
+		handler.now = func() time.Time { return now }
+		return handler
+	}
+
+	t.Run("unsupported dialect", func(t *testing.T) {
+		_, err := NewSQLSessionHandler(nil, SQLSessionHandlerConfig{Dialect: "oracle"})
+		require.Error(t, err)
+	})
+
+	t.Run("read", func(t *testing.T) {
+		handler := newHandler(t, SQLDialectPostgres)
+		defer handler.Close()
+
+		fakeSQL.columns = []string{"sess_data", "sess_lifetime", "sess_time"}
+		fakeSQL.rows = [][]driver.Value{{[]byte("a|i:1;"), int64(1440), now.Unix() - 60}}
+		data, err := handler.Read("abc")
+		require.NoError(t, err)
+		require.Equal(t, "a|i:1;", data)
+		require.Equal(t, "SELECT sess_data, sess_lifetime, sess_time FROM sessions WHERE sess_id = $1", fakeSQL.queries[0])
+
+		fakeSQL.rows = [][]driver.Value{{[]byte("a|i:1;"), int64(1440), now.Unix() - 1441}}
+		data, err = handler.Read("abc")
+		require.NoError(t, err)
+		require.Equal(t, "", data)
+
+		data, err = handler.Read("missing")
+		require.NoError(t, err)
+		require.Equal(t, "", data)
+	})
+
+	t.Run("write mysql", func(t *testing.T) {
+		handler := newHandler(t, SQLDialectMySQL)
+		defer handler.Close()
+
+		require.NoError(t, handler.Write("abc", "a|i:1;"))
+		require.Equal(t, "INSERT INTO sessions (sess_id, sess_data, sess_lifetime, sess_time) VALUES (?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE sess_data = VALUES(sess_data), sess_lifetime = VALUES(sess_lifetime), sess_time = VALUES(sess_time)",
+			fakeSQL.queries[0])
+		require.Equal(t, []driver.Value{"abc", []byte("a|i:1;"), int64(1440), now.Unix()}, fakeSQL.args[0])
+	})
+
+	t.Run("write sqlite", func(t *testing.T) {
+		handler := newHandler(t, SQLDialectSQLite)
+		defer handler.Close()
+
+		require.NoError(t, handler.Write("abc", "a|i:1;"))
+		require.Equal(t, "INSERT INTO sessions (sess_id, sess_data, sess_lifetime, sess_time) VALUES (?, ?, ?, ?) "+
+			"ON CONFLICT (sess_id) DO UPDATE SET sess_data = EXCLUDED.sess_data, sess_lifetime = EXCLUDED.sess_lifetime, sess_time = EXCLUDED.sess_time",
+			fakeSQL.queries[0])
+	})
+
+	t.Run("rewrite session", func(t *testing.T) {
+		handler := newHandler(t, SQLDialectPostgres)
+		defer handler.Close()
+
+		replaced, err := handler.RewriteSession("abc", "a|i:1;", "a:1:{s:1:\"a\";i:1;}")
+		require.NoError(t, err)
+		require.True(t, replaced)
+		require.Equal(t, "UPDATE sessions SET sess_data = $1 WHERE sess_id = $2 AND sess_data = $3", fakeSQL.queries[0])
+		require.Equal(t, []driver.Value{[]byte("a:1:{s:1:\"a\";i:1;}"), "abc", []byte("a|i:1;")}, fakeSQL.args[0])
+	})
+
+	t.Run("destroy, update timestamp and gc", func(t *testing.T) {
+		handler := newHandler(t, SQLDialectPostgres)
+		defer handler.Close()
+
+		require.NoError(t, handler.Destroy("abc"))
+		require.NoError(t, handler.UpdateTimestamp("abc", "a|i:1;"))
+		removed, err := handler.Gc()
+		require.NoError(t, err)
+		require.Equal(t, int64(1), removed)
+
+		require.Equal(t, []string{
+			"DELETE FROM sessions WHERE sess_id = $1",
+			"UPDATE sessions SET sess_lifetime = $1, sess_time = $2 WHERE sess_id = $3",
+			"DELETE FROM sessions WHERE sess_lifetime > 0 AND sess_lifetime + sess_time < $1",
+		}, fakeSQL.queries)
+	})
+
+	t.Run("walk sessions", func(t *testing.T) {
+		handler := newHandler(t, SQLDialectMySQL)
+		defer handler.Close()
+
+		fakeSQL.columns = []string{"sess_id", "sess_time"}
+		fakeSQL.rows = [][]driver.Value{{"a", now.Unix()}, {"b", now.Unix() - 60}}
+
+		var infos []SessionInfo
+		require.NoError(t, handler.WalkSessions(func(info SessionInfo) error {
+			infos = append(infos, info)
+			return nil
+		}))
+		require.Equal(t, []SessionInfo{{ID: "a", LastWrite: now}, {ID: "b", LastWrite: now.Add(-time.Minute)}}, infos)
+	})
+}
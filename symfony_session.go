@@ -0,0 +1,179 @@
+package phpsessgo
+
+import (
+	"strings"
+	"time"
+
+	"github.com/eligundry/phpsessgo/phpencode"
+	"github.com/eligundry/phpsessgo/phptype"
+)
+
+const (
+	// SymfonyAttributesKey is session key of Symfony AttributeBag in native session storage
+	SymfonyAttributesKey = "_sf2_attributes"
+	// SymfonyMetaKey is session key of Symfony MetadataBag in native session storage
+	SymfonyMetaKey = "_sf2_meta"
+
+	symfonyMetaCreated  = "c"
+	symfonyMetaUpdated  = "u"
+	symfonyMetaLifetime = "l"
+)
+
+// SymfonySession is view of Session with the layout of Symfony NativeSessionStorage
+type SymfonySession struct {
+	*Session
+	// NamespaceCharacter split attribute name into nested arrays like Symfony NamespacedAttributeBag,
+	// e.g. "/" store "user/name" as ["user" => ["name" => ...]]. Disabled when empty
+	NamespaceCharacter string
+}
+
+// NewSymfonySession create Symfony view of the session
+func NewSymfonySession(session *Session) *SymfonySession {
+	return &SymfonySession{Session: session}
+}
+
+// Get return the attribute, adoption of SessionInterface::get()
+func (s *SymfonySession) Get(name string) (phptype.Value, bool) {
+	attributes, name := s.resolve(name, false)
+	if attributes == nil {
+		return nil, false
+	}
+	value, ok := attributes[name]
+	return value, ok
+}
+
+// Has check whether the attribute exist
+func (s *SymfonySession) Has(name string) bool {
+	_, ok := s.Get(name)
+	return ok
+}
+
+// Set the attribute
+func (s *SymfonySession) Set(name string, value phptype.Value) {
+	attributes, name := s.resolve(name, true)
+	attributes[name] = value
+}
+
+// Remove the attribute and return its value
+func (s *SymfonySession) Remove(name string) phptype.Value {
+	attributes, name := s.resolve(name, false)
+	if attributes == nil {
+		return nil
+	}
+	value := attributes[name]
+	delete(attributes, name)
+	return value
+}
+
+// All return the attributes
+func (s *SymfonySession) All() phptype.Array {
+	attributes, _ := s.Value[SymfonyAttributesKey].(phptype.Array)
+	if attributes == nil {
+		return phptype.Array{}
+	}
+	return attributes
+}
+
+// Clear remove all attributes
+func (s *SymfonySession) Clear() {
+	s.Value[SymfonyAttributesKey] = phptype.Array{}
+}
+
+// resolve return the array holding the attribute and the attribute name within it
+func (s *SymfonySession) resolve(name string, create bool) (phptype.Array, string) {
+	attributes, ok := s.Value[SymfonyAttributesKey].(phptype.Array)
+	if !ok {
+		if !create {
+			return nil, name
+		}
+		attributes = phptype.Array{}
+		s.Value[SymfonyAttributesKey] = attributes
+	}
+
+	if s.NamespaceCharacter == "" {
+		return attributes, name
+	}
+
+	parts := strings.Split(name, s.NamespaceCharacter)
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := attributes[part].(phptype.Array)
+		if !ok {
+			if !create {
+				return nil, name
+			}
+			child = phptype.Array{}
+			attributes[part] = child
+		}
+		attributes = child
+	}
+	return attributes, parts[len(parts)-1]
+}
+
+// AddFlash add message to Symfony FlashBag regardless of the configured flash storage
+func (s *SymfonySession) AddFlash(flashType string, message phptype.Value) {
+	(&SymfonyFlashStorage{}).Add(s.Value, flashType, message)
+}
+
+// Flashes return and remove messages of Symfony FlashBag
+func (s *SymfonySession) Flashes(flashType string) []phptype.Value {
+	return (&SymfonyFlashStorage{}).Take(s.Value, flashType)
+}
+
+// PeekFlashes return messages of Symfony FlashBag without removing them
+func (s *SymfonySession) PeekFlashes(flashType string) []phptype.Value {
+	return (&SymfonyFlashStorage{}).Peek(s.Value, flashType)
+}
+
+// Created return time the session was created, adoption of MetadataBag::getCreated()
+func (s *SymfonySession) Created() time.Time {
+	return s.metaTime(symfonyMetaCreated)
+}
+
+// LastUsed return time the session was last saved, adoption of MetadataBag::getLastUsed()
+func (s *SymfonySession) LastUsed() time.Time {
+	return s.metaTime(symfonyMetaUpdated)
+}
+
+// Lifetime return the cookie lifetime recorded when the session was created, adoption of MetadataBag::getLifetime()
+func (s *SymfonySession) Lifetime() time.Duration {
+	meta, _ := s.Value[SymfonyMetaKey].(phptype.Array)
+	lifetime, _ := meta[symfonyMetaLifetime].(int)
+	return time.Duration(lifetime) * time.Second
+}
+
+func (s *SymfonySession) metaTime(key string) time.Time {
+	meta, _ := s.Value[SymfonyMetaKey].(phptype.Array)
+	t, _ := unixTime(meta[key])
+	return t
+}
+
+// withSymfonyMetadata return copy of the value with "_sf2_meta" updated like MetadataBag::initialize()
+func (m *sessionManager) withSymfonyMetadata(value phpencode.PhpSession, now time.Time) phpencode.PhpSession {
+	updated := make(phpencode.PhpSession, len(value)+1)
+	for k, v := range value {
+		updated[k] = v
+	}
+
+	meta, _ := value[SymfonyMetaKey].(phptype.Array)
+	if _, ok := meta[symfonyMetaCreated]; !ok {
+		updated[SymfonyMetaKey] = phptype.Array{
+			symfonyMetaCreated:  int(now.Unix()),
+			symfonyMetaUpdated:  int(now.Unix()),
+			symfonyMetaLifetime: int(m.config.CookieLifetime / time.Second),
+		}
+		return updated
+	}
+
+	lastUsed, _ := unixTime(meta[symfonyMetaUpdated])
+	if now.Sub(lastUsed) < m.config.SymfonyMetadataUpdateThreshold {
+		return updated
+	}
+
+	stamped := make(phptype.Array, len(meta))
+	for k, v := range meta {
+		stamped[k] = v
+	}
+	stamped[symfonyMetaUpdated] = int(now.Unix())
+	updated[SymfonyMetaKey] = stamped
+	return updated
+}
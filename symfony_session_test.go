@@ -0,0 +1,128 @@
+package phpsessgo_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/eligundry/phpsessgo/phptype"
+	"github.com/stretchr/testify/require"
+)
+
+const symfonySessionData = `_sf2_attributes|a:2:{s:4:"user";a:1:{s:4:"name";s:4:"John";}s:6:"_token";s:3:"abc";}` +
+	`_sf2_flashes|a:1:{s:6:"notice";a:1:{i:0;s:5:"Hello";}}` +
+	`_sf2_meta|a:3:{s:1:"u";i:1600000000;s:1:"c";i:1599990000;s:1:"l";i:3600;}`
+
+func TestSymfonySession(t *testing.T) {
+	value, err := (&phpsessgo.PHPSessionEncoder{}).Decode(symfonySessionData)
+	require.NoError(t, err)
+
+	session := phpsessgo.NewSession()
+	session.Value = value
+	symfony := phpsessgo.NewSymfonySession(session)
+
+	t.Run("attributes", func(t *testing.T) {
+		token, ok := symfony.Get("_token")
+		require.True(t, ok)
+		require.Equal(t, "abc", token)
+		require.False(t, symfony.Has("user/name"))
+
+		symfony.Set("locale", "en")
+		require.Equal(t, "abc", symfony.Remove("_token"))
+		require.Equal(t, phptype.Array{
+			"user":   phptype.Array{"name": "John"},
+			"locale": "en",
+		}, symfony.All())
+	})
+
+	t.Run("namespaced attributes", func(t *testing.T) {
+		symfony.NamespaceCharacter = "/"
+		defer func() { symfony.NamespaceCharacter = "" }()
+
+		name, ok := symfony.Get("user/name")
+		require.True(t, ok)
+		require.Equal(t, "John", name)
+
+		symfony.Set("cart/items/1", 2)
+		require.Equal(t, phptype.Array{"items": phptype.Array{"1": 2}}, symfony.All()["cart"])
+		require.Equal(t, "John", symfony.Remove("user/name"))
+		require.False(t, symfony.Has("user/name"))
+		require.False(t, symfony.Has("missing/name"))
+	})
+
+	t.Run("flashes", func(t *testing.T) {
+		symfony.AddFlash("notice", "World")
+		require.Equal(t, []phptype.Value{"Hello", "World"}, symfony.Flashes("notice"))
+		require.Empty(t, symfony.PeekFlashes("notice"))
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		require.Equal(t, time.Unix(1599990000, 0), symfony.Created())
+		require.Equal(t, time.Unix(1600000000, 0), symfony.LastUsed())
+		require.Equal(t, time.Hour, symfony.Lifetime())
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		symfony.Clear()
+		require.Empty(t, symfony.All())
+	})
+}
+
+func TestSessionManager_SymfonyMetadata(t *testing.T) {
+	handler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	encoder := &phpsessgo.PHPSessionEncoder{}
+	config := phpsessgo.SessionManagerConfig{
+		CookieLifetime:  30 * time.Minute,
+		SymfonyMetadata: true,
+	}
+
+	save := func(config phpsessgo.SessionManagerConfig, sessionID string) *phpsessgo.SymfonySession {
+		manager, err := phpsessgo.NewSessionManager("PHPSESSID", nil, handler, encoder, config)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: sessionID})
+		session, err := manager.Start(httptest.NewRecorder(), req)
+		require.NoError(t, err)
+		require.NoError(t, manager.Save(session))
+
+		data, _ := handler.Read(sessionID)
+		saved := phpsessgo.NewSession()
+		saved.Value, err = encoder.Decode(data)
+		require.NoError(t, err)
+		return phpsessgo.NewSymfonySession(saved)
+	}
+
+	t.Run("stamp created", func(t *testing.T) {
+		require.NoError(t, handler.Write("new-session-id", `_sf2_attributes|a:0:{}`))
+
+		saved := save(config, "new-session-id")
+		require.WithinDuration(t, time.Now(), saved.Created(), time.Second)
+		require.Equal(t, saved.Created(), saved.LastUsed())
+		require.Equal(t, 30*time.Minute, saved.Lifetime())
+	})
+
+	t.Run("update existing", func(t *testing.T) {
+		require.NoError(t, handler.Write("symfony-session-id", symfonySessionData))
+
+		saved := save(config, "symfony-session-id")
+		require.Equal(t, time.Unix(1599990000, 0), saved.Created())
+		require.WithinDuration(t, time.Now(), saved.LastUsed(), time.Second)
+		require.Equal(t, time.Hour, saved.Lifetime())
+	})
+
+	t.Run("update threshold", func(t *testing.T) {
+		lastUsed := time.Now().Add(-time.Minute).Unix()
+		require.NoError(t, handler.Write("recent-session-id",
+			fmt.Sprintf(`_sf2_meta|a:3:{s:1:"u";i:%d;s:1:"c";i:1599990000;s:1:"l";i:0;}`, lastUsed)))
+
+		config.SymfonyMetadataUpdateThreshold = time.Hour
+		saved := save(config, "recent-session-id")
+		require.Equal(t, time.Unix(lastUsed, 0), saved.LastUsed())
+	})
+}
@@ -0,0 +1,29 @@
+<?php
+// Print the encrypted fixtures of laravel_session_encoder_test.go with Laravel's own Encrypter,
+// which support AES-256-GCM since Laravel 9. The IV is random, so every run print other
+// payloads, any of them must decode to the same session and cookie
+//
+//	make laravel-fixtures
+
+require __DIR__ . '/vendor/autoload.php';
+
+use Illuminate\Cookie\CookieValuePrefix;
+use Illuminate\Encryption\Encrypter;
+
+$key = base64_decode('bQZ6WaQAqoOpvCwPBoTZsMehL3JoVwNbRL/0x/HhDrA=');
+$attributes = ['_token' => 'abcdefgh', '_previous' => ['url' => 'http://example.com']];
+$sessionName = 'laravel_session';
+$sessionID = 'Qf1vJ6pZKc2tBkXe6f9mYwqjH3sVd8Ru0nLiTgOa';
+
+// EncryptedStore::prepareForStorage() encrypt serialize($attributes), which encrypt() serialize again
+$sessions = ['laravelEncryptedSession' => 'aes-256-cbc', 'laravelEncryptedSessionGCM' => 'aes-256-gcm'];
+foreach ($sessions as $constant => $cipher) {
+    $encrypter = new Encrypter($key, $cipher);
+    printf("%s = \"%s\"\n", $constant, $encrypter->encrypt(serialize($attributes)));
+}
+
+// EncryptCookies middleware encrypt the prefixed session ID without serializing it,
+// the Set-Cookie header URL-encode the value
+$encrypter = new Encrypter($key, 'aes-256-cbc');
+$cookie = $encrypter->encrypt(CookieValuePrefix::create($sessionName, $key) . $sessionID, false);
+printf("laravelEncryptedCookie = \"%s\"\n", rawurlencode($cookie));
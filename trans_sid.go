@@ -0,0 +1,241 @@
+package phpsessgo
+
+import (
+	"bytes"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultTransSIDTags is default value of session.trans_sid_tags
+const DefaultTransSIDTags = "a=href,area=href,frame=src,form="
+
+// TransSIDConfig is adoption of session.trans_sid_tags and session.trans_sid_hosts
+type TransSIDConfig struct {
+	// Tags to rewrite in "tag=attribute" list, default to DefaultTransSIDTags.
+	// Tag with empty attribute get hidden input holding the session ID
+	Tags string
+	// Hosts allowed in absolute URLs, default to the request host
+	Hosts []string
+}
+
+// attributeRegexps match the attributes of DefaultTransSIDTags and the form action,
+// other attributes are compiled by NewTransSIDRewriter
+var attributeRegexps = map[string]*regexp.Regexp{
+	"href":   attributeRegexp("href"),
+	"src":    attributeRegexp("src"),
+	"action": attributeRegexp("action"),
+}
+
+func attributeRegexp(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\s` + regexp.QuoteMeta(name) + `\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+}
+
+// TransSIDRewriter append the session ID to URLs like PHP session.use_trans_sid
+type TransSIDRewriter struct {
+	SessionName string
+	SessionID   string
+	Hosts       []string
+	tags        map[string]string
+	tagRegexp   *regexp.Regexp
+	attributes  map[string]*regexp.Regexp
+}
+
+// NewTransSIDRewriter create new instance of TransSIDRewriter
+func NewTransSIDRewriter(sessionName, sessionID string, config TransSIDConfig) *TransSIDRewriter {
+	rawTags := config.Tags
+	if rawTags == "" {
+		rawTags = DefaultTransSIDTags
+	}
+
+	tags := make(map[string]string)
+	attributes := map[string]*regexp.Regexp{"action": attributeRegexps["action"]}
+	var names []string
+	for _, pair := range strings.Split(rawTags, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if parts[0] == "" {
+			continue
+		}
+		name := strings.ToLower(parts[0])
+		if len(parts) == 2 {
+			tags[name] = strings.ToLower(parts[1])
+		} else {
+			tags[name] = ""
+		}
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	for _, attribute := range tags {
+		if attribute == "" || attributes[attribute] != nil {
+			continue
+		}
+		if attributes[attribute] = attributeRegexps[attribute]; attributes[attribute] == nil {
+			attributes[attribute] = attributeRegexp(attribute)
+		}
+	}
+
+	return &TransSIDRewriter{
+		SessionName: sessionName,
+		SessionID:   sessionID,
+		Hosts:       config.Hosts,
+		tags:        tags,
+		tagRegexp:   regexp.MustCompile(`(?i)<(` + strings.Join(names, "|") + `)(\s[^>]*)?>`),
+		attributes:  attributes,
+	}
+}
+
+// RewriteURL append the session ID to relative URL or URL of allowed host
+func (rw *TransSIDRewriter) RewriteURL(rawURL string) string {
+	if !rw.isRewritable(rawURL) {
+		return rawURL
+	}
+
+	fragment := ""
+	if i := strings.Index(rawURL, "#"); i >= 0 {
+		rawURL, fragment = rawURL[:i], rawURL[i:]
+	}
+
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+
+	return rawURL + separator + rw.SessionName + "=" + phpURLEncode(rw.SessionID) + fragment
+}
+
+// RewriteHTML rewrite URLs of configured tags and add hidden input to the forms
+func (rw *TransSIDRewriter) RewriteHTML(source string) string {
+	return rw.tagRegexp.ReplaceAllStringFunc(source, func(tag string) string {
+		name := strings.ToLower(rw.tagRegexp.FindStringSubmatch(tag)[1])
+		attribute := rw.tags[name]
+
+		if attribute == "" {
+			// form without action or with rewritable action post back the session ID
+			if action, ok := findAttribute(tag, rw.attributes["action"]); ok && !rw.isRewritable(html.UnescapeString(action.value)) {
+				return tag
+			}
+			return tag + `<input type="hidden" name="` + html.EscapeString(rw.SessionName) +
+				`" value="` + html.EscapeString(rw.SessionID) + `" />`
+		}
+
+		attr, ok := findAttribute(tag, rw.attributes[attribute])
+		if !ok {
+			return tag
+		}
+		rewritten := rw.RewriteURL(html.UnescapeString(attr.value))
+		if rewritten == html.UnescapeString(attr.value) {
+			return tag
+		}
+		return tag[:attr.start] + attr.quote + html.EscapeString(rewritten) + attr.quote + tag[attr.end:]
+	})
+}
+
+func (rw *TransSIDRewriter) isRewritable(rawURL string) bool {
+	rawURL = strings.TrimSpace(rawURL)
+	if strings.HasPrefix(rawURL, "#") {
+		return false
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	if _, ok := u.Query()[rw.SessionName]; ok {
+		return false
+	}
+	if u.Host == "" {
+		return true
+	}
+	for _, host := range rw.Hosts {
+		if strings.EqualFold(host, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+type htmlAttribute struct {
+	value      string
+	quote      string
+	start, end int
+}
+
+func findAttribute(tag string, r *regexp.Regexp) (attr htmlAttribute, ok bool) {
+	loc := r.FindStringSubmatchIndex(tag)
+	if loc == nil {
+		return attr, false
+	}
+
+	attr.start, attr.end = loc[2], loc[3]
+	attr.value = tag[attr.start:attr.end]
+	if attr.value[0] == '"' || attr.value[0] == '\'' {
+		attr.quote = attr.value[:1]
+		attr.value = attr.value[1 : len(attr.value)-1]
+	}
+	return attr, true
+}
+
+// NewTransSIDMiddleware create net/http middleware rewriting HTML responses like PHP session.use_trans_sid.
+// It must run inside the session middleware, the response is left untouched when the session cookie is present
+func NewTransSIDMiddleware(manager SessionManager, config TransSIDConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session := SessionFromContext(r.Context())
+			if session == nil || session.SessionID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if _, err := r.Cookie(manager.SessionName()); err == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rewriter := NewTransSIDRewriter(manager.SessionName(), session.SessionID, config)
+			if len(rewriter.Hosts) == 0 {
+				rewriter.Hosts = []string{r.Host}
+			}
+
+			buffer := &bufferedResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(buffer, r)
+
+			body := buffer.body.Bytes()
+			contentType := w.Header().Get("Content-Type")
+			if contentType == "" {
+				contentType = http.DetectContentType(body)
+			}
+			if strings.HasPrefix(contentType, "text/html") {
+				body = []byte(rewriter.RewriteHTML(string(body)))
+				if w.Header().Get("Content-Length") != "" {
+					w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				}
+			}
+
+			if buffer.statusCode != 0 {
+				w.WriteHeader(buffer.statusCode)
+			}
+			w.Write(body)
+		})
+	}
+}
+
+// bufferedResponseWriter hold the response body until the handler is done
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
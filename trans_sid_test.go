@@ -0,0 +1,72 @@
+package phpsessgo_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eligundry/phpsessgo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransSIDRewriter_RewriteURL(t *testing.T) {
+	rewriter := phpsessgo.NewTransSIDRewriter("PHPSESSID", "abc", phpsessgo.TransSIDConfig{
+		Hosts: []string{"www.example.com"},
+	})
+
+	testcases := map[string]string{
+		"/page.php":                          "/page.php?PHPSESSID=abc",
+		"page.php?a=1":                       "page.php?a=1&PHPSESSID=abc",
+		"page.php?a=1#top":                   "page.php?a=1&PHPSESSID=abc#top",
+		"http://www.example.com/page.php":    "http://www.example.com/page.php?PHPSESSID=abc",
+		"https://other.com/page.php":         "https://other.com/page.php",
+		"#top":                               "#top",
+		"mailto:someone@example.com":         "mailto:someone@example.com",
+		"javascript:void(0)":                 "javascript:void(0)",
+		"/page.php?PHPSESSID=already-exists": "/page.php?PHPSESSID=already-exists",
+	}
+
+	for source, expected := range testcases {
+		require.Equal(t, expected, rewriter.RewriteURL(source), source)
+	}
+}
+
+func TestTransSIDRewriter_RewriteHTML(t *testing.T) {
+	rewriter := phpsessgo.NewTransSIDRewriter("PHPSESSID", "abc", phpsessgo.TransSIDConfig{})
+
+	source := `<a href="/a.php?x=1&amp;y=2">a</a><A HREF='b.php'>b</A><a href="https://other.com/">c</a>` +
+		`<img src="/img.png"><form method="post"></form><form action="https://other.com/"></form>`
+	expected := `<a href="/a.php?x=1&amp;y=2&amp;PHPSESSID=abc">a</a><A HREF='b.php?PHPSESSID=abc'>b</A><a href="https://other.com/">c</a>` +
+		`<img src="/img.png"><form method="post"><input type="hidden" name="PHPSESSID" value="abc" /></form><form action="https://other.com/"></form>`
+
+	require.Equal(t, expected, rewriter.RewriteHTML(source))
+}
+
+func TestTransSIDMiddleware(t *testing.T) {
+	handler := phpsessgo.NewMemorySessionHandler(phpsessgo.MemorySessionHandlerConfig{})
+	defer handler.Close()
+
+	manager, err := phpsessgo.NewSessionManager("PHPSESSID", &phpsessgo.UUIDCreator{}, handler, &phpsessgo.PHPSessionEncoder{}, phpsessgo.SessionManagerConfig{
+		IDExtractors: phpsessgo.PHPSessionIDExtractors(false, false),
+	})
+	require.NoError(t, err)
+
+	h := phpsessgo.NewMiddleware(manager, phpsessgo.MiddlewareConfig{})(
+		phpsessgo.NewTransSIDMiddleware(manager, phpsessgo.TransSIDConfig{})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.Write([]byte(`<a href="/next.php">next</a>`))
+			}),
+		),
+	)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/?PHPSESSID=some-session-id", nil))
+	require.Equal(t, `<a href="/next.php?PHPSESSID=some-session-id">next</a>`, rr.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/?PHPSESSID=some-session-id", nil)
+	req.AddCookie(&http.Cookie{Name: "PHPSESSID", Value: "some-session-id"})
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, `<a href="/next.php">next</a>`, rr.Body.String())
+}